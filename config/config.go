@@ -9,32 +9,48 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Logger   Logger
-	Postgres PostgresConfig
-	Redis    RedisConfig
-	Http     Http
-	Cookie   Cookie
-	Session  Session
+	Server              ServerConfig
+	Logger              Logger
+	Postgres            PostgresConfig
+	Redis               RedisConfig
+	Http                Http
+	GrpcWeb             GrpcWeb
+	Cookie              Cookie
+	Session             Session
+	Purge               Purge
+	Idempotency         Idempotency
+	Mailer              Mailer
+	Signup              Signup
+	Invitation          Invitation
+	MagicLink           MagicLink
+	OTP                 OTP
+	SMS                 SMS
+	TrustedDevice       TrustedDevice
+	APIKey              APIKey
+	PersonalAccessToken PersonalAccessToken
+	OAuth               OAuth
 }
 
 type ServerConfig struct {
-	AppVersion        string
-	Port              string
-	PprofPort         string
-	Mode              string
-	JwtSecretKey      string
-	CookieName        string
-	ReadTimeout       time.Duration
-	WriteTimeout      time.Duration
-	SSL               bool
-	CtxDefaultTimeout time.Duration
-	CSRF              bool
-	Debug             bool
-	MaxConnectionIdle time.Duration
-	Timeout           time.Duration
-	MaxConnectionAge  time.Duration
-	Time              time.Duration
+	AppVersion          string
+	Port                string
+	SinglePort          bool
+	PprofPort           string
+	Mode                string
+	JwtSecretKey        string
+	CursorSecretKey     string
+	CookieName          string
+	ReadTimeout         time.Duration
+	WriteTimeout        time.Duration
+	SSL                 bool
+	CtxDefaultTimeout   time.Duration
+	CSRF                bool
+	Debug               bool
+	DebugErrorsResponse bool
+	MaxConnectionIdle   time.Duration
+	Timeout             time.Duration
+	MaxConnectionAge    time.Duration
+	Time                time.Duration
 }
 
 type Http struct {
@@ -45,7 +61,14 @@ type Http struct {
 	DebugHeaders        bool
 	HttpClientDebug     bool
 	DebugErrorsResponse bool
+	ErrorFormat         string
 	IgnoreLogUrls       []string
+	TrustedProxies      []string
+}
+
+type GrpcWeb struct {
+	Enabled        bool
+	AllowedOrigins []string
 }
 
 type Logger struct {
@@ -85,12 +108,127 @@ type Cookie struct {
 	HTTPOnly bool
 }
 
+// Session ReauthMaxAge is how long, in seconds, an authentication counts as recent for sensitive operations
 type Session struct {
-	Prefix string
-	Name   string
+	Prefix       string
+	Name         string
+	Expire       int
+	ReauthMaxAge int
+}
+
+// Purge hard deletes or anonymizes soft deleted users older than the retention period
+type Purge struct {
+	Enabled       bool
+	Interval      time.Duration
+	RetentionDays int
+	BatchSize     int
+	Anonymize     bool
+}
+
+// Idempotency replays the stored response of mutating requests retried with the same Idempotency-Key header,
+// Expire and LockExpire are in seconds
+type Idempotency struct {
+	Enabled    bool
+	Expire     int
+	LockExpire int
+}
+
+// Mailer sends emails over smtp when Driver is smtp and logs them otherwise,
+// LinkBaseURL is the frontend url emailed links point to
+type Mailer struct {
+	Driver      string
+	From        string
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	LinkBaseURL string
+}
+
+// Signup public self-service signup, Mode is open, allowlist, invite or empty to disable it.
+// DeniedDomains apply in every mode, VerificationExpire and RateLimitWindow are in seconds
+type Signup struct {
+	Mode                     string
+	AllowedDomains           []string
+	DeniedDomains            []string
+	InviteCodes              []string
+	RequireEmailVerification bool
+	VerificationExpire       int
+	RateLimit                int
+	RateLimitWindow          int
+}
+
+// Invitation admin invites of users choosing their own password, Expire of the emailed link is in seconds
+type Invitation struct {
 	Expire int
 }
 
+// MagicLink passwordless login links bound to the requesting browser by the CookieName nonce cookie,
+// Expire and RateLimitWindow are in seconds
+type MagicLink struct {
+	Expire          int
+	CookieName      string
+	RateLimit       int
+	RateLimitWindow int
+}
+
+// OTP emailed one time codes for login and step-up verification, a code is dropped after MaxAttempts wrong tries.
+// Expire, RateLimitWindow and StepUpMaxAge, how long a step-up is accepted, are in seconds
+type OTP struct {
+	Expire          int
+	MaxAttempts     int
+	RateLimit       int
+	RateLimitWindow int
+	StepUpMaxAge    int
+}
+
+// SMS sends text messages to the ProviderURL json api when Driver is http, authorized by the ProviderToken bearer token.
+// Other drivers append messages to FilePath or log them. A phone number receives at most RateLimit codes
+// every RateLimitWindow seconds, Timeout and RateLimitWindow are in seconds
+type SMS struct {
+	Driver          string
+	From            string
+	FilePath        string
+	ProviderURL     string
+	ProviderToken   string
+	Timeout         int
+	RateLimit       int
+	RateLimitWindow int
+}
+
+// TrustedDevice browsers remembered after a step-up by the signed CookieName cookie skip the second factor when logging in,
+// Expire is in seconds
+type TrustedDevice struct {
+	Expire     int
+	CookieName string
+}
+
+// APIKey service account keys, Expire is the default lifetime of new keys and RotationGrace how long a rotated key
+// keeps working, both in seconds and 0 meaning forever and not at all
+type APIKey struct {
+	Expire        int
+	RotationGrace int
+}
+
+// PersonalAccessToken tokens users create for scripts, Expire is the default lifetime and MaxExpire the longest one
+// a user may ask for, both in seconds
+type PersonalAccessToken struct {
+	Expire    int
+	MaxExpire int
+}
+
+// OAuth authorization server, CodeExpire is how long, in seconds, an authorization code can be exchanged for tokens
+type OAuth struct {
+	CodeExpire int
+}
+
+// Signup modes, any other mode disables signup
+const (
+	SignupModeOpen      = "open"
+	SignupModeAllowlist = "allowlist"
+	SignupModeInvite    = "invite"
+)
+
 // LoadConfig Load config file from given path
 func LoadConfig(filename string) (*viper.Viper, error) {
 	v := viper.New()
@@ -0,0 +1,42 @@
+package accesstoken
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// AuthScheme authorization header scheme of personal access tokens, "Authorization: Bearer ujp_..."
+const AuthScheme = "Bearer"
+
+type accessTokenCtxKey struct{}
+
+type principal struct {
+	token *models.PersonalAccessToken
+	user  *models.User
+}
+
+// ParseAuthorization personal access token of an authorization header value, false for other schemes and bearer jwts
+func ParseAuthorization(header string) (string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], AuthScheme) {
+		return "", false
+	}
+	token := strings.TrimSpace(parts[1])
+	return token, strings.HasPrefix(token, models.PersonalAccessTokenPrefix)
+}
+
+// NewContext ctx carrying the personal access token the request authenticated with and its user
+func NewContext(ctx context.Context, token *models.PersonalAccessToken, user *models.User) context.Context {
+	return context.WithValue(ctx, accessTokenCtxKey{}, &principal{token: token, user: user})
+}
+
+// FromContext personal access token the request authenticated with and its user, false when it did not use one
+func FromContext(ctx context.Context) (*models.PersonalAccessToken, *models.User, bool) {
+	p, ok := ctx.Value(accessTokenCtxKey{}).(*principal)
+	if !ok || p == nil {
+		return nil, nil, false
+	}
+	return p.token, p.user, true
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pg_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/dinorain/useraja/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockAccessTokenPGRepository is a mock of AccessTokenPGRepository interface.
+type MockAccessTokenPGRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessTokenPGRepositoryMockRecorder
+}
+
+// MockAccessTokenPGRepositoryMockRecorder is the mock recorder for MockAccessTokenPGRepository.
+type MockAccessTokenPGRepositoryMockRecorder struct {
+	mock *MockAccessTokenPGRepository
+}
+
+// NewMockAccessTokenPGRepository creates a new mock instance.
+func NewMockAccessTokenPGRepository(ctrl *gomock.Controller) *MockAccessTokenPGRepository {
+	mock := &MockAccessTokenPGRepository{ctrl: ctrl}
+	mock.recorder = &MockAccessTokenPGRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessTokenPGRepository) EXPECT() *MockAccessTokenPGRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAccessTokenPGRepository) Authenticate(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, tokenHash)
+	ret0, _ := ret[0].(*models.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAccessTokenPGRepositoryMockRecorder) Authenticate(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAccessTokenPGRepository)(nil).Authenticate), ctx, tokenHash)
+}
+
+// Create mocks base method.
+func (m *MockAccessTokenPGRepository) Create(ctx context.Context, token *models.PersonalAccessToken) (*models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, token)
+	ret0, _ := ret[0].(*models.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAccessTokenPGRepositoryMockRecorder) Create(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAccessTokenPGRepository)(nil).Create), ctx, token)
+}
+
+// DeleteAllByUserId mocks base method.
+func (m *MockAccessTokenPGRepository) DeleteAllByUserId(ctx context.Context, userID uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllByUserId", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAllByUserId indicates an expected call of DeleteAllByUserId.
+func (mr *MockAccessTokenPGRepositoryMockRecorder) DeleteAllByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllByUserId", reflect.TypeOf((*MockAccessTokenPGRepository)(nil).DeleteAllByUserId), ctx, userID)
+}
+
+// DeleteById mocks base method.
+func (m *MockAccessTokenPGRepository) DeleteById(ctx context.Context, userID, tokenID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteById", ctx, userID, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteById indicates an expected call of DeleteById.
+func (mr *MockAccessTokenPGRepositoryMockRecorder) DeleteById(ctx, userID, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockAccessTokenPGRepository)(nil).DeleteById), ctx, userID, tokenID)
+}
+
+// FindAllByUserId mocks base method.
+func (m *MockAccessTokenPGRepository) FindAllByUserId(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllByUserId", ctx, userID)
+	ret0, _ := ret[0].([]models.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllByUserId indicates an expected call of FindAllByUserId.
+func (mr *MockAccessTokenPGRepositoryMockRecorder) FindAllByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllByUserId", reflect.TypeOf((*MockAccessTokenPGRepository)(nil).FindAllByUserId), ctx, userID)
+}
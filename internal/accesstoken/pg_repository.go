@@ -0,0 +1,19 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository.go -package mock
+package accesstoken
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// Personal access token Postgresql repository
+type AccessTokenPGRepository interface {
+	Create(ctx context.Context, token *models.PersonalAccessToken) (*models.PersonalAccessToken, error)
+	Authenticate(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	FindAllByUserId(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error)
+	DeleteById(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error
+	DeleteAllByUserId(ctx context.Context, userID uuid.UUID) (int, error)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/internal/accesstoken"
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// Personal access token repository
+type AccessTokenRepository struct {
+	db *sqlx.DB
+}
+
+var _ accesstoken.AccessTokenPGRepository = (*AccessTokenRepository)(nil)
+
+// Personal access token repository constructor
+func NewAccessTokenPGRepository(db *sqlx.DB) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+// Create new personal access token
+func (r *AccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) (*models.PersonalAccessToken, error) {
+	createdToken := &models.PersonalAccessToken{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createAccessTokenQuery,
+		token.UserID,
+		token.Name,
+		token.Prefix,
+		token.TokenHash,
+		token.Scopes,
+		token.ExpiresAt,
+	).StructScan(createdToken); err != nil {
+		return nil, errors.Wrap(err, "AccessTokenRepository.Create.QueryRowxContext")
+	}
+
+	return createdToken, nil
+}
+
+// Authenticate Find the unexpired token with the hash and record it was used now, sql.ErrNoRows when there is none
+func (r *AccessTokenRepository) Authenticate(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	token := &models.PersonalAccessToken{}
+	if err := r.db.QueryRowxContext(ctx, authenticateAccessTokenQuery, tokenHash).StructScan(token); err != nil {
+		return nil, errors.Wrap(err, "AccessTokenRepository.Authenticate.QueryRowxContext")
+	}
+
+	return token, nil
+}
+
+// FindAllByUserId Find the tokens of user, newest first
+func (r *AccessTokenRepository) FindAllByUserId(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	if err := r.db.SelectContext(ctx, &tokens, findAllByUserIdQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "AccessTokenRepository.FindAllByUserId.SelectContext")
+	}
+
+	return tokens, nil
+}
+
+// DeleteById Delete a token of user, sql.ErrNoRows when the user has no such token
+func (r *AccessTokenRepository) DeleteById(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, deleteByIdQuery, userID, tokenID)
+	if err != nil {
+		return errors.Wrap(err, "AccessTokenRepository.DeleteById.ExecContext")
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "AccessTokenRepository.DeleteById.RowsAffected")
+	} else if cnt == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteAllByUserId Delete every token of user
+func (r *AccessTokenRepository) DeleteAllByUserId(ctx context.Context, userID uuid.UUID) (int, error) {
+	res, err := r.db.ExecContext(ctx, deleteAllByUserIdQuery, userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "AccessTokenRepository.DeleteAllByUserId.ExecContext")
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "AccessTokenRepository.DeleteAllByUserId.RowsAffected")
+	}
+
+	return int(cnt), nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+var tokenColumns = []string{"token_id", "user_id", "name", "prefix", "token_hash", "scopes", "expires_at", "last_used_at", "created_at"}
+
+func TestAccessTokenRepository_Create(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	accessTokenPGRepository := NewAccessTokenPGRepository(sqlxDB)
+
+	mockToken := &models.PersonalAccessToken{
+		UserID:    uuid.New(),
+		Name:      "deploy script",
+		Prefix:    "ujp_abcdefgh",
+		TokenHash: "hash",
+		Scopes:    pq.StringArray{models.APIKeyScopeUsersRead},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tokenID := uuid.New()
+	mock.ExpectQuery(createAccessTokenQuery).
+		WithArgs(mockToken.UserID, mockToken.Name, mockToken.Prefix, mockToken.TokenHash, mockToken.Scopes, mockToken.ExpiresAt).
+		WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow(tokenID, mockToken.UserID, mockToken.Name, mockToken.Prefix, mockToken.TokenHash, "{users:read}", mockToken.ExpiresAt, nil, time.Now()))
+
+	createdToken, err := accessTokenPGRepository.Create(context.Background(), mockToken)
+	require.NoError(t, err)
+	require.Equal(t, tokenID, createdToken.TokenID)
+	require.Equal(t, pq.StringArray{models.APIKeyScopeUsersRead}, createdToken.Scopes)
+}
+
+func TestAccessTokenRepository_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	accessTokenPGRepository := NewAccessTokenPGRepository(sqlxDB)
+
+	tokenID := uuid.New()
+	mock.ExpectQuery(authenticateAccessTokenQuery).WithArgs("hash").WillReturnRows(
+		sqlmock.NewRows(tokenColumns).AddRow(tokenID, uuid.New(), "deploy script", "ujp_abcdefgh", "hash", "{users:read}", time.Now().Add(time.Hour), time.Now(), time.Now()),
+	)
+	token, err := accessTokenPGRepository.Authenticate(context.Background(), "hash")
+	require.NoError(t, err)
+	require.Equal(t, tokenID, token.TokenID)
+	require.NotNil(t, token.LastUsedAt)
+
+	mock.ExpectQuery(authenticateAccessTokenQuery).WithArgs("expired").WillReturnRows(sqlmock.NewRows(tokenColumns))
+	_, err = accessTokenPGRepository.Authenticate(context.Background(), "expired")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestAccessTokenRepository_DeleteById(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	accessTokenPGRepository := NewAccessTokenPGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+	tokenID := uuid.New()
+
+	mock.ExpectExec(deleteByIdQuery).WithArgs(userUUID, tokenID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, accessTokenPGRepository.DeleteById(context.Background(), userUUID, tokenID))
+
+	mock.ExpectExec(deleteByIdQuery).WithArgs(userUUID, tokenID).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.ErrorIs(t, accessTokenPGRepository.DeleteById(context.Background(), userUUID, tokenID), sql.ErrNoRows)
+
+	mock.ExpectExec(deleteAllByUserIdQuery).WithArgs(userUUID).WillReturnResult(sqlmock.NewResult(0, 3))
+	cnt, err := accessTokenPGRepository.DeleteAllByUserId(context.Background(), userUUID)
+	require.NoError(t, err)
+	require.Equal(t, 3, cnt)
+}
@@ -0,0 +1,18 @@
+package repository
+
+const (
+	createAccessTokenQuery = `INSERT INTO personal_access_tokens (user_id, name, prefix, token_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING token_id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, created_at`
+
+	// authenticateAccessTokenQuery records a use of an unexpired token, no row when the token is unknown, revoked or expired
+	authenticateAccessTokenQuery = `UPDATE personal_access_tokens SET last_used_at = NOW()
+		WHERE token_hash = $1 AND expires_at > NOW()
+		RETURNING token_id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, created_at`
+
+	findAllByUserIdQuery = `SELECT token_id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at DESC`
+
+	deleteByIdQuery = `DELETE FROM personal_access_tokens WHERE user_id = $1 AND token_id = $2`
+
+	deleteAllByUserIdQuery = `DELETE FROM personal_access_tokens WHERE user_id = $1`
+)
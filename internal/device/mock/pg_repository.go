@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pg_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/dinorain/useraja/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockDevicePGRepository is a mock of DevicePGRepository interface.
+type MockDevicePGRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDevicePGRepositoryMockRecorder
+}
+
+// MockDevicePGRepositoryMockRecorder is the mock recorder for MockDevicePGRepository.
+type MockDevicePGRepositoryMockRecorder struct {
+	mock *MockDevicePGRepository
+}
+
+// NewMockDevicePGRepository creates a new mock instance.
+func NewMockDevicePGRepository(ctrl *gomock.Controller) *MockDevicePGRepository {
+	mock := &MockDevicePGRepository{ctrl: ctrl}
+	mock.recorder = &MockDevicePGRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDevicePGRepository) EXPECT() *MockDevicePGRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockDevicePGRepository) Create(ctx context.Context, device *models.TrustedDevice) (*models.TrustedDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, device)
+	ret0, _ := ret[0].(*models.TrustedDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDevicePGRepositoryMockRecorder) Create(ctx, device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDevicePGRepository)(nil).Create), ctx, device)
+}
+
+// DeleteAllByUserId mocks base method.
+func (m *MockDevicePGRepository) DeleteAllByUserId(ctx context.Context, userID uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllByUserId", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAllByUserId indicates an expected call of DeleteAllByUserId.
+func (mr *MockDevicePGRepositoryMockRecorder) DeleteAllByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllByUserId", reflect.TypeOf((*MockDevicePGRepository)(nil).DeleteAllByUserId), ctx, userID)
+}
+
+// DeleteById mocks base method.
+func (m *MockDevicePGRepository) DeleteById(ctx context.Context, userID, deviceID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteById", ctx, userID, deviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteById indicates an expected call of DeleteById.
+func (mr *MockDevicePGRepositoryMockRecorder) DeleteById(ctx, userID, deviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockDevicePGRepository)(nil).DeleteById), ctx, userID, deviceID)
+}
+
+// FindAllByUserId mocks base method.
+func (m *MockDevicePGRepository) FindAllByUserId(ctx context.Context, userID uuid.UUID) ([]models.TrustedDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllByUserId", ctx, userID)
+	ret0, _ := ret[0].([]models.TrustedDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllByUserId indicates an expected call of FindAllByUserId.
+func (mr *MockDevicePGRepositoryMockRecorder) FindAllByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllByUserId", reflect.TypeOf((*MockDevicePGRepository)(nil).FindAllByUserId), ctx, userID)
+}
+
+// Touch mocks base method.
+func (m *MockDevicePGRepository) Touch(ctx context.Context, userID uuid.UUID, tokenHash, ip string) (*models.TrustedDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, userID, tokenHash, ip)
+	ret0, _ := ret[0].(*models.TrustedDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockDevicePGRepositoryMockRecorder) Touch(ctx, userID, tokenHash, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockDevicePGRepository)(nil).Touch), ctx, userID, tokenHash, ip)
+}
@@ -0,0 +1,19 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository.go -package mock
+package device
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// Trusted device Postgresql repository
+type DevicePGRepository interface {
+	Create(ctx context.Context, device *models.TrustedDevice) (*models.TrustedDevice, error)
+	Touch(ctx context.Context, userID uuid.UUID, tokenHash string, ip string) (*models.TrustedDevice, error)
+	FindAllByUserId(ctx context.Context, userID uuid.UUID) ([]models.TrustedDevice, error)
+	DeleteById(ctx context.Context, userID uuid.UUID, deviceID uuid.UUID) error
+	DeleteAllByUserId(ctx context.Context, userID uuid.UUID) (int, error)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/internal/device"
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// Trusted device repository
+type DeviceRepository struct {
+	db *sqlx.DB
+}
+
+var _ device.DevicePGRepository = (*DeviceRepository)(nil)
+
+// Trusted device repository constructor
+func NewDevicePGRepository(db *sqlx.DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+// Create new trusted device
+func (r *DeviceRepository) Create(ctx context.Context, device *models.TrustedDevice) (*models.TrustedDevice, error) {
+	createdDevice := &models.TrustedDevice{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createDeviceQuery,
+		device.UserID,
+		device.TokenHash,
+		device.Name,
+		device.IP,
+		device.ExpiresAt,
+	).StructScan(createdDevice); err != nil {
+		return nil, errors.Wrap(err, "DeviceRepository.Create.QueryRowxContext")
+	}
+
+	return createdDevice, nil
+}
+
+// Touch Record a use of the unexpired device of user with the token hash, sql.ErrNoRows when there is none
+func (r *DeviceRepository) Touch(ctx context.Context, userID uuid.UUID, tokenHash string, ip string) (*models.TrustedDevice, error) {
+	device := &models.TrustedDevice{}
+	if err := r.db.QueryRowxContext(ctx, touchDeviceQuery, userID, tokenHash, ip).StructScan(device); err != nil {
+		return nil, errors.Wrap(err, "DeviceRepository.Touch.QueryRowxContext")
+	}
+
+	return device, nil
+}
+
+// FindAllByUserId Find the unexpired devices of user, most recently seen first
+func (r *DeviceRepository) FindAllByUserId(ctx context.Context, userID uuid.UUID) ([]models.TrustedDevice, error) {
+	var devices []models.TrustedDevice
+	if err := r.db.SelectContext(ctx, &devices, findAllByUserIdQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "DeviceRepository.FindAllByUserId.SelectContext")
+	}
+
+	return devices, nil
+}
+
+// DeleteById Delete a device of user, sql.ErrNoRows when the user has no such device
+func (r *DeviceRepository) DeleteById(ctx context.Context, userID uuid.UUID, deviceID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, deleteByIdQuery, userID, deviceID)
+	if err != nil {
+		return errors.Wrap(err, "DeviceRepository.DeleteById.ExecContext")
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "DeviceRepository.DeleteById.RowsAffected")
+	} else if cnt == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteAllByUserId Delete every device of user
+func (r *DeviceRepository) DeleteAllByUserId(ctx context.Context, userID uuid.UUID) (int, error) {
+	res, err := r.db.ExecContext(ctx, deleteAllByUserIdQuery, userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "DeviceRepository.DeleteAllByUserId.ExecContext")
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "DeviceRepository.DeleteAllByUserId.RowsAffected")
+	}
+
+	return int(cnt), nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+var deviceColumns = []string{"device_id", "user_id", "token_hash", "name", "ip", "first_seen_at", "last_seen_at", "expires_at"}
+
+func TestDeviceRepository_Create(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	devicePGRepository := NewDevicePGRepository(sqlxDB)
+
+	mockDevice := &models.TrustedDevice{
+		UserID:    uuid.New(),
+		TokenHash: "hash",
+		Name:      "Firefox",
+		IP:        "127.0.0.1",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	deviceID := uuid.New()
+	mock.ExpectQuery(createDeviceQuery).
+		WithArgs(mockDevice.UserID, mockDevice.TokenHash, mockDevice.Name, mockDevice.IP, mockDevice.ExpiresAt).
+		WillReturnRows(sqlmock.NewRows(deviceColumns).AddRow(deviceID, mockDevice.UserID, mockDevice.TokenHash, mockDevice.Name, mockDevice.IP, time.Now(), time.Now(), mockDevice.ExpiresAt))
+
+	createdDevice, err := devicePGRepository.Create(context.Background(), mockDevice)
+	require.NoError(t, err)
+	require.Equal(t, deviceID, createdDevice.DeviceID)
+	require.Equal(t, mockDevice.Name, createdDevice.Name)
+}
+
+func TestDeviceRepository_Touch(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	devicePGRepository := NewDevicePGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+	deviceID := uuid.New()
+
+	mock.ExpectQuery(touchDeviceQuery).WithArgs(userUUID, "hash", "10.0.0.1").WillReturnRows(
+		sqlmock.NewRows(deviceColumns).AddRow(deviceID, userUUID, "hash", "Firefox", "10.0.0.1", time.Now(), time.Now(), time.Now().Add(time.Hour)),
+	)
+	touchedDevice, err := devicePGRepository.Touch(context.Background(), userUUID, "hash", "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, deviceID, touchedDevice.DeviceID)
+	require.Equal(t, "10.0.0.1", touchedDevice.IP)
+
+	mock.ExpectQuery(touchDeviceQuery).WithArgs(userUUID, "other", "10.0.0.1").WillReturnRows(sqlmock.NewRows(deviceColumns))
+	_, err = devicePGRepository.Touch(context.Background(), userUUID, "other", "10.0.0.1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeviceRepository_FindAllByUserId(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	devicePGRepository := NewDevicePGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+	mock.ExpectQuery(findAllByUserIdQuery).WithArgs(userUUID).WillReturnRows(
+		sqlmock.NewRows(deviceColumns).
+			AddRow(uuid.New(), userUUID, "a", "Firefox", "127.0.0.1", time.Now(), time.Now(), time.Now().Add(time.Hour)).
+			AddRow(uuid.New(), userUUID, "b", "Chrome", "127.0.0.1", time.Now(), time.Now(), time.Now().Add(time.Hour)),
+	)
+
+	devices, err := devicePGRepository.FindAllByUserId(context.Background(), userUUID)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+}
+
+func TestDeviceRepository_DeleteById(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	devicePGRepository := NewDevicePGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+	deviceID := uuid.New()
+
+	mock.ExpectExec(deleteByIdQuery).WithArgs(userUUID, deviceID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, devicePGRepository.DeleteById(context.Background(), userUUID, deviceID))
+
+	mock.ExpectExec(deleteByIdQuery).WithArgs(userUUID, deviceID).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.ErrorIs(t, devicePGRepository.DeleteById(context.Background(), userUUID, deviceID), sql.ErrNoRows)
+
+	mock.ExpectExec(deleteAllByUserIdQuery).WithArgs(userUUID).WillReturnResult(sqlmock.NewResult(0, 2))
+	cnt, err := devicePGRepository.DeleteAllByUserId(context.Background(), userUUID)
+	require.NoError(t, err)
+	require.Equal(t, 2, cnt)
+}
@@ -0,0 +1,18 @@
+package repository
+
+const (
+	createDeviceQuery = `INSERT INTO trusted_devices (user_id, token_hash, name, ip, expires_at) VALUES ($1, $2, $3, $4, $5)
+		RETURNING device_id, user_id, token_hash, name, ip, first_seen_at, last_seen_at, expires_at`
+
+	// touchDeviceQuery records a use of an unexpired device of the user, no row when the token is not trusted for the user
+	touchDeviceQuery = `UPDATE trusted_devices SET last_seen_at = NOW(), ip = $3
+		WHERE user_id = $1 AND token_hash = $2 AND expires_at > NOW()
+		RETURNING device_id, user_id, token_hash, name, ip, first_seen_at, last_seen_at, expires_at`
+
+	findAllByUserIdQuery = `SELECT device_id, user_id, token_hash, name, ip, first_seen_at, last_seen_at, expires_at
+		FROM trusted_devices WHERE user_id = $1 AND expires_at > NOW() ORDER BY last_seen_at DESC`
+
+	deleteByIdQuery = `DELETE FROM trusted_devices WHERE user_id = $1 AND device_id = $2`
+
+	deleteAllByUserIdQuery = `DELETE FROM trusted_devices WHERE user_id = $1`
+)
@@ -0,0 +1,16 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository.go -package mock
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// Idempotency repository
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, key string, fingerprint string, expire time.Duration) (*models.IdempotencyRecord, bool, error)
+	Save(ctx context.Context, key string, record *models.IdempotencyRecord, expire time.Duration) error
+	Release(ctx context.Context, key string) error
+}
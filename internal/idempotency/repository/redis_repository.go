@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/internal/idempotency"
+	"github.com/dinorain/useraja/internal/models"
+)
+
+const (
+	basePrefix      = "idempotency:"
+	reserveAttempts = 3
+)
+
+// Idempotency repository
+type idempotencyRepo struct {
+	redisClient *redis.Client
+	basePrefix  string
+}
+
+var _ idempotency.IdempotencyRepository = (*idempotencyRepo)(nil)
+
+// Idempotency repository constructor
+func NewIdempotencyRepository(redisClient *redis.Client) idempotency.IdempotencyRepository {
+	return &idempotencyRepo{redisClient: redisClient, basePrefix: basePrefix}
+}
+
+// Reserve the key for a request in flight, the record already stored under the key is returned when it is taken
+func (r *idempotencyRepo) Reserve(ctx context.Context, key string, fingerprint string, expire time.Duration) (*models.IdempotencyRecord, bool, error) {
+	recordBytes, err := json.Marshal(&models.IdempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, false, errors.Wrap(err, "idempotencyRepo.Reserve.json.Marshal")
+	}
+
+	// the key may be released or expire between SETNX and GET, the reservation is then attempted again
+	for attempt := 1; ; attempt++ {
+		reserved, err := r.redisClient.SetNX(ctx, r.generateKey(key), recordBytes, expire).Result()
+		if err != nil {
+			return nil, false, errors.Wrap(err, "idempotencyRepo.Reserve.redisClient.SetNX")
+		}
+		if reserved {
+			return nil, true, nil
+		}
+
+		storedBytes, err := r.redisClient.Get(ctx, r.generateKey(key)).Bytes()
+		if errors.Is(err, redis.Nil) && attempt < reserveAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, false, errors.Wrap(err, "idempotencyRepo.Reserve.redisClient.Get")
+		}
+
+		record := &models.IdempotencyRecord{}
+		if err = json.Unmarshal(storedBytes, record); err != nil {
+			return nil, false, errors.Wrap(err, "idempotencyRepo.Reserve.json.Unmarshal")
+		}
+		return record, false, nil
+	}
+}
+
+// Save the completed record of the key
+func (r *idempotencyRepo) Save(ctx context.Context, key string, record *models.IdempotencyRecord, expire time.Duration) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "idempotencyRepo.Save.json.Marshal")
+	}
+
+	if err = r.redisClient.Set(ctx, r.generateKey(key), recordBytes, expire).Err(); err != nil {
+		return errors.Wrap(err, "idempotencyRepo.Save.redisClient.Set")
+	}
+	return nil
+}
+
+// Release the key so the request may be retried
+func (r *idempotencyRepo) Release(ctx context.Context, key string) error {
+	if err := r.redisClient.Del(ctx, r.generateKey(key)).Err(); err != nil {
+		return errors.Wrap(err, "idempotencyRepo.Release.redisClient.Del")
+	}
+	return nil
+}
+
+func (r *idempotencyRepo) generateKey(key string) string {
+	return r.basePrefix + key
+}
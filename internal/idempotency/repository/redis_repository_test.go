@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/internal/idempotency"
+	"github.com/dinorain/useraja/internal/models"
+)
+
+func SetupRedis() idempotency.IdempotencyRepository {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	return NewIdempotencyRepository(client)
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	t.Parallel()
+
+	idempotencyRepository := SetupRedis()
+	ctx := context.Background()
+
+	t.Run("Reserve", func(t *testing.T) {
+		record, reserved, err := idempotencyRepository.Reserve(ctx, "reserve", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+		require.Nil(t, record)
+
+		record, reserved, err = idempotencyRepository.Reserve(ctx, "reserve", "other", time.Minute)
+		require.NoError(t, err)
+		require.False(t, reserved)
+		require.Equal(t, "fingerprint", record.Fingerprint)
+		require.False(t, record.Completed)
+	})
+
+	t.Run("Save", func(t *testing.T) {
+		_, reserved, err := idempotencyRepository.Reserve(ctx, "save", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		err = idempotencyRepository.Save(ctx, "save", &models.IdempotencyRecord{
+			Fingerprint: "fingerprint",
+			Completed:   true,
+			Status:      201,
+			Body:        []byte(`{"user_id":"id"}`),
+		}, time.Hour)
+		require.NoError(t, err)
+
+		record, reserved, err := idempotencyRepository.Reserve(ctx, "save", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		require.False(t, reserved)
+		require.True(t, record.Completed)
+		require.Equal(t, 201, record.Status)
+		require.Equal(t, `{"user_id":"id"}`, string(record.Body))
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		_, reserved, err := idempotencyRepository.Reserve(ctx, "release", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		require.NoError(t, idempotencyRepository.Release(ctx, "release"))
+
+		_, reserved, err = idempotencyRepository.Reserve(ctx, "release", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+	})
+}
+
+// releaseBeforeGet releases the key right after a lost SETNX, like a request finishing in between
+type releaseBeforeGet struct {
+	mr       *miniredis.Miniredis
+	released bool
+}
+
+func (h *releaseBeforeGet) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	if cmd.Name() == "get" && !h.released {
+		h.released = true
+		h.mr.Del(cmd.Args()[1].(string))
+	}
+	return ctx, nil
+}
+
+func (h *releaseBeforeGet) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+func (h *releaseBeforeGet) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *releaseBeforeGet) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+func TestIdempotencyRepository_ReserveReleasedKey(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	idempotencyRepository := NewIdempotencyRepository(client)
+	ctx := context.Background()
+
+	_, reserved, err := idempotencyRepository.Reserve(ctx, "released", "fingerprint", time.Minute)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	client.AddHook(&releaseBeforeGet{mr: mr})
+
+	record, reserved, err := idempotencyRepository.Reserve(ctx, "released", "fingerprint", time.Minute)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.Nil(t, record)
+}
@@ -0,0 +1,150 @@
+package interceptors
+
+import (
+	"context"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dinorain/useraja/internal/accesstoken"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/grpc_errors"
+)
+
+// APIKeyAuth unary interceptor authenticating calls made with "authorization: ApiKey <key>" or
+// "authorization: Bearer ujp_..." personal access token metadata. methodScopes maps the full method names keys and tokens
+// may call to the scope they need, every other method rejects them. Sessions of OAuth clients need the scope of the
+// methods of methodScopes too and may only call the methods of clientMethods, such as logging out, without one.
+// Other calls pass through to the session based checks of the handlers
+func (im *InterceptorManager) APIKeyAuth(methodScopes map[string]string, clientMethods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := im.apiKeyCtx(ctx, info.FullMethod, methodScopes, clientMethods)
+		if err != nil {
+			return nil, grpc_errors.ErrorResponse(err, "APIKeyAuth", im.cfg.Server.DebugErrorsResponse)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAPIKeyAuth stream interceptor counterpart of APIKeyAuth
+func (im *InterceptorManager) StreamAPIKeyAuth(methodScopes map[string]string, clientMethods ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := im.apiKeyCtx(ss.Context(), info.FullMethod, methodScopes, clientMethods)
+		if err != nil {
+			return grpc_errors.ErrorResponse(err, "StreamAPIKeyAuth", im.cfg.Server.DebugErrorsResponse)
+		}
+
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
+
+// apiKeyCtx ctx carrying the API key or personal access token of the call metadata, ctx itself when the call has neither
+func (im *InterceptorManager) apiKeyCtx(ctx context.Context, fullMethod string, methodScopes map[string]string, clientMethods []string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	for _, value := range md.Get("authorization") {
+		if key, ok := serviceaccount.ParseAuthorization(value); ok {
+			scope, err := methodScope(fullMethod, methodScopes)
+			if err != nil {
+				return nil, err
+			}
+
+			apiKey, err := im.apiKeyUC.Authenticate(ctx, key)
+			if err != nil {
+				im.logger.Warnf("apiKeyUC.Authenticate: %v", err)
+				return nil, err
+			}
+			if !apiKey.HasScope(scope) {
+				return nil, domain_errors.InsufficientScope(scope)
+			}
+
+			return serviceaccount.NewContext(ctx, apiKey), nil
+		}
+
+		if token, ok := accesstoken.ParseAuthorization(value); ok {
+			scope, err := methodScope(fullMethod, methodScopes)
+			if err != nil {
+				return nil, err
+			}
+
+			accessToken, tokenUser, err := im.userUC.AuthenticateAccessToken(ctx, token)
+			if err != nil {
+				im.logger.Warnf("userUC.AuthenticateAccessToken: %v", err)
+				return nil, err
+			}
+			if !accessToken.HasScope(scope) {
+				return nil, domain_errors.InsufficientScope(scope)
+			}
+
+			return accesstoken.NewContext(ctx, accessToken, tokenUser), nil
+		}
+	}
+
+	if err := im.checkClientSessionScope(ctx, md, fullMethod, methodScopes, clientMethods); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// checkClientSessionScope reject calls made with the session of an OAuth client to methods outside methodScopes and
+// clientMethods, and to methods of methodScopes unless the session was granted its scope and its user is still allowed
+// it. Calls of other sessions are left to the handlers
+func (im *InterceptorManager) checkClientSessionScope(ctx context.Context, md metadata.MD, fullMethod string, methodScopes map[string]string, clientMethods []string) error {
+	sessionID := md.Get("session_id")
+	if len(sessionID) == 0 || sessionID[0] == "" {
+		return nil
+	}
+
+	session, err := im.sessUC.GetSessionById(ctx, sessionID[0])
+	if err != nil {
+		im.logger.Warnf("sessUC.GetSessionById: %v", err)
+		return err
+	}
+	if session.ClientID == "" {
+		return nil
+	}
+	for _, method := range clientMethods {
+		if method == fullMethod {
+			return nil
+		}
+	}
+
+	scope, err := methodScope(fullMethod, methodScopes)
+	if err != nil {
+		return err
+	}
+
+	sessionUser, err := im.userUC.CachedFindById(ctx, session.UserID)
+	if err != nil {
+		im.logger.Warnf("userUC.CachedFindById: %v", err)
+		return err
+	}
+	if !sessionUser.IsActive() {
+		return domain_errors.ErrUserInactive
+	}
+	for _, granted := range models.ScopesForRole(sessionUser.Role, session.Scopes) {
+		if granted == scope {
+			return nil
+		}
+	}
+	return domain_errors.InsufficientScope(scope)
+}
+
+// methodScope scope keys and tokens need to call fullMethod, domain_errors.ErrForbidden when they may not call it
+func methodScope(fullMethod string, methodScopes map[string]string) (string, error) {
+	scope, ok := methodScopes[fullMethod]
+	if !ok {
+		return "", domain_errors.ErrForbidden
+	}
+	return scope, nil
+}
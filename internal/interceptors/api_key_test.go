@@ -0,0 +1,143 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/accesstoken"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	mockServiceAccountUC "github.com/dinorain/useraja/internal/serviceaccount/mock"
+	mockSessUC "github.com/dinorain/useraja/internal/session/mock"
+	mockUserUC "github.com/dinorain/useraja/internal/user/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestInterceptorManager_APIKeyAuth(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiKeyUC := mockServiceAccountUC.NewMockServiceAccountUseCase(ctrl)
+	userUC := mockUserUC.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	im := NewInterceptorManager(logger.NewAppLogger(nil), &config.Config{}, sessUC, apiKeyUC, userUC)
+	interceptor := im.APIKeyAuth(map[string]string{
+		"/userService.UserService/FindAll":     models.APIKeyScopeUsersRead,
+		"/userService.UserService/SuspendUser": models.APIKeyScopeUsersWrite,
+	}, "/userService.UserService/Logout")
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if apiKey, ok := serviceaccount.FromContext(ctx); ok {
+			return apiKey.KeyID, nil
+		}
+		if _, tokenUser, ok := accesstoken.FromContext(ctx); ok {
+			return tokenUser.UserID, nil
+		}
+		return "session", nil
+	}
+	call := func(md metadata.MD, fullMethod string) (interface{}, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+	}
+
+	readKey := &models.APIKey{KeyID: uuid.New(), ServiceAccountID: uuid.New(), Scopes: []string{models.APIKeyScopeUsersRead}}
+
+	t.Run("Key with scope", func(t *testing.T) {
+		apiKeyUC.EXPECT().Authenticate(gomock.Any(), "uja_read").Return(readKey, nil)
+
+		resp, err := call(metadata.Pairs("authorization", "ApiKey uja_read"), "/userService.UserService/FindAll")
+		require.NoError(t, err)
+		require.Equal(t, readKey.KeyID, resp)
+	})
+
+	t.Run("Key without scope", func(t *testing.T) {
+		apiKeyUC.EXPECT().Authenticate(gomock.Any(), "uja_read").Return(readKey, nil)
+
+		_, err := call(metadata.Pairs("authorization", "ApiKey uja_read"), "/userService.UserService/SuspendUser")
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		st, _ := status.FromError(err)
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		require.Equal(t, domain_errors.ReasonInsufficientScope, info.GetReason())
+		require.Equal(t, models.APIKeyScopeUsersWrite, info.GetMetadata()[domain_errors.MetadataScope])
+	})
+
+	t.Run("Method closed to keys", func(t *testing.T) {
+		_, err := call(metadata.Pairs("authorization", "ApiKey uja_read"), "/userService.UserService/DeactivateMe")
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		apiKeyUC.EXPECT().Authenticate(gomock.Any(), "uja_revoked").Return(nil, domain_errors.ErrInvalidAPIKey)
+
+		_, err := call(metadata.Pairs("authorization", "ApiKey uja_revoked"), "/userService.UserService/FindAll")
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Personal access token", func(t *testing.T) {
+		userID := uuid.New()
+		userUC.EXPECT().AuthenticateAccessToken(gomock.Any(), "ujp_read").Return(
+			&models.PersonalAccessToken{UserID: userID, Scopes: []string{models.APIKeyScopeUsersRead}}, &models.User{UserID: userID}, nil,
+		)
+
+		resp, err := call(metadata.Pairs("authorization", "Bearer ujp_read"), "/userService.UserService/FindAll")
+		require.NoError(t, err)
+		require.Equal(t, userID, resp)
+	})
+
+	t.Run("Session call passes through", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "session").Return(&models.Session{SessionID: "session", UserID: uuid.New()}, nil)
+
+		resp, err := call(metadata.Pairs("session_id", "session"), "/userService.UserService/DeactivateMe")
+		require.NoError(t, err)
+		require.Equal(t, "session", resp)
+	})
+
+	t.Run("OAuth client session", func(t *testing.T) {
+		userID := uuid.New()
+		clientSession := &models.Session{SessionID: "client", UserID: userID, ClientID: uuid.New().String(), Scopes: []string{models.APIKeyScopeUsersRead}}
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "client").Return(clientSession, nil).Times(2)
+		userUC.EXPECT().CachedFindById(gomock.Any(), userID).Return(&models.User{UserID: userID, Role: models.UserRoleAdmin, Status: models.UserStatusActive}, nil).Times(2)
+
+		resp, err := call(metadata.Pairs("session_id", "client"), "/userService.UserService/FindAll")
+		require.NoError(t, err)
+		require.Equal(t, "session", resp)
+
+		_, err = call(metadata.Pairs("session_id", "client"), "/userService.UserService/SuspendUser")
+		require.Error(t, err)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("OAuth client session on methods without scope", func(t *testing.T) {
+		clientSession := &models.Session{SessionID: "client", UserID: uuid.New(), ClientID: uuid.New().String(), Scopes: []string{models.APIKeyScopeUsersRead}}
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "client").Return(clientSession, nil).Times(2)
+
+		_, err := call(metadata.Pairs("session_id", "client"), "/userService.UserService/UpdateById")
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		resp, err := call(metadata.Pairs("session_id", "client"), "/userService.UserService/Logout")
+		require.NoError(t, err)
+		require.Equal(t, "session", resp)
+	})
+
+	t.Run("Ended OAuth client session", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "logged-out").Return(nil, domain_errors.ErrSessionNotFound)
+
+		_, err := call(metadata.Pairs("session_id", "logged-out"), "/userService.UserService/FindAll")
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
@@ -8,20 +8,32 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	"github.com/dinorain/useraja/internal/session"
+	"github.com/dinorain/useraja/internal/user"
 	"github.com/dinorain/useraja/pkg/logger"
 )
 
+// redactedMetadataKeys metadata carrying credentials, their values are never logged
+var redactedMetadataKeys = []string{"authorization", "session_id"}
+
 // InterceptorManager
 type InterceptorManager struct {
 	logger logger.Logger
 	cfg    *config.Config
+	sessUC session.SessUseCase
+	apiKeyUC serviceaccount.ServiceAccountUseCase
+	userUC user.UserUseCase
 }
 
 // InterceptorManager constructor
-func NewInterceptorManager(logger logger.Logger, cfg *config.Config) *InterceptorManager {
+func NewInterceptorManager(logger logger.Logger, cfg *config.Config, sessUC session.SessUseCase, apiKeyUC serviceaccount.ServiceAccountUseCase, userUC user.UserUseCase) *InterceptorManager {
 	return &InterceptorManager{
 		logger: logger,
 		cfg: cfg,
+		sessUC: sessUC,
+		apiKeyUC: apiKeyUC,
+		userUC: userUC,
 	}
 }
 
@@ -29,9 +41,31 @@ func NewInterceptorManager(logger logger.Logger, cfg *config.Config) *Intercepto
 func (im *InterceptorManager) Logger(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 	start := time.Now()
 	md, _ := metadata.FromIncomingContext(ctx)
+	md = redactMetadata(md)
 	reply, err := handler(ctx, req)
 	im.logger.Infof("Method: %s, Time: %v, Metadata: %v, Err: %v", info.FullMethod, time.Since(start), md, err)
 
 	return reply, err
 }
 
+// StreamLogger Interceptor
+func (im *InterceptorManager) StreamLogger(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	md, _ := metadata.FromIncomingContext(ss.Context())
+	md = redactMetadata(md)
+	err := handler(srv, ss)
+	im.logger.Infof("Method: %s, Time: %v, Metadata: %v, Err: %v", info.FullMethod, time.Since(start), md, err)
+
+	return err
+}
+
+// redactMetadata copy of the metadata safe to log
+func redactMetadata(md metadata.MD) metadata.MD {
+	redacted := md.Copy()
+	for _, key := range redactedMetadataKeys {
+		if len(redacted.Get(key)) > 0 {
+			redacted.Set(key, "[REDACTED]")
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,22 @@
+package interceptors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRedactMetadata(t *testing.T) {
+	t.Parallel()
+
+	md := metadata.Pairs("authorization", "ApiKey secret", "session_id", "session", "user-agent", "grpc-go")
+	redacted := redactMetadata(md)
+
+	require.Equal(t, []string{"[REDACTED]"}, redacted.Get("authorization"))
+	require.Equal(t, []string{"[REDACTED]"}, redacted.Get("session_id"))
+	require.Equal(t, []string{"grpc-go"}, redacted.Get("user-agent"))
+	require.Equal(t, []string{"ApiKey secret"}, md.Get("authorization"))
+
+	require.Empty(t, redactMetadata(nil))
+}
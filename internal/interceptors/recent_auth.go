@@ -0,0 +1,54 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/grpc_errors"
+)
+
+// RequireRecentAuth unary interceptor allowing the call only when the user of the session_id metadata authenticated within maxAge,
+// using one of methods when any are given
+func (im *InterceptorManager) RequireRecentAuth(maxAge time.Duration, methods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return nil, grpc_errors.ErrorResponse(grpc_errors.ErrNoCtxMetaData, "RequireRecentAuth", im.cfg.Server.DebugErrorsResponse)
+		}
+		sessionID := md.Get("session_id")
+		if len(sessionID) == 0 || sessionID[0] == "" {
+			return nil, grpc_errors.ErrorResponse(grpc_errors.ErrInvalidSessionId, "RequireRecentAuth", im.cfg.Server.DebugErrorsResponse)
+		}
+
+		session, err := im.sessUC.GetSessionById(ctx, sessionID[0])
+		if err != nil {
+			im.logger.Errorf("sessUC.GetSessionById: %v", err)
+			return nil, grpc_errors.ErrorResponse(err, "sessUC.GetSessionById", im.cfg.Server.DebugErrorsResponse)
+		}
+
+		if !session.AuthenticatedWithin(maxAge, methods...) {
+			return nil, grpc_errors.ErrorResponse(domain_errors.ReauthRequired(maxAge, methods...), "RequireRecentAuth", im.cfg.Server.DebugErrorsResponse)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// ForMethods apply interceptor only to the unary calls of the given full method names, e.g. /userService.UserService/DeactivateMe
+func ForMethods(interceptor grpc.UnaryServerInterceptor, fullMethods ...string) grpc.UnaryServerInterceptor {
+	selected := make(map[string]bool, len(fullMethods))
+	for _, fullMethod := range fullMethods {
+		selected[fullMethod] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !selected[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, info, handler)
+	}
+}
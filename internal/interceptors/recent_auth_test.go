@@ -0,0 +1,74 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/models"
+	mockSessUC "github.com/dinorain/useraja/internal/session/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestInterceptorManager_RequireRecentAuth(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	im := NewInterceptorManager(logger.NewAppLogger(nil), &config.Config{}, sessUC, nil, nil)
+	interceptor := ForMethods(im.RequireRecentAuth(5*time.Minute, models.AuthMethodPassword), "/userService.UserService/DeactivateMe")
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	call := func(sessionID string, fullMethod string) (interface{}, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionID))
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+	}
+
+	t.Run("Recent", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "recent").Return(&models.Session{
+			AuthTime: time.Now(),
+			AMR:      []string{models.AuthMethodPassword},
+		}, nil)
+
+		resp, err := call("recent", "/userService.UserService/DeactivateMe")
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "stale").Return(&models.Session{
+			AuthTime: time.Now().Add(-time.Hour),
+			AMR:      []string{models.AuthMethodPassword},
+		}, nil)
+
+		_, err := call("stale", "/userService.UserService/DeactivateMe")
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		st, _ := status.FromError(err)
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		require.Equal(t, domain_errors.ReasonReauthRequired, info.GetReason())
+		require.Equal(t, "300", info.GetMetadata()[domain_errors.MetadataMaxAge])
+		require.Equal(t, models.AuthMethodPassword, info.GetMetadata()[domain_errors.MetadataMethods])
+	})
+
+	t.Run("Other method is not checked", func(t *testing.T) {
+		resp, err := call("stale", "/userService.UserService/GetMe")
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	})
+}
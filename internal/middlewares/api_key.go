@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+
+	"github.com/dinorain/useraja/internal/accesstoken"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
+)
+
+// clientAllowedKey echo context key set once the route accepted the OAuth client token of the request
+const clientAllowedKey = "oauth_client_allowed"
+
+// RequireScope allow a request made with an API key, personal access token or OAuth client token only when it was
+// granted scope, jwt sessions of the user pass through. Must follow IsLoggedIn
+func (mw *middlewareManager) RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if credential, ok := scopedCredential(c); ok && !credential.HasScope(scope) {
+				return httpErrors.ErrorCtxResponse(c, domain_errors.InsufficientScope(scope), mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			c.Set(clientAllowedKey, true)
+			return next(c)
+		}
+	}
+}
+
+// IsAdminOrScope allow admins, and requests made with an API key, personal access token or OAuth client token
+// granted scope. Must follow IsLoggedIn
+func (mw *middlewareManager) IsAdminOrScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		isAdmin := mw.IsAdmin(next)
+		return func(c echo.Context) error {
+			credential, ok := scopedCredential(c)
+			if !ok {
+				return isAdmin(c)
+			}
+
+			if !credential.HasScope(scope) {
+				return httpErrors.ErrorCtxResponse(c, domain_errors.InsufficientScope(scope), mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			c.Set(clientAllowedKey, true)
+			return next(c)
+		}
+	}
+}
+
+// AllowOAuthClient let OAuth client tokens call a route that declares no scope, such as logging out. Must follow IsLoggedIn
+func (mw *middlewareManager) AllowOAuthClient(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Set(clientAllowedKey, true)
+		return next(c)
+	}
+}
+
+// OAuthClientDenied reports whether the request carries an OAuth client access token on a route that declares no
+// scope, such tokens only reach routes guarded by RequireScope, IsAdminOrScope or AllowOAuthClient
+func OAuthClientDenied(c echo.Context) bool {
+	if _, ok := clientTokenScopes(c); !ok {
+		return false
+	}
+	allowed, _ := c.Get(clientAllowedKey).(bool)
+	return !allowed
+}
+
+// scopedCredential API key, personal access token or OAuth client access token the request authenticated with,
+// false for jwt sessions of the user
+func scopedCredential(c echo.Context) (interface{ HasScope(scope string) bool }, bool) {
+	ctx := c.Request().Context()
+	if apiKey, ok := serviceaccount.FromContext(ctx); ok {
+		return apiKey, true
+	}
+	if token, _, ok := accesstoken.FromContext(ctx); ok {
+		return token, true
+	}
+	if scopes, ok := clientTokenScopes(c); ok {
+		return scopes, true
+	}
+	return nil, false
+}
+
+// clientScopesKey echo context key of the scopes IsLoggedIn granted the OAuth client token of the request
+const clientScopesKey = "client_scopes"
+
+// clientScopes scopes of an access token issued to an OAuth client
+type clientScopes []string
+
+// HasScope reports whether the client token was granted scope
+func (s clientScopes) HasScope(scope string) bool {
+	for _, granted := range s {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// clientTokenScopes scopes of the jwt access token when it was issued to an OAuth client, those its session was granted
+// and its user still holds. A token that did not pass IsLoggedIn has none
+func clientTokenScopes(c echo.Context) (clientScopes, bool) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, false
+	}
+	if clientID, _ := claims["client_id"].(string); clientID == "" {
+		return nil, false
+	}
+
+	scopes, _ := c.Get(clientScopesKey).(clientScopes)
+	return scopes, true
+}
@@ -0,0 +1,145 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/accesstoken"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	mockServiceAccountUC "github.com/dinorain/useraja/internal/serviceaccount/mock"
+	mockSessUC "github.com/dinorain/useraja/internal/session/mock"
+	mockUserUC "github.com/dinorain/useraja/internal/user/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestMiddlewareManager_APIKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiKeyUC := mockServiceAccountUC.NewMockServiceAccountUseCase(ctrl)
+	userUC := mockUserUC.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	cfg := &config.Config{Server: config.ServerConfig{JwtSecretKey: "secret"}}
+	mw := NewMiddlewareManager(logger.NewAppLogger(nil), cfg, nil, nil, sessUC, apiKeyUC, userUC)
+
+	e := echo.New()
+	serve := func(authorization string, middlewares ...echo.MiddlewareFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+		res := httptest.NewRecorder()
+		e.GET("/user", func(c echo.Context) error {
+			if _, ok := serviceaccount.FromContext(c.Request().Context()); ok {
+				return c.NoContent(http.StatusOK)
+			}
+			if _, _, ok := accesstoken.FromContext(c.Request().Context()); ok {
+				return c.NoContent(http.StatusAccepted)
+			}
+			return c.NoContent(http.StatusNoContent)
+		}, append([]echo.MiddlewareFunc{mw.IsLoggedIn()}, middlewares...)...)
+		e.ServeHTTP(res, req)
+		return res
+	}
+
+	readKey := &models.APIKey{KeyID: uuid.New(), ServiceAccountID: uuid.New(), Scopes: []string{models.APIKeyScopeUsersRead}}
+
+	t.Run("Key with scope", func(t *testing.T) {
+		apiKeyUC.EXPECT().Authenticate(gomock.Any(), "uja_read").Return(readKey, nil)
+
+		require.Equal(t, http.StatusOK, serve("ApiKey uja_read", mw.RequireScope(models.APIKeyScopeUsersRead)).Code)
+	})
+
+	t.Run("Key without scope", func(t *testing.T) {
+		apiKeyUC.EXPECT().Authenticate(gomock.Any(), "uja_read").Return(readKey, nil)
+
+		require.Equal(t, http.StatusForbidden, serve("ApiKey uja_read", mw.IsAdminOrScope(models.APIKeyScopeUsersWrite)).Code)
+	})
+
+	t.Run("Key on admin only route", func(t *testing.T) {
+		apiKeyUC.EXPECT().Authenticate(gomock.Any(), "uja_read").Return(readKey, nil)
+
+		require.Equal(t, http.StatusForbidden, serve("ApiKey uja_read", mw.IsAdmin).Code)
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		apiKeyUC.EXPECT().Authenticate(gomock.Any(), "uja_revoked").Return(nil, domain_errors.ErrInvalidAPIKey)
+
+		require.Equal(t, http.StatusUnauthorized, serve("ApiKey uja_revoked", mw.RequireScope(models.APIKeyScopeUsersRead)).Code)
+	})
+
+	t.Run("Personal access token with scope", func(t *testing.T) {
+		userUC.EXPECT().AuthenticateAccessToken(gomock.Any(), "ujp_write").Return(
+			&models.PersonalAccessToken{Scopes: []string{models.APIKeyScopeUsersWrite}}, &models.User{Role: models.UserRoleAdmin}, nil,
+		)
+
+		require.Equal(t, http.StatusAccepted, serve("Bearer ujp_write", mw.IsAdminOrScope(models.APIKeyScopeUsersWrite)).Code)
+	})
+
+	t.Run("Personal access token without scope", func(t *testing.T) {
+		userUC.EXPECT().AuthenticateAccessToken(gomock.Any(), "ujp_read").Return(
+			&models.PersonalAccessToken{Scopes: []string{models.APIKeyScopeUsersRead}}, &models.User{Role: models.UserRoleUser}, nil,
+		)
+
+		require.Equal(t, http.StatusForbidden, serve("Bearer ujp_read", mw.IsAdminOrScope(models.APIKeyScopeUsersWrite)).Code)
+	})
+
+	t.Run("Revoked personal access token", func(t *testing.T) {
+		userUC.EXPECT().AuthenticateAccessToken(gomock.Any(), "ujp_revoked").Return(nil, nil, domain_errors.ErrInvalidAccessToken)
+
+		require.Equal(t, http.StatusUnauthorized, serve("Bearer ujp_revoked", mw.RequireScope(models.APIKeyScopeUsersRead)).Code)
+	})
+
+	t.Run("Admin token", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"session_id": "admin", "role": models.UserRoleAdmin}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "admin").Return(&models.Session{SessionID: "admin", UserID: uuid.New()}, nil)
+
+		require.Equal(t, http.StatusNoContent, serve("Bearer "+token, mw.IsAdminOrScope(models.APIKeyScopeUsersWrite)).Code)
+	})
+
+	t.Run("Ended session", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"session_id": "ended", "role": models.UserRoleAdmin}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "ended").Return(nil, domain_errors.ErrSessionNotFound)
+
+		require.Equal(t, http.StatusUnauthorized, serve("Bearer "+token, mw.IsAdminOrScope(models.APIKeyScopeUsersWrite)).Code)
+	})
+
+	t.Run("OAuth client token", func(t *testing.T) {
+		userID := uuid.New()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"session_id": "client",
+			"role":       models.UserRoleAdmin,
+			"client_id":  uuid.New().String(),
+			"scope":      models.APIKeyScopeUsersRead + " " + models.APIKeyScopeUsersWrite,
+		}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		clientSession := &models.Session{
+			SessionID: "client",
+			UserID:    userID,
+			ClientID:  uuid.New().String(),
+			Scopes:    []string{models.APIKeyScopeUsersRead, models.APIKeyScopeUsersWrite},
+		}
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "client").Return(clientSession, nil).Times(4)
+		// the user lost the admin role since the client was authorized
+		userUC.EXPECT().CachedFindById(gomock.Any(), userID).Return(&models.User{UserID: userID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil).Times(3)
+
+		require.Equal(t, http.StatusNoContent, serve("Bearer "+token, mw.RequireScope(models.APIKeyScopeUsersRead)).Code)
+		require.Equal(t, http.StatusForbidden, serve("Bearer "+token, mw.IsAdminOrScope(models.APIKeyScopeUsersWrite)).Code)
+		require.Equal(t, http.StatusForbidden, serve("Bearer "+token, mw.IsAdmin).Code)
+
+		userUC.EXPECT().CachedFindById(gomock.Any(), userID).Return(&models.User{UserID: userID, Role: models.UserRoleUser, Status: models.UserStatusSuspended}, nil)
+		require.Equal(t, http.StatusForbidden, serve("Bearer "+token, mw.RequireScope(models.APIKeyScopeUsersRead)).Code)
+	})
+}
@@ -0,0 +1,141 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/pkg/constants"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
+)
+
+const idempotencyKeyMaxLength = 255
+
+// idempotencyStoredHeaders response headers replayed together with the stored body
+var idempotencyStoredHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, constants.HeaderETag}
+
+// Idempotency replay the stored response of a mutating request retried with the same Idempotency-Key header.
+// Keys are scoped by the Authorization header, or by the client IP for anonymous requests, a key still in flight is
+// rejected with 409 and a key reused for a different request with 400. Failed requests release their key so they can
+// be retried. Cookies are never stored, routes issuing credentials must not use it.
+func (mw *middlewareManager) Idempotency(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		key := req.Header.Get(constants.HeaderIdempotencyKey)
+		if key == "" || mw.idempotencyRepo == nil || !mw.cfg.Idempotency.Enabled || !isMutatingMethod(req.Method) {
+			return next(c)
+		}
+
+		if len(key) > idempotencyKeyMaxLength {
+			mw.logger.Warnf("Idempotency-Key length: %v", len(key))
+			err := fmt.Errorf("longer than %d characters", idempotencyKeyMaxLength)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField(constants.HeaderIdempotencyKey, err), mw.cfg.Http.DebugErrorsResponse)
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			mw.logger.WarnMsg("io.ReadAll", err)
+			return httpErrors.ErrorCtxResponse(c, err, mw.cfg.Http.DebugErrorsResponse)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := req.Header.Get(echo.HeaderAuthorization)
+		if scope == "" {
+			scope = "anonymous " + c.RealIP()
+		}
+		scopedKey := idempotencyHash(scope, key)
+		fingerprint := idempotencyHash(req.Method, req.URL.Path, string(body))
+
+		record, reserved, err := mw.idempotencyRepo.Reserve(req.Context(), scopedKey, fingerprint, time.Duration(mw.cfg.Idempotency.LockExpire)*time.Second)
+		if err != nil {
+			mw.logger.Errorf("idempotencyRepo.Reserve: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, mw.cfg.Http.DebugErrorsResponse)
+		}
+
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				mw.logger.Warnf("Idempotency-Key reused: %v %v", req.Method, req.URL.Path)
+				return httpErrors.ErrorCtxResponse(c, domain_errors.ErrIdempotencyKeyReused, mw.cfg.Http.DebugErrorsResponse)
+			case !record.Completed:
+				mw.logger.Warnf("Idempotency-Key in flight: %v %v", req.Method, req.URL.Path)
+				return httpErrors.ErrorCtxResponse(c, domain_errors.ErrIdempotencyKeyInUse, mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			for name, value := range record.Header {
+				c.Response().Header().Set(name, value)
+			}
+			c.Response().Header().Set(constants.HeaderIdempotentReplayed, "true")
+			c.Response().WriteHeader(record.Status)
+			_, err = c.Response().Write(record.Body)
+			return err
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+
+		// the outcome is stored even when the client went away, that is when it retries
+		ctx := context.Background()
+		if err := next(c); err != nil || c.Response().Status >= http.StatusInternalServerError {
+			if releaseErr := mw.idempotencyRepo.Release(ctx, scopedKey); releaseErr != nil {
+				mw.logger.Errorf("idempotencyRepo.Release: %v", releaseErr)
+			}
+			return err
+		}
+
+		completed := &models.IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      c.Response().Status,
+			Header:      map[string]string{},
+			Body:        recorder.body.Bytes(),
+		}
+		for _, name := range idempotencyStoredHeaders {
+			if value := c.Response().Header().Get(name); value != "" {
+				completed.Header[name] = value
+			}
+		}
+		if err := mw.idempotencyRepo.Save(ctx, scopedKey, completed, time.Duration(mw.cfg.Idempotency.Expire)*time.Second); err != nil {
+			mw.logger.Errorf("idempotencyRepo.Save: %v", err)
+		}
+
+		return nil
+	}
+}
+
+// idempotencyRecorder keeps a copy of the response body written by the handler
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func idempotencyHash(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
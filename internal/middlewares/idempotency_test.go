@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	idempotencyRepository "github.com/dinorain/useraja/internal/idempotency/repository"
+	"github.com/dinorain/useraja/pkg/constants"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestMiddlewareManager_Idempotency(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	idempotencyRepo := idempotencyRepository.NewIdempotencyRepository(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	cfg := &config.Config{Idempotency: config.Idempotency{Enabled: true, Expire: 60, LockExpire: 10}}
+	mw := NewMiddlewareManager(logger.NewAppLogger(nil), cfg, idempotencyRepo, nil, nil, nil, nil)
+
+	calls := 0
+	failures := 0
+	e := echo.New()
+	handler := mw.Idempotency(func(c echo.Context) error {
+		calls++
+		if failures > 0 {
+			failures--
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed"})
+		}
+		c.Response().Header().Set(constants.HeaderETag, `"1"`)
+		c.SetCookie(&http.Cookie{Name: "trusted_device", Value: "device"})
+		return c.JSON(http.StatusCreated, map[string]int{"call": calls})
+	})
+
+	serveFrom := func(authorization string, remoteAddr string, method string, key string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/user", strings.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		if key != "" {
+			req.Header.Set(constants.HeaderIdempotencyKey, key)
+		}
+		res := httptest.NewRecorder()
+		require.NoError(t, handler(e.NewContext(req, res)))
+		return res
+	}
+	serve := func(method string, key string, body string) *httptest.ResponseRecorder {
+		return serveFrom("bearer token", "192.0.2.1:1234", method, key, body)
+	}
+
+	t.Run("Replay", func(t *testing.T) {
+		first := serve(http.MethodPost, "replay", `{"email":"email@gmail.com"}`)
+		require.Equal(t, http.StatusCreated, first.Code)
+		require.Empty(t, first.Header().Get(constants.HeaderIdempotentReplayed))
+
+		replayed := serve(http.MethodPost, "replay", `{"email":"email@gmail.com"}`)
+		require.Equal(t, http.StatusCreated, replayed.Code)
+		require.Equal(t, first.Body.String(), replayed.Body.String())
+		require.Equal(t, `"1"`, replayed.Header().Get(constants.HeaderETag))
+		require.NotEmpty(t, first.Header().Get(echo.HeaderSetCookie))
+		require.Empty(t, replayed.Header().Get(echo.HeaderSetCookie))
+		require.Equal(t, "true", replayed.Header().Get(constants.HeaderIdempotentReplayed))
+	})
+
+	t.Run("Anonymous keys are scoped by client IP", func(t *testing.T) {
+		body := `{"email":"email@gmail.com"}`
+		require.Empty(t, serveFrom("", "192.0.2.1:1234", http.MethodPost, "anonymous", body).Header().Get(constants.HeaderIdempotentReplayed))
+		require.Empty(t, serveFrom("", "198.51.100.1:1234", http.MethodPost, "anonymous", body).Header().Get(constants.HeaderIdempotentReplayed))
+		require.Equal(t, "true", serveFrom("", "192.0.2.1:1234", http.MethodPost, "anonymous", body).Header().Get(constants.HeaderIdempotentReplayed))
+	})
+
+	t.Run("Reused with a different payload", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, serve(http.MethodPost, "reused", `{"email":"email@gmail.com"}`).Code)
+
+		res := serve(http.MethodPost, "reused", `{"email":"other@gmail.com"}`)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("In flight", func(t *testing.T) {
+		body := `{"email":"email@gmail.com"}`
+		_, reserved, err := idempotencyRepo.Reserve(context.Background(), idempotencyHash("bearer token", "in-flight"), idempotencyHash(http.MethodPost, "/user", body), time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		res := serve(http.MethodPost, "in-flight", body)
+		require.Equal(t, http.StatusConflict, res.Code)
+	})
+
+	t.Run("Failed request is retried", func(t *testing.T) {
+		failures = 1
+		require.Equal(t, http.StatusInternalServerError, serve(http.MethodPost, "retried", `{}`).Code)
+
+		before := calls
+		res := serve(http.MethodPost, "retried", `{}`)
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.Equal(t, before+1, calls)
+	})
+
+	t.Run("Without key", func(t *testing.T) {
+		before := calls
+		serve(http.MethodPost, "", `{}`)
+		serve(http.MethodPost, "", `{}`)
+		require.Equal(t, before+2, calls)
+	})
+}
@@ -1,7 +1,6 @@
 package middlewares
 
 import (
-	"errors"
 	"strings"
 	"time"
 
@@ -10,7 +9,14 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 
 	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/accesstoken"
+	"github.com/dinorain/useraja/internal/idempotency"
 	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/ratelimit"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	"github.com/dinorain/useraja/internal/session"
+	"github.com/dinorain/useraja/internal/user"
+	"github.com/dinorain/useraja/pkg/domain_errors"
 	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
 	"github.com/dinorain/useraja/pkg/logger"
 )
@@ -19,51 +25,152 @@ type MiddlewareManager interface {
 	RequestLoggerMiddleware(next echo.HandlerFunc) echo.HandlerFunc
 	IsLoggedIn() echo.MiddlewareFunc
 	IsAdmin(next echo.HandlerFunc) echo.HandlerFunc
+	ErrorFormatMiddleware(next echo.HandlerFunc) echo.HandlerFunc
+	Idempotency(next echo.HandlerFunc) echo.HandlerFunc
+	RateLimit(name string, limit int, window time.Duration) echo.MiddlewareFunc
+	RequireStepUp(maxAge time.Duration) echo.MiddlewareFunc
+	RequireRecentAuth(maxAge time.Duration, methods ...string) echo.MiddlewareFunc
+	RequireScope(scope string) echo.MiddlewareFunc
+	IsAdminOrScope(scope string) echo.MiddlewareFunc
+	AllowOAuthClient(next echo.HandlerFunc) echo.HandlerFunc
 }
 
 type middlewareManager struct {
-	logger logger.Logger
-	cfg    *config.Config
+	logger          logger.Logger
+	cfg             *config.Config
+	idempotencyRepo idempotency.IdempotencyRepository
+	rateLimitRepo   ratelimit.RateLimitRepository
+	sessUC          session.SessUseCase
+	apiKeyUC        serviceaccount.ServiceAccountUseCase
+	userUC          user.UserUseCase
 }
 
 var _ MiddlewareManager = (*middlewareManager)(nil)
 
-func NewMiddlewareManager(logger logger.Logger, cfg *config.Config) *middlewareManager {
-	return &middlewareManager{logger: logger, cfg: cfg}
+func NewMiddlewareManager(
+	logger logger.Logger,
+	cfg *config.Config,
+	idempotencyRepo idempotency.IdempotencyRepository,
+	rateLimitRepo ratelimit.RateLimitRepository,
+	sessUC session.SessUseCase,
+	apiKeyUC serviceaccount.ServiceAccountUseCase,
+	userUC user.UserUseCase,
+) *middlewareManager {
+	return &middlewareManager{
+		logger:          logger,
+		cfg:             cfg,
+		idempotencyRepo: idempotencyRepo,
+		rateLimitRepo:   rateLimitRepo,
+		sessUC:          sessUC,
+		apiKeyUC:        apiKeyUC,
+		userUC:          userUC,
+	}
 }
 
+// IsLoggedIn authenticate the request with a jwt access token whose session still exists, a service account key sent as
+// "Authorization: ApiKey <key>" or a personal access token sent as "Authorization: Bearer ujp_...". Routes keys, personal
+// access tokens and OAuth client tokens may call must check their scope with RequireScope or IsAdminOrScope
 func (mw *middlewareManager) IsLoggedIn() echo.MiddlewareFunc {
-	return middleware.JWTWithConfig(middleware.JWTConfig{
+	jwtMiddleware := middleware.JWTWithConfig(middleware.JWTConfig{
 		SigningKey: []byte(mw.cfg.Server.JwtSecretKey),
 	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		jwtNext := jwtMiddleware(mw.requireSession(next))
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+
+			if key, ok := serviceaccount.ParseAuthorization(authorization); ok {
+				apiKey, err := mw.apiKeyUC.Authenticate(ctx, key)
+				if err != nil {
+					mw.logger.Warnf("apiKeyUC.Authenticate: %v", err)
+					return httpErrors.ErrorCtxResponse(c, err, mw.cfg.Http.DebugErrorsResponse)
+				}
+
+				c.SetRequest(c.Request().WithContext(serviceaccount.NewContext(ctx, apiKey)))
+				return next(c)
+			}
+
+			if token, ok := accesstoken.ParseAuthorization(authorization); ok {
+				accessToken, tokenUser, err := mw.userUC.AuthenticateAccessToken(ctx, token)
+				if err != nil {
+					mw.logger.Warnf("userUC.AuthenticateAccessToken: %v", err)
+					return httpErrors.ErrorCtxResponse(c, err, mw.cfg.Http.DebugErrorsResponse)
+				}
+
+				c.SetRequest(c.Request().WithContext(accesstoken.NewContext(ctx, accessToken, tokenUser)))
+				return next(c)
+			}
+
+			return jwtNext(c)
+		}
+	}
+}
+
+// requireSession reject jwt access tokens whose session ended. OAuth client tokens are only granted the scopes of their
+// session the user still holds, and stop working once the user is no longer active
+func (mw *middlewareManager) requireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		session, err := mw.tokenSession(c)
+		if err != nil {
+			return httpErrors.ErrorCtxResponse(c, err, mw.cfg.Http.DebugErrorsResponse)
+		}
+
+		if session.ClientID != "" {
+			sessionUser, err := mw.userUC.CachedFindById(c.Request().Context(), session.UserID)
+			if err != nil {
+				mw.logger.Errorf("userUC.CachedFindById: %v", err)
+				return httpErrors.ErrorCtxResponse(c, err, mw.cfg.Http.DebugErrorsResponse)
+			}
+			if !sessionUser.IsActive() {
+				return httpErrors.ErrorCtxResponse(c, domain_errors.ErrUserInactive, mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			c.Set(clientScopesKey, clientScopes(models.ScopesForRole(sessionUser.Role, session.Scopes)))
+		}
+
+		return next(c)
+	}
 }
 
 func (mw *middlewareManager) IsAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		if _, ok := scopedCredential(c); ok {
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrForbidden, mw.cfg.Http.DebugErrorsResponse)
+		}
+
 		user, ok := c.Get("user").(*jwt.Token)
 		if !ok {
 			mw.logger.Warnf("jwt.Token: %+v", c.Get("user"))
-			return errors.New("invalid token header")
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, mw.cfg.Http.DebugErrorsResponse)
 		}
 		claims := user.Claims.(jwt.MapClaims)
 		if !ok {
 			mw.logger.Warnf("jwt.MapClaims: %+v", c.Get("user"))
-			return errors.New("invalid token header")
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, mw.cfg.Http.DebugErrorsResponse)
 		}
 		role, ok := claims["role"].(string)
 		if !ok {
 			mw.logger.Warnf("role: %v", claims)
-			return errors.New("invalid token header")
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, mw.cfg.Http.DebugErrorsResponse)
 		}
 
 		if role != models.UserRoleAdmin {
-			return httpErrors.NewForbiddenError(c, nil, mw.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrForbidden, mw.cfg.Http.DebugErrorsResponse)
 		}
 
 		return next(c)
 	}
 }
 
+// ErrorFormatMiddleware select the error response format from config, problem+json or the legacy rest error
+func (mw *middlewareManager) ErrorFormatMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Set(httpErrors.ErrorFormatKey, mw.cfg.Http.ErrorFormat)
+		return next(c)
+	}
+}
+
 func (mw *middlewareManager) RequestLoggerMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(ctx echo.Context) error {
 
@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
+)
+
+// IPExtractor client ip of requests for echo.Echo.IPExtractor. X-Forwarded-For is only read when the request comes from
+// one of the trusted proxy CIDR ranges, otherwise a client could pick its own ip and defeat the rate limits
+func IPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trustedProxies {
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// RateLimit allow limit requests per client ip in every window of the named limit, a zero limit disables it.
+// Requests are let through when the limit can not be checked
+func (mw *middlewareManager) RateLimit(name string, limit int, window time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if mw.rateLimitRepo == nil || limit <= 0 {
+				return next(c)
+			}
+
+			allowed, retryAfter, err := mw.rateLimitRepo.Allow(c.Request().Context(), name+":"+c.RealIP(), limit, window)
+			if err != nil {
+				mw.logger.Errorf("rateLimitRepo.Allow: %v", err)
+				return next(c)
+			}
+
+			if !allowed {
+				mw.logger.Warnf("RateLimit %s: %v", name, c.RealIP())
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+				return httpErrors.ErrorCtxResponse(c, domain_errors.RateLimited(retryAfter), mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			return next(c)
+		}
+	}
+}
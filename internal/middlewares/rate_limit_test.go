@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	rateLimitRepository "github.com/dinorain/useraja/internal/ratelimit/repository"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestMiddlewareManager_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	rateLimitRepo := rateLimitRepository.NewRateLimitRepository(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	mw := NewMiddlewareManager(logger.NewAppLogger(nil), &config.Config{}, nil, rateLimitRepo, nil, nil, nil)
+
+	newEcho := func(trustedProxies []string) *echo.Echo {
+		ipExtractor, err := IPExtractor(trustedProxies)
+		require.NoError(t, err)
+
+		e := echo.New()
+		e.IPExtractor = ipExtractor
+		e.POST("/user/signup", func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		}, mw.RateLimit("signup", 1, time.Minute))
+		return e
+	}
+	serve := func(e *echo.Echo, remoteAddr string, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/user/signup", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		return res.Code
+	}
+
+	t.Run("Spoofed header", func(t *testing.T) {
+		e := newEcho(nil)
+
+		require.Equal(t, http.StatusNoContent, serve(e, "192.0.2.1:1234", "198.51.100.1"))
+		require.Equal(t, http.StatusTooManyRequests, serve(e, "192.0.2.1:1234", "198.51.100.2"))
+	})
+
+	t.Run("Trusted proxy", func(t *testing.T) {
+		e := newEcho([]string{"203.0.113.0/24"})
+
+		require.Equal(t, http.StatusNoContent, serve(e, "203.0.113.10:1234", "198.51.100.3"))
+		require.Equal(t, http.StatusNoContent, serve(e, "203.0.113.10:1234", "198.51.100.4"))
+		require.Equal(t, http.StatusTooManyRequests, serve(e, "203.0.113.11:1234", "198.51.100.4"))
+	})
+
+	t.Run("Invalid proxy range", func(t *testing.T) {
+		_, err := IPExtractor([]string{"10.0.0.1"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
+)
+
+// RequireRecentAuth allow the request only when the user authenticated within maxAge, using one of methods when any are given.
+// Must follow IsLoggedIn
+func (mw *middlewareManager) RequireRecentAuth(maxAge time.Duration, methods ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if OAuthClientDenied(c) {
+				return httpErrors.ErrorCtxResponse(c, domain_errors.ErrForbidden, mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			session, err := mw.tokenSession(c)
+			if err != nil {
+				return httpErrors.ErrorCtxResponse(c, err, mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			if !session.AuthenticatedWithin(maxAge, methods...) {
+				return httpErrors.ErrorCtxResponse(c, domain_errors.ReauthRequired(maxAge, methods...), mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// sessionKey echo context key of the session IsLoggedIn loaded for the jwt access token
+const sessionKey = "session"
+
+// tokenSession session of the jwt access token of the request
+func (mw *middlewareManager) tokenSession(c echo.Context) (*models.Session, error) {
+	if session, ok := c.Get(sessionKey).(*models.Session); ok {
+		return session, nil
+	}
+
+	user, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		mw.logger.Warnf("jwt.Token: %+v", c.Get("user"))
+		return nil, domain_errors.ErrInvalidToken
+	}
+	claims, ok := user.Claims.(jwt.MapClaims)
+	if !ok {
+		mw.logger.Warnf("jwt.MapClaims: %+v", c.Get("user"))
+		return nil, domain_errors.ErrInvalidToken
+	}
+	sessionID, ok := claims["session_id"].(string)
+	if !ok {
+		mw.logger.Warnf("session_id: %v", claims)
+		return nil, domain_errors.ErrInvalidToken
+	}
+
+	session, err := mw.sessUC.GetSessionById(c.Request().Context(), sessionID)
+	if err != nil {
+		mw.logger.Errorf("sessUC.GetSessionById: %v", err)
+		return nil, err
+	}
+
+	c.Set(sessionKey, session)
+	return session, nil
+}
@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/models"
+	mockSessUC "github.com/dinorain/useraja/internal/session/mock"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestMiddlewareManager_RequireRecentAuth(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	cfg := &config.Config{}
+	mw := NewMiddlewareManager(logger.NewAppLogger(nil), cfg, nil, nil, sessUC, nil, nil)
+
+	e := echo.New()
+	handler := mw.RequireRecentAuth(5*time.Minute, models.AuthMethodPassword)(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	serve := func(sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/user/me/deactivate", nil)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"session_id": sessionID}})
+		require.NoError(t, handler(ctx))
+		return res
+	}
+
+	t.Run("Recent password login", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "recent").Return(&models.Session{
+			AuthTime: time.Now().Add(-time.Minute),
+			AMR:      []string{models.AuthMethodPassword},
+		}, nil)
+
+		require.Equal(t, http.StatusNoContent, serve("recent").Code)
+	})
+
+	t.Run("Old login", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "old").Return(&models.Session{
+			AuthTime: time.Now().Add(-time.Hour),
+			AMR:      []string{models.AuthMethodPassword},
+		}, nil)
+
+		res := serve("old")
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+		require.Equal(t, `Bearer error="insufficient_user_authentication", max_age=300`, res.Header().Get(echo.HeaderWWWAuthenticate))
+	})
+
+	t.Run("Other method", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "link").Return(&models.Session{
+			AuthTime: time.Now(),
+			AMR:      []string{models.AuthMethodMagicLink},
+		}, nil)
+
+		require.Equal(t, http.StatusUnauthorized, serve("link").Code)
+	})
+
+	t.Run("Session without auth time", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "legacy").Return(&models.Session{}, nil)
+
+		require.Equal(t, http.StatusUnauthorized, serve("legacy").Code)
+	})
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
+)
+
+// RequireStepUp allow the request only when the session verified a step-up code within maxAge, must follow IsLoggedIn
+func (mw *middlewareManager) RequireStepUp(maxAge time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if OAuthClientDenied(c) {
+				return httpErrors.ErrorCtxResponse(c, domain_errors.ErrForbidden, mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			session, err := mw.tokenSession(c)
+			if err != nil {
+				return httpErrors.ErrorCtxResponse(c, err, mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			if !session.SteppedUp(maxAge) {
+				return httpErrors.ErrorCtxResponse(c, domain_errors.ErrStepUpRequired, mw.cfg.Http.DebugErrorsResponse)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/models"
+	mockSessUC "github.com/dinorain/useraja/internal/session/mock"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestMiddlewareManager_RequireStepUp(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	cfg := &config.Config{}
+	mw := NewMiddlewareManager(logger.NewAppLogger(nil), cfg, nil, nil, sessUC, nil, nil)
+
+	e := echo.New()
+	handler := mw.RequireStepUp(10 * time.Minute)(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	serve := func(sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/user/id", nil)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"session_id": sessionID}})
+		require.NoError(t, handler(ctx))
+		return res
+	}
+
+	t.Run("Stepped up", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "recent").Return(&models.Session{AuthTime: time.Now(), ACR: models.SessionACRStepUp}, nil)
+
+		require.Equal(t, http.StatusNoContent, serve("recent").Code)
+	})
+
+	t.Run("Step-up expired", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "old").Return(&models.Session{AuthTime: time.Now().Add(-time.Hour), ACR: models.SessionACRStepUp}, nil)
+
+		require.Equal(t, http.StatusForbidden, serve("old").Code)
+	})
+
+	t.Run("Basic session", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "basic").Return(&models.Session{AuthTime: time.Now(), ACR: models.SessionACRBasic}, nil)
+
+		require.Equal(t, http.StatusForbidden, serve("basic").Code)
+	})
+}
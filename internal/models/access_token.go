@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, sent as "Authorization: Bearer ujp_..."
+const PersonalAccessTokenPrefix = "ujp_"
+
+// PersonalAccessTokenNameMaxLength longest token name
+const PersonalAccessTokenNameMaxLength = 64
+
+// PersonalAccessToken token a user scripts against the API with, only the hash of the token is stored
+type PersonalAccessToken struct {
+	TokenID    uuid.UUID      `json:"token_id" db:"token_id"`
+	UserID     uuid.UUID      `json:"user_id" db:"user_id"`
+	Name       string         `json:"name" db:"name"`
+	Prefix     string         `json:"prefix" db:"prefix"`
+	TokenHash  string         `json:"-" db:"token_hash"`
+	Scopes     pq.StringArray `json:"scopes" db:"scopes"`
+	ExpiresAt  time.Time      `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+// HasScope reports whether the token was granted scope
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, granted := range t.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleAllowsScope reports whether users of role may use scope, personal access tokens never exceed their user
+func RoleAllowsScope(role string, scope string) bool {
+	switch scope {
+	case APIKeyScopeUsersRead:
+		return true
+	case APIKeyScopeUsersWrite:
+		return role == UserRoleAdmin
+	}
+	return false
+}
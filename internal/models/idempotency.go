@@ -0,0 +1,10 @@
+package models
+
+// IdempotencyRecord fingerprint of the first request sent with an idempotency key and, once it completed, its response
+type IdempotencyRecord struct {
+	Fingerprint string            `json:"fingerprint"`
+	Completed   bool              `json:"completed"`
+	Status      int               `json:"status,omitempty"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
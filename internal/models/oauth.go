@@ -0,0 +1,96 @@
+package models
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// OAuth client types, confidential clients authenticate to the token endpoint with their secret, public clients such
+// as mobile and single page apps can not keep one and rely on PKCE alone
+const (
+	OAuthClientTypePublic       = "public"
+	OAuthClientTypeConfidential = "confidential"
+)
+
+// OAuthClientSecretPrefix starts every client secret so leaked secrets are easy to recognize
+const OAuthClientSecretPrefix = "ujc_"
+
+// OAuth grant types accepted by the token endpoint
+const (
+	OAuthGrantTypeAuthorizationCode = "authorization_code"
+	OAuthGrantTypeRefreshToken      = "refresh_token"
+)
+
+// OAuthCodeChallengeMethodS256 only PKCE method accepted, plain challenges would leak the verifier with the code
+const OAuthCodeChallengeMethodS256 = "S256"
+
+// OAuthClient application registered to sign users in through the authorization code flow, only the hash of the
+// secret of confidential clients is stored
+type OAuthClient struct {
+	ClientID     uuid.UUID      `json:"client_id" db:"client_id"`
+	Name         string         `json:"name" db:"name"`
+	ClientType   string         `json:"client_type" db:"client_type"`
+	SecretHash   string         `json:"-" db:"secret_hash"`
+	RedirectURIs pq.StringArray `json:"redirect_uris" db:"redirect_uris"`
+	Scopes       pq.StringArray `json:"scopes" db:"scopes"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Confidential reports whether the client must authenticate with its secret
+func (c *OAuthClient) Confidential() bool {
+	return c.ClientType == OAuthClientTypeConfidential
+}
+
+// AllowsRedirectURI reports whether redirectURI exactly matches one of the registered redirect uris
+func (c *OAuthClient) AllowsRedirectURI(redirectURI string) bool {
+	for _, registered := range c.RedirectURIs {
+		if registered == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsScope reports whether the client may request scope
+func (c *OAuthClient) AllowsScope(scope string) bool {
+	for _, allowed := range c.Scopes {
+		if allowed == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizationCode grant of the authorization code flow, kept until the client exchanges the code for tokens
+type AuthorizationCode struct {
+	ClientID      uuid.UUID `json:"client_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	SessionID     string    `json:"session_id"`
+	RedirectURI   string    `json:"redirect_uri"`
+	Scopes        []string  `json:"scopes"`
+	CodeChallenge string    `json:"code_challenge"`
+}
+
+// VerifyCodeVerifier reports whether the S256 challenge of verifier matches the challenge the code was issued for
+func (a *AuthorizationCode) VerifyCodeVerifier(verifier string) bool {
+	hash := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(a.CodeChallenge)) == 1
+}
+
+// ScopesForRole scopes users of role may use, tokens issued to clients never exceed their user
+func ScopesForRole(role string, scopes []string) []string {
+	allowed := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if RoleAllowsScope(role, scope) {
+			allowed = append(allowed, scope)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,12 @@
+package models
+
+// One time code purposes, a code only verifies the purpose it was issued for
+const (
+	OTPPurposeLogin       = "login"
+	OTPPurposeStepUp      = "step_up"
+	OTPPurposeSMSLogin    = "sms_login"
+	OTPPurposePhoneVerify = "phone_verify"
+)
+
+// OTPDigits length of one time codes
+const OTPDigits = 6
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// API key scopes, a key may only call the endpoints of its scopes
+const (
+	APIKeyScopeUsersRead  = "users:read"
+	APIKeyScopeUsersWrite = "users:write"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognize
+const APIKeyPrefix = "uja_"
+
+// APIKeyDisplayLength leading characters of a key kept to tell keys apart
+const APIKeyDisplayLength = 12
+
+// ServiceAccount non human caller authenticating with API keys
+type ServiceAccount struct {
+	ServiceAccountID uuid.UUID `json:"service_account_id" db:"service_account_id"`
+	Name             string    `json:"name" db:"name"`
+	Description      string    `json:"description" db:"description"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// APIKey key of a service account, only the hash of the key is stored
+type APIKey struct {
+	KeyID            uuid.UUID      `json:"key_id" db:"key_id"`
+	ServiceAccountID uuid.UUID      `json:"service_account_id" db:"service_account_id"`
+	Prefix           string         `json:"prefix" db:"prefix"`
+	KeyHash          string         `json:"-" db:"key_hash"`
+	Scopes           pq.StringArray `json:"scopes" db:"scopes"`
+	ExpiresAt        *time.Time     `json:"expires_at" db:"expires_at"`
+	LastUsedAt       *time.Time     `json:"last_used_at" db:"last_used_at"`
+	RevokedAt        *time.Time     `json:"revoked_at" db:"revoked_at"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+}
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the key is neither revoked nor expired
+func (k *APIKey) Active() bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(time.Now()))
+}
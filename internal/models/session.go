@@ -1,9 +1,70 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
 
-// Session model
+	"github.com/google/uuid"
+)
+
+// Session authentication context classes, a step-up session verified a second factor after logging in
+const (
+	SessionACRBasic  = "basic"
+	SessionACRStepUp = "step-up"
+)
+
+// Authentication methods recorded in the session amr, see RFC 8176
+const (
+	AuthMethodPassword      = "pwd"
+	AuthMethodOTP           = "otp"
+	AuthMethodMagicLink     = "email"
+	AuthMethodSMS           = "sms"
+	AuthMethodTrustedDevice = "device"
+)
+
+// Lifetimes of the jwt token pair issued for a session
+const (
+	AccessTokenExpire  = time.Minute * 15
+	RefreshTokenExpire = time.Hour * 24
+)
+
+// Session model, ClientID and Scopes are set on sessions of tokens issued to an OAuth client
 type Session struct {
 	SessionID string    `json:"session_id"`
 	UserID    uuid.UUID `json:"user_id"`
+	AuthTime  time.Time `json:"auth_time,omitempty"`
+	ACR       string    `json:"acr,omitempty"`
+	AMR       []string  `json:"amr,omitempty"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+}
+
+// SteppedUp reports whether the session verified a second factor within maxAge
+func (s *Session) SteppedUp(maxAge time.Duration) bool {
+	return s.ACR == SessionACRStepUp && time.Since(s.AuthTime) <= maxAge
+}
+
+// AuthenticatedWithin reports whether the user authenticated within maxAge, using one of methods when any are given
+func (s *Session) AuthenticatedWithin(maxAge time.Duration, methods ...string) bool {
+	if s.AuthTime.IsZero() || time.Since(s.AuthTime) > maxAge {
+		return false
+	}
+	if len(methods) == 0 {
+		return true
+	}
+	for _, method := range methods {
+		if s.HasMethod(method) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMethod reports whether method is one of the session authentication methods
+func (s *Session) HasMethod(method string) bool {
+	for _, amr := range s.AMR {
+		if amr == method {
+			return true
+		}
+	}
+	return false
 }
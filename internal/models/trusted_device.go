@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrustedDeviceNameMaxLength longest stored device name
+const TrustedDeviceNameMaxLength = 128
+
+// TrustedDevice browser remembered after a second factor, only the hash of its token is stored
+type TrustedDevice struct {
+	DeviceID    uuid.UUID `json:"device_id" db:"device_id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	TokenHash   string    `json:"-" db:"token_hash"`
+	Name        string    `json:"name" db:"name"`
+	IP          string    `json:"ip" db:"ip"`
+	FirstSeenAt time.Time `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at" db:"last_seen_at"`
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -14,6 +14,47 @@ const (
 	UserRoleUser  = "user"
 )
 
+const (
+	UserStatusPending     = "pending"
+	UserStatusActive      = "active"
+	UserStatusSuspended   = "suspended"
+	UserStatusLocked      = "locked"
+	UserStatusDeactivated = "deactivated"
+)
+
+// UserPasswordUnset stored password of users who did not choose one yet, no password matches it
+const UserPasswordUnset = "!"
+
+// userStatusTransitions allowed status changes, anything else is rejected
+var userStatusTransitions = map[string][]string{
+	UserStatusPending:     {UserStatusActive, UserStatusSuspended, UserStatusDeactivated},
+	UserStatusActive:      {UserStatusSuspended, UserStatusLocked, UserStatusDeactivated},
+	UserStatusSuspended:   {UserStatusActive, UserStatusDeactivated},
+	UserStatusLocked:      {UserStatusActive, UserStatusSuspended, UserStatusDeactivated},
+	UserStatusDeactivated: {UserStatusActive},
+}
+
+// CanTransitionStatus reports whether a user in status from may be moved to status to
+func CanTransitionStatus(from string, to string) bool {
+	for _, allowed := range userStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UserStatusReasonMaxLength longest accepted status change reason
+const UserStatusReasonMaxLength = 250
+
+// UserStatusChange requested status change, ActorID is nil for system changes
+type UserStatusChange struct {
+	UserID  uuid.UUID
+	Status  string
+	Reason  string
+	ActorID *uuid.UUID
+}
+
 // User model
 type User struct {
 	UserID    uuid.UUID `json:"user_id" db:"user_id" validate:"omitempty"`
@@ -25,6 +66,28 @@ type User struct {
 	Password  string    `json:"-" db:"password"`
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	Status    string    `json:"status" db:"status"`
+	Version   int64     `json:"version" db:"version"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	PhoneNumber     *string    `json:"phone_number" db:"phone_number" validate:"omitempty,e164"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at" db:"phone_verified_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// IsActive reports whether the user may sign in and use its sessions
+func (u *User) IsActive() bool {
+	return u.Status == UserStatusActive
+}
+
+// HasPassword reports whether the user chose a password, invited users have none until they accept
+func (u *User) HasPassword() bool {
+	return u.Password != UserPasswordUnset
+}
+
+// HasVerifiedPhone reports whether the user verified its phone number
+func (u *User) HasVerifiedPhone() bool {
+	return u.PhoneNumber != nil && u.PhoneVerifiedAt != nil
 }
 
 func (u *User) SanitizePassword() {
@@ -69,3 +132,62 @@ func (u *User) GetAvatar() string {
 	}
 	return *u.Avatar
 }
+
+// UserFilter narrows user listings, zero values are ignored.
+// Search matches email, first and last name.
+type UserFilter struct {
+	Role           string
+	Search         string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	Verified       *bool
+	IncludeDeleted bool
+}
+
+// UsersList page of users
+type UsersList struct {
+	TotalCount int
+	TotalPages int
+	Page       int
+	Size       int
+	HasMore    bool
+	NextCursor string
+	PrevCursor string
+	Users      []User
+}
+
+// UserSortFields sortable user listing fields, anything else is rejected
+var UserSortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"email":      true,
+	"first_name": true,
+	"last_name":  true,
+	"role":       true,
+}
+
+// UserSort user listing order
+type UserSort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseUserSort parse a whitelisted sort field, a leading '-' sorts descending, empty sorts by created_at
+func ParseUserSort(orderBy string) (*UserSort, error) {
+	orderBy = strings.TrimSpace(orderBy)
+	if orderBy == "" {
+		return &UserSort{Field: "created_at"}, nil
+	}
+
+	sort := &UserSort{Field: strings.TrimPrefix(orderBy, "-"), Desc: strings.HasPrefix(orderBy, "-")}
+	if !UserSortFields[sort.Field] {
+		return nil, fmt.Errorf("invalid sort field %q", sort.Field)
+	}
+	return sort, nil
+}
+
+// UserCursor position in users ordered by (created_at, user_id)
+type UserCursor struct {
+	CreatedAt time.Time
+	UserID    uuid.UUID
+}
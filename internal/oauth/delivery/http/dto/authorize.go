@@ -0,0 +1,18 @@
+package dto
+
+// AuthorizeRequestDto authorization request of RFC 6749 section 4.1.1 with the PKCE challenge of RFC 7636,
+// scope is space separated
+type AuthorizeRequestDto struct {
+	ResponseType        string `query:"response_type" validate:"required,eq=code"`
+	ClientID            string `query:"client_id" validate:"required,uuid"`
+	RedirectURI         string `query:"redirect_uri" validate:"required"`
+	Scope               string `query:"scope" validate:"lte=256"`
+	State               string `query:"state" validate:"lte=512"`
+	CodeChallenge       string `query:"code_challenge" validate:"required,len=43"`
+	CodeChallengeMethod string `query:"code_challenge_method" validate:"required,eq=S256"`
+}
+
+// AuthorizeResponseDto redirect uri of the client carrying the code and state, the login page sends the browser there
+type AuthorizeResponseDto struct {
+	RedirectURI string `json:"redirect_uri"`
+}
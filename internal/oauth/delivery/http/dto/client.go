@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+type OAuthClientCreateRequestDto struct {
+	Name         string   `json:"name" validate:"required,lte=64"`
+	ClientType   string   `json:"client_type" validate:"required,oneof=public confidential"`
+	RedirectURIs []string `json:"redirect_uris" validate:"required,min=1,dive,url"`
+	Scopes       []string `json:"scopes" validate:"dive,oneof=users:read users:write"`
+}
+
+type OAuthClientResponseDto struct {
+	ClientID     uuid.UUID `json:"client_id"`
+	Name         string    `json:"name"`
+	ClientType   string    `json:"client_type"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// OAuthClientCreateResponseDto the secret of a confidential client is only ever shown in this response
+type OAuthClientCreateResponseDto struct {
+	ClientSecret string                  `json:"client_secret,omitempty"`
+	Client       *OAuthClientResponseDto `json:"client"`
+}
+
+type OAuthClientsResponseDto struct {
+	Clients []*OAuthClientResponseDto `json:"clients"`
+}
+
+func OAuthClientResponseFromModel(client *models.OAuthClient) *OAuthClientResponseDto {
+	return &OAuthClientResponseDto{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		ClientType:   client.ClientType,
+		RedirectURIs: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		CreatedAt:    client.CreatedAt,
+		UpdatedAt:    client.UpdatedAt,
+	}
+}
+
+func OAuthClientsResponseFromModels(clients []models.OAuthClient) *OAuthClientsResponseDto {
+	res := &OAuthClientsResponseDto{Clients: make([]*OAuthClientResponseDto, 0, len(clients))}
+	for i := range clients {
+		res.Clients = append(res.Clients, OAuthClientResponseFromModel(&clients[i]))
+	}
+	return res
+}
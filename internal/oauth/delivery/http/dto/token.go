@@ -0,0 +1,27 @@
+package dto
+
+// TokenRequestDto form encoded token request of RFC 6749 sections 4.1.3 and 6, confidential clients may send their
+// credentials with http basic authentication instead of client_id and client_secret
+type TokenRequestDto struct {
+	GrantType    string `form:"grant_type" validate:"required"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier" validate:"omitempty,min=43,max=128"`
+	RefreshToken string `form:"refresh_token"`
+}
+
+type TokenResponseDto struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// TokenErrorResponseDto error response of RFC 6749 section 5.2
+type TokenErrorResponseDto struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
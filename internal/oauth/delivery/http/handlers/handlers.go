@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-playground/validator"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/middlewares"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/oauth"
+	"github.com/dinorain/useraja/internal/oauth/delivery/http/dto"
+	"github.com/dinorain/useraja/internal/user"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+// OAuth error codes of RFC 6749 section 5.2
+const (
+	tokenErrorInvalidRequest       = "invalid_request"
+	tokenErrorInvalidClient        = "invalid_client"
+	tokenErrorInvalidGrant         = "invalid_grant"
+	tokenErrorInvalidScope         = "invalid_scope"
+	tokenErrorUnsupportedGrantType = "unsupported_grant_type"
+	tokenErrorServerError          = "server_error"
+)
+
+type oauthHandlersHTTP struct {
+	group   *echo.Group
+	logger  logger.Logger
+	cfg     *config.Config
+	mw      middlewares.MiddlewareManager
+	v       *validator.Validate
+	oauthUC oauth.OAuthUseCase
+	userUC  user.UserUseCase
+}
+
+var _ oauth.OAuthHandlers = (*oauthHandlersHTTP)(nil)
+
+func NewOAuthHandlersHTTP(
+	group *echo.Group,
+	logger logger.Logger,
+	cfg *config.Config,
+	mw middlewares.MiddlewareManager,
+	v *validator.Validate,
+	oauthUC oauth.OAuthUseCase,
+	userUC user.UserUseCase,
+) *oauthHandlersHTTP {
+	return &oauthHandlersHTTP{group: group, logger: logger, cfg: cfg, mw: mw, v: v, oauthUC: oauthUC, userUC: userUC}
+}
+
+// CreateClient
+// @Tags OAuth
+// @Summary Register OAuth client
+// @Description Admin register an application signing users in through the authorization code flow.
+// @Description Confidential clients get a secret which is only returned in this response
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body dto.OAuthClientCreateRequestDto true "Payload"
+// @Success 201 {object} dto.OAuthClientCreateResponseDto
+// @Router /oauth/clients [post]
+func (h *oauthHandlersHTTP) CreateClient() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		createDto := &dto.OAuthClientCreateRequestDto{}
+		if err := c.Bind(createDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, createDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		secret, createdClient, err := h.oauthUC.CreateClient(ctx, &models.OAuthClient{
+			Name:         createDto.Name,
+			ClientType:   createDto.ClientType,
+			RedirectURIs: createDto.RedirectURIs,
+			Scopes:       createDto.Scopes,
+		})
+		if err != nil {
+			h.logger.Errorf("oauthUC.CreateClient: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusCreated, dto.OAuthClientCreateResponseDto{ClientSecret: secret, Client: dto.OAuthClientResponseFromModel(createdClient)})
+	}
+}
+
+// FindAllClients
+// @Tags OAuth
+// @Summary List OAuth clients
+// @Description Admin list every registered OAuth client
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.OAuthClientsResponseDto
+// @Router /oauth/clients [get]
+func (h *oauthHandlersHTTP) FindAllClients() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		clients, err := h.oauthUC.FindAllClients(c.Request().Context())
+		if err != nil {
+			h.logger.Errorf("oauthUC.FindAllClients: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.OAuthClientsResponseFromModels(clients))
+	}
+}
+
+// DeleteClient
+// @Tags OAuth
+// @Summary Delete OAuth client
+// @Description Admin unregister an OAuth client and end its sessions, it can no longer get, refresh or use tokens
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param client_id path string true "Client ID"
+// @Success 204
+// @Router /oauth/clients/{client_id} [delete]
+func (h *oauthHandlersHTTP) DeleteClient() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		clientID, err := uuid.Parse(c.Param("client_id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("client_id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.oauthUC.DeleteClient(c.Request().Context(), clientID); err != nil {
+			h.logger.Errorf("oauthUC.DeleteClient: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// Authorize
+// @Tags OAuth
+// @Summary Authorize OAuth client
+// @Description Grant the client an authorization code on behalf of the logged in user. The login page of this service calls it
+// @Description with the access token of the user and sends the browser to the returned redirect uri, which carries the code and state.
+// @Description PKCE is mandatory, code_challenge_method must be S256
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect uri"
+// @Param scope query string false "Space separated scopes, every scope allowed to the client when omitted"
+// @Param state query string false "Opaque value returned to the client"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {object} dto.AuthorizeResponseDto
+// @Router /oauth/authorize [get]
+func (h *oauthHandlersHTTP) Authorize() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		authorizeDto := &dto.AuthorizeRequestDto{}
+		if err := c.Bind(authorizeDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, authorizeDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		sessionID, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		clientID, err := uuid.Parse(authorizeDto.ClientID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("client_id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		redirectURI, err := url.Parse(authorizeDto.RedirectURI)
+		if err != nil {
+			h.logger.WarnMsg("url.Parse", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidRedirectURI.Wrap(err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		code, err := h.oauthUC.Authorize(ctx, sessionID, &models.AuthorizationCode{
+			ClientID:      clientID,
+			RedirectURI:   authorizeDto.RedirectURI,
+			Scopes:        strings.Fields(authorizeDto.Scope),
+			CodeChallenge: authorizeDto.CodeChallenge,
+		})
+		if err != nil {
+			h.logger.Errorf("oauthUC.Authorize: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		query := redirectURI.Query()
+		query.Set("code", code)
+		if authorizeDto.State != "" {
+			query.Set("state", authorizeDto.State)
+		}
+		redirectURI.RawQuery = query.Encode()
+
+		return c.JSON(http.StatusOK, dto.AuthorizeResponseDto{RedirectURI: redirectURI.String()})
+	}
+}
+
+// Token
+// @Tags OAuth
+// @Summary Get OAuth tokens
+// @Description Exchange an authorization code and its PKCE code verifier, or a refresh token, for a token pair of the client session.
+// @Description Confidential clients authenticate with client_secret or http basic authentication. Logging out with the access token,
+// @Description or deleting the client, ends the session and revokes its refresh token. Errors follow RFC 6749 section 5.2
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code or refresh_token"
+// @Param client_id formData string false "Client ID, unless sent with http basic authentication"
+// @Param client_secret formData string false "Secret of confidential clients"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect uri of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Success 200 {object} dto.TokenResponseDto
+// @Failure 400 {object} dto.TokenErrorResponseDto
+// @Failure 401 {object} dto.TokenErrorResponseDto
+// @Router /oauth/token [post]
+func (h *oauthHandlersHTTP) Token() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		tokenDto := &dto.TokenRequestDto{}
+		if err := c.Bind(tokenDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return h.tokenErrorResponse(c, domain_errors.Validation().Wrap(err))
+		}
+
+		if err := h.v.StructCtx(ctx, tokenDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return h.tokenErrorResponse(c, err)
+		}
+
+		clientID, clientSecret := tokenDto.ClientID, tokenDto.ClientSecret
+		if basicID, basicSecret, ok := c.Request().BasicAuth(); ok {
+			clientID, clientSecret = basicID, basicSecret
+		}
+		clientUUID, err := uuid.Parse(clientID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return h.tokenErrorResponse(c, domain_errors.ErrInvalidOAuthClient.Wrap(err))
+		}
+
+		client, err := h.oauthUC.AuthenticateClient(ctx, clientUUID, clientSecret)
+		if err != nil {
+			h.logger.Warnf("oauthUC.AuthenticateClient: %v", err)
+			return h.tokenErrorResponse(c, err)
+		}
+
+		var session *models.Session
+		switch tokenDto.GrantType {
+		case models.OAuthGrantTypeAuthorizationCode:
+			if tokenDto.Code == "" || tokenDto.RedirectURI == "" || tokenDto.CodeVerifier == "" {
+				return h.tokenErrorResponse(c, domain_errors.Validation(
+					domain_errors.FieldViolation{Field: "code", Code: "required", Description: "code, redirect_uri and code_verifier are required"},
+				))
+			}
+			session, err = h.oauthUC.ExchangeCode(ctx, client, tokenDto.Code, tokenDto.RedirectURI, tokenDto.CodeVerifier)
+		case models.OAuthGrantTypeRefreshToken:
+			if tokenDto.RefreshToken == "" {
+				return h.tokenErrorResponse(c, domain_errors.Validation(
+					domain_errors.FieldViolation{Field: "refresh_token", Code: "required", Description: "refresh_token is required"},
+				))
+			}
+			session, err = h.oauthUC.RefreshSession(ctx, client, tokenDto.RefreshToken)
+		default:
+			err = domain_errors.ErrUnsupportedGrantType
+		}
+		if err != nil {
+			h.logger.Warnf("oauth grant %s: %v", tokenDto.GrantType, err)
+			return h.tokenErrorResponse(c, err)
+		}
+
+		sessionUser, err := h.userUC.FindById(ctx, session.UserID)
+		if err != nil {
+			h.logger.Errorf("userUC.FindById: %v", err)
+			return h.tokenErrorResponse(c, err)
+		}
+
+		if !sessionUser.IsActive() {
+			h.logger.Warnf("user.IsActive: %v", sessionUser.Status)
+			return h.tokenErrorResponse(c, domain_errors.ErrUserInactive)
+		}
+
+		accessToken, refreshToken, err := h.userUC.GenerateClientTokenPair(sessionUser, session)
+		if err != nil {
+			h.logger.Errorf("userUC.GenerateClientTokenPair: %v", err)
+			return h.tokenErrorResponse(c, err)
+		}
+
+		c.Response().Header().Set("Cache-Control", "no-store")
+		c.Response().Header().Set("Pragma", "no-cache")
+		return c.JSON(http.StatusOK, dto.TokenResponseDto{
+			AccessToken:  accessToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(models.AccessTokenExpire.Seconds()),
+			RefreshToken: refreshToken,
+			Scope:        strings.Join(models.ScopesForRole(sessionUser.Role, session.Scopes), " "),
+		})
+	}
+}
+
+// tokenErrorResponse respond with the RFC 6749 error of err, OAuth clients do not understand the problem details
+// of the rest of the API
+func (h *oauthHandlersHTTP) tokenErrorResponse(c echo.Context, err error) error {
+	domainErr := domain_errors.From(err)
+
+	status, code := http.StatusBadRequest, tokenErrorInvalidRequest
+	switch {
+	case domainErr.Reason == domain_errors.ReasonInvalidOAuthClient:
+		status, code = http.StatusUnauthorized, tokenErrorInvalidClient
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Basic")
+	case domainErr.Reason == domain_errors.ReasonInvalidGrant, domainErr.Reason == domain_errors.ReasonAccountInactive:
+		code = tokenErrorInvalidGrant
+	case domainErr.Reason == domain_errors.ReasonInvalidOAuthScope:
+		code = tokenErrorInvalidScope
+	case domainErr.Reason == domain_errors.ReasonUnsupportedGrantType:
+		code = tokenErrorUnsupportedGrantType
+	case domainErr.Kind != domain_errors.KindValidation:
+		return c.JSON(http.StatusInternalServerError, dto.TokenErrorResponseDto{Error: tokenErrorServerError})
+	}
+
+	description := domainErr.Message
+	if len(domainErr.Fields) > 0 {
+		description = domainErr.Fields[0].Description
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(status, dto.TokenErrorResponseDto{Error: code, ErrorDescription: description})
+}
+
+// getSessionIDFromCtx session of the jwt access token of the user, keys and tokens of other credentials can not
+// authorize clients
+func (h *oauthHandlersHTTP) getSessionIDFromCtx(c echo.Context) (string, error) {
+	if middlewares.OAuthClientDenied(c) {
+		return "", domain_errors.ErrForbidden
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		h.logger.Warnf("jwt.Token: %+v", c.Get("user"))
+		return "", domain_errors.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		h.logger.Warnf("jwt.MapClaims: %+v", c.Get("user"))
+		return "", domain_errors.ErrInvalidToken
+	}
+
+	sessionID, ok := claims["session_id"].(string)
+	if !ok {
+		h.logger.Warnf("session_id: %+v", claims)
+		return "", domain_errors.ErrInvalidToken
+	}
+
+	return sessionID, nil
+}
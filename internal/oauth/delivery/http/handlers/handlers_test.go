@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator"
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/middlewares"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/oauth/delivery/http/dto"
+	"github.com/dinorain/useraja/internal/oauth/mock"
+	mockUserUC "github.com/dinorain/useraja/internal/user/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestOAuthHandlers_Authorize(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	oauthUC := mock.NewMockOAuthUseCase(ctrl)
+
+	appLogger := logger.NewAppLogger(nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	cfg := &config.Config{}
+	handlers := NewOAuthHandlersHTTP(e.Group("oauth"), appLogger, cfg, mw, validator.New(), oauthUC, nil)
+
+	clientID := uuid.New()
+	serve := func(query url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+query.Encode(), nil)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"session_id": "s"}})
+		require.NoError(t, handlers.Authorize()(ctx))
+		return res
+	}
+	query := func() url.Values {
+		return url.Values{
+			"response_type":         {"code"},
+			"client_id":             {clientID.String()},
+			"redirect_uri":          {"https://app.example.com/callback?tab=1"},
+			"scope":                 {models.APIKeyScopeUsersRead},
+			"state":                 {"xyz"},
+			"code_challenge":        {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+			"code_challenge_method": {models.OAuthCodeChallengeMethodS256},
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		oauthUC.EXPECT().Authorize(gomock.Any(), "s", &models.AuthorizationCode{
+			ClientID:      clientID,
+			RedirectURI:   "https://app.example.com/callback?tab=1",
+			Scopes:        []string{models.APIKeyScopeUsersRead},
+			CodeChallenge: "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		}).Return("the-code", nil)
+
+		res := serve(query())
+		require.Equal(t, http.StatusOK, res.Code)
+
+		resDto := &dto.AuthorizeResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), resDto))
+		redirectURI, err := url.Parse(resDto.RedirectURI)
+		require.NoError(t, err)
+		require.Equal(t, "app.example.com", redirectURI.Host)
+		require.Equal(t, "the-code", redirectURI.Query().Get("code"))
+		require.Equal(t, "xyz", redirectURI.Query().Get("state"))
+		require.Equal(t, "1", redirectURI.Query().Get("tab"))
+	})
+
+	t.Run("PKCE is mandatory", func(t *testing.T) {
+		withoutChallenge := query()
+		withoutChallenge.Del("code_challenge")
+		require.Equal(t, http.StatusBadRequest, serve(withoutChallenge).Code)
+
+		plain := query()
+		plain.Set("code_challenge_method", "plain")
+		require.Equal(t, http.StatusBadRequest, serve(plain).Code)
+	})
+
+	t.Run("Unregistered redirect uri", func(t *testing.T) {
+		oauthUC.EXPECT().Authorize(gomock.Any(), "s", gomock.Any()).Return("", domain_errors.ErrInvalidRedirectURI)
+
+		require.Equal(t, http.StatusBadRequest, serve(query()).Code)
+	})
+}
+
+func TestOAuthHandlers_Token(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	oauthUC := mock.NewMockOAuthUseCase(ctrl)
+	userUC := mockUserUC.NewMockUserUseCase(ctrl)
+
+	appLogger := logger.NewAppLogger(nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	cfg := &config.Config{}
+	handlers := NewOAuthHandlersHTTP(e.Group("oauth"), appLogger, cfg, mw, validator.New(), oauthUC, userUC)
+
+	client := &models.OAuthClient{ClientID: uuid.New(), ClientType: models.OAuthClientTypeConfidential}
+	serve := func(form url.Values, basicAuth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		if basicAuth {
+			req.SetBasicAuth(client.ClientID.String(), "ujc_secret")
+		}
+		res := httptest.NewRecorder()
+		require.NoError(t, handlers.Token()(e.NewContext(req, res)))
+		return res
+	}
+	tokenError := func(res *httptest.ResponseRecorder) string {
+		errDto := &dto.TokenErrorResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), errDto))
+		return errDto.Error
+	}
+
+	verifier := strings.Repeat("v", 43)
+	codeForm := url.Values{
+		"grant_type":    {models.OAuthGrantTypeAuthorizationCode},
+		"code":          {"the-code"},
+		"redirect_uri":  {"https://app.example.com/callback"},
+		"code_verifier": {verifier},
+	}
+
+	t.Run("Authorization code", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), Role: models.UserRoleUser, Status: models.UserStatusActive}
+		session := &models.Session{SessionID: "client-session", UserID: user.UserID, ClientID: client.ClientID.String(), Scopes: []string{models.APIKeyScopeUsersRead, models.APIKeyScopeUsersWrite}}
+		oauthUC.EXPECT().AuthenticateClient(gomock.Any(), client.ClientID, "ujc_secret").Return(client, nil)
+		oauthUC.EXPECT().ExchangeCode(gomock.Any(), client, "the-code", "https://app.example.com/callback", verifier).Return(session, nil)
+		userUC.EXPECT().FindById(gomock.Any(), user.UserID).Return(user, nil)
+		userUC.EXPECT().GenerateClientTokenPair(user, session).Return("at", "rt", nil)
+
+		res := serve(codeForm, true)
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+
+		resDto := &dto.TokenResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), resDto))
+		require.Equal(t, "at", resDto.AccessToken)
+		require.Equal(t, "rt", resDto.RefreshToken)
+		require.Equal(t, "Bearer", resDto.TokenType)
+		require.Equal(t, 900, resDto.ExpiresIn)
+		require.Equal(t, models.APIKeyScopeUsersRead, resDto.Scope)
+	})
+
+	t.Run("Invalid client secret", func(t *testing.T) {
+		oauthUC.EXPECT().AuthenticateClient(gomock.Any(), client.ClientID, "ujc_secret").Return(nil, domain_errors.ErrInvalidOAuthClient)
+
+		res := serve(codeForm, true)
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+		require.Equal(t, tokenErrorInvalidClient, tokenError(res))
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		oauthUC.EXPECT().AuthenticateClient(gomock.Any(), client.ClientID, "ujc_secret").Return(client, nil)
+		oauthUC.EXPECT().ExchangeCode(gomock.Any(), client, "the-code", gomock.Any(), gomock.Any()).Return(nil, domain_errors.ErrInvalidGrant)
+
+		res := serve(codeForm, true)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, tokenErrorInvalidGrant, tokenError(res))
+	})
+
+	t.Run("Missing code verifier", func(t *testing.T) {
+		oauthUC.EXPECT().AuthenticateClient(gomock.Any(), client.ClientID, "ujc_secret").Return(client, nil)
+
+		withoutVerifier := url.Values{"grant_type": {models.OAuthGrantTypeAuthorizationCode}, "code": {"the-code"}, "redirect_uri": {"https://app.example.com/callback"}}
+		res := serve(withoutVerifier, true)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, tokenErrorInvalidRequest, tokenError(res))
+	})
+
+	t.Run("Unsupported grant type", func(t *testing.T) {
+		oauthUC.EXPECT().AuthenticateClient(gomock.Any(), client.ClientID, "").Return(client, nil)
+
+		res := serve(url.Values{"grant_type": {"password"}, "client_id": {client.ClientID.String()}}, false)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, tokenErrorUnsupportedGrantType, tokenError(res))
+	})
+}
@@ -0,0 +1,11 @@
+package handlers
+
+func (h *oauthHandlersHTTP) OAuthMapRoutes() {
+	h.group.GET("/authorize", h.Authorize(), h.mw.IsLoggedIn())
+	h.group.POST("/token", h.Token())
+
+	clients := h.group.Group("/clients", h.mw.IsLoggedIn(), h.mw.IsAdmin)
+	clients.POST("", h.CreateClient())
+	clients.GET("", h.FindAllClients())
+	clients.DELETE("/:client_id", h.DeleteClient())
+}
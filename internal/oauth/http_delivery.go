@@ -0,0 +1,12 @@
+package oauth
+
+import "github.com/labstack/echo/v4"
+
+// OAuth HTTP Handlers interface
+type OAuthHandlers interface {
+	CreateClient() echo.HandlerFunc
+	FindAllClients() echo.HandlerFunc
+	DeleteClient() echo.HandlerFunc
+	Authorize() echo.HandlerFunc
+	Token() echo.HandlerFunc
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pg_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/dinorain/useraja/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockOAuthClientPGRepository is a mock of OAuthClientPGRepository interface.
+type MockOAuthClientPGRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthClientPGRepositoryMockRecorder
+}
+
+// MockOAuthClientPGRepositoryMockRecorder is the mock recorder for MockOAuthClientPGRepository.
+type MockOAuthClientPGRepositoryMockRecorder struct {
+	mock *MockOAuthClientPGRepository
+}
+
+// NewMockOAuthClientPGRepository creates a new mock instance.
+func NewMockOAuthClientPGRepository(ctrl *gomock.Controller) *MockOAuthClientPGRepository {
+	mock := &MockOAuthClientPGRepository{ctrl: ctrl}
+	mock.recorder = &MockOAuthClientPGRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthClientPGRepository) EXPECT() *MockOAuthClientPGRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOAuthClientPGRepository) Create(ctx context.Context, client *models.OAuthClient) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, client)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOAuthClientPGRepositoryMockRecorder) Create(ctx, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOAuthClientPGRepository)(nil).Create), ctx, client)
+}
+
+// DeleteById mocks base method.
+func (m *MockOAuthClientPGRepository) DeleteById(ctx context.Context, clientID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteById", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteById indicates an expected call of DeleteById.
+func (mr *MockOAuthClientPGRepositoryMockRecorder) DeleteById(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockOAuthClientPGRepository)(nil).DeleteById), ctx, clientID)
+}
+
+// FindAll mocks base method.
+func (m *MockOAuthClientPGRepository) FindAll(ctx context.Context) ([]models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx)
+	ret0, _ := ret[0].([]models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockOAuthClientPGRepositoryMockRecorder) FindAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockOAuthClientPGRepository)(nil).FindAll), ctx)
+}
+
+// FindById mocks base method.
+func (m *MockOAuthClientPGRepository) FindById(ctx context.Context, clientID uuid.UUID) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, clientID)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockOAuthClientPGRepositoryMockRecorder) FindById(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockOAuthClientPGRepository)(nil).FindById), ctx, clientID)
+}
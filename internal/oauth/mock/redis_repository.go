@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: redis_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/dinorain/useraja/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAuthorizationCodeRepository is a mock of AuthorizationCodeRepository interface.
+type MockAuthorizationCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizationCodeRepositoryMockRecorder
+}
+
+// MockAuthorizationCodeRepositoryMockRecorder is the mock recorder for MockAuthorizationCodeRepository.
+type MockAuthorizationCodeRepositoryMockRecorder struct {
+	mock *MockAuthorizationCodeRepository
+}
+
+// NewMockAuthorizationCodeRepository creates a new mock instance.
+func NewMockAuthorizationCodeRepository(ctrl *gomock.Controller) *MockAuthorizationCodeRepository {
+	mock := &MockAuthorizationCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockAuthorizationCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizationCodeRepository) EXPECT() *MockAuthorizationCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockAuthorizationCodeRepository) Consume(ctx context.Context, codeHash string) (*models.AuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, codeHash)
+	ret0, _ := ret[0].(*models.AuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockAuthorizationCodeRepositoryMockRecorder) Consume(ctx, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockAuthorizationCodeRepository)(nil).Consume), ctx, codeHash)
+}
+
+// Create mocks base method.
+func (m *MockAuthorizationCodeRepository) Create(ctx context.Context, codeHash string, code *models.AuthorizationCode, expire time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, codeHash, code, expire)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuthorizationCodeRepositoryMockRecorder) Create(ctx, codeHash, code, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuthorizationCodeRepository)(nil).Create), ctx, codeHash, code, expire)
+}
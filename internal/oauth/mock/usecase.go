@@ -0,0 +1,157 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/dinorain/useraja/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockOAuthUseCase is a mock of OAuthUseCase interface.
+type MockOAuthUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthUseCaseMockRecorder
+}
+
+// MockOAuthUseCaseMockRecorder is the mock recorder for MockOAuthUseCase.
+type MockOAuthUseCaseMockRecorder struct {
+	mock *MockOAuthUseCase
+}
+
+// NewMockOAuthUseCase creates a new mock instance.
+func NewMockOAuthUseCase(ctrl *gomock.Controller) *MockOAuthUseCase {
+	mock := &MockOAuthUseCase{ctrl: ctrl}
+	mock.recorder = &MockOAuthUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthUseCase) EXPECT() *MockOAuthUseCaseMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateClient mocks base method.
+func (m *MockOAuthUseCase) AuthenticateClient(ctx context.Context, clientID uuid.UUID, secret string) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateClient", ctx, clientID, secret)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateClient indicates an expected call of AuthenticateClient.
+func (mr *MockOAuthUseCaseMockRecorder) AuthenticateClient(ctx, clientID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateClient", reflect.TypeOf((*MockOAuthUseCase)(nil).AuthenticateClient), ctx, clientID, secret)
+}
+
+// Authorize mocks base method.
+func (m *MockOAuthUseCase) Authorize(ctx context.Context, sessionID string, grant *models.AuthorizationCode) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, sessionID, grant)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockOAuthUseCaseMockRecorder) Authorize(ctx, sessionID, grant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockOAuthUseCase)(nil).Authorize), ctx, sessionID, grant)
+}
+
+// CreateClient mocks base method.
+func (m *MockOAuthUseCase) CreateClient(ctx context.Context, client *models.OAuthClient) (string, *models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClient", ctx, client)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*models.OAuthClient)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateClient indicates an expected call of CreateClient.
+func (mr *MockOAuthUseCaseMockRecorder) CreateClient(ctx, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockOAuthUseCase)(nil).CreateClient), ctx, client)
+}
+
+// DeleteClient mocks base method.
+func (m *MockOAuthUseCase) DeleteClient(ctx context.Context, clientID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteClient", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteClient indicates an expected call of DeleteClient.
+func (mr *MockOAuthUseCaseMockRecorder) DeleteClient(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClient", reflect.TypeOf((*MockOAuthUseCase)(nil).DeleteClient), ctx, clientID)
+}
+
+// ExchangeCode mocks base method.
+func (m *MockOAuthUseCase) ExchangeCode(ctx context.Context, client *models.OAuthClient, code, redirectURI, codeVerifier string) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExchangeCode", ctx, client, code, redirectURI, codeVerifier)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExchangeCode indicates an expected call of ExchangeCode.
+func (mr *MockOAuthUseCaseMockRecorder) ExchangeCode(ctx, client, code, redirectURI, codeVerifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangeCode", reflect.TypeOf((*MockOAuthUseCase)(nil).ExchangeCode), ctx, client, code, redirectURI, codeVerifier)
+}
+
+// FindAllClients mocks base method.
+func (m *MockOAuthUseCase) FindAllClients(ctx context.Context) ([]models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllClients", ctx)
+	ret0, _ := ret[0].([]models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllClients indicates an expected call of FindAllClients.
+func (mr *MockOAuthUseCaseMockRecorder) FindAllClients(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllClients", reflect.TypeOf((*MockOAuthUseCase)(nil).FindAllClients), ctx)
+}
+
+// FindClientById mocks base method.
+func (m *MockOAuthUseCase) FindClientById(ctx context.Context, clientID uuid.UUID) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindClientById", ctx, clientID)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindClientById indicates an expected call of FindClientById.
+func (mr *MockOAuthUseCaseMockRecorder) FindClientById(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindClientById", reflect.TypeOf((*MockOAuthUseCase)(nil).FindClientById), ctx, clientID)
+}
+
+// RefreshSession mocks base method.
+func (m *MockOAuthUseCase) RefreshSession(ctx context.Context, client *models.OAuthClient, refreshToken string) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSession", ctx, client, refreshToken)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshSession indicates an expected call of RefreshSession.
+func (mr *MockOAuthUseCaseMockRecorder) RefreshSession(ctx, client, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockOAuthUseCase)(nil).RefreshSession), ctx, client, refreshToken)
+}
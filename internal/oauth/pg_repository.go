@@ -0,0 +1,18 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository.go -package mock
+package oauth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// OAuth client Postgresql repository
+type OAuthClientPGRepository interface {
+	Create(ctx context.Context, client *models.OAuthClient) (*models.OAuthClient, error)
+	FindAll(ctx context.Context) ([]models.OAuthClient, error)
+	FindById(ctx context.Context, clientID uuid.UUID) (*models.OAuthClient, error)
+	DeleteById(ctx context.Context, clientID uuid.UUID) error
+}
@@ -0,0 +1,15 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository.go -package mock
+package oauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// Authorization code Redis repository
+type AuthorizationCodeRepository interface {
+	Create(ctx context.Context, codeHash string, code *models.AuthorizationCode, expire time.Duration) error
+	Consume(ctx context.Context, codeHash string) (*models.AuthorizationCode, error)
+}
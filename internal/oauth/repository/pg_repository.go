@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/oauth"
+)
+
+// OAuth client repository
+type OAuthClientRepository struct {
+	db *sqlx.DB
+}
+
+var _ oauth.OAuthClientPGRepository = (*OAuthClientRepository)(nil)
+
+// OAuth client repository constructor
+func NewOAuthClientPGRepository(db *sqlx.DB) *OAuthClientRepository {
+	return &OAuthClientRepository{db: db}
+}
+
+// Create new OAuth client
+func (r *OAuthClientRepository) Create(ctx context.Context, client *models.OAuthClient) (*models.OAuthClient, error) {
+	createdClient := &models.OAuthClient{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createClientQuery,
+		client.Name,
+		client.ClientType,
+		client.SecretHash,
+		client.RedirectURIs,
+		client.Scopes,
+	).StructScan(createdClient); err != nil {
+		return nil, errors.Wrap(err, "OAuthClientRepository.Create.QueryRowxContext")
+	}
+
+	return createdClient, nil
+}
+
+// FindAll Find every OAuth client by name
+func (r *OAuthClientRepository) FindAll(ctx context.Context) ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	if err := r.db.SelectContext(ctx, &clients, findAllClientsQuery); err != nil {
+		return nil, errors.Wrap(err, "OAuthClientRepository.FindAll.SelectContext")
+	}
+
+	return clients, nil
+}
+
+// FindById Find OAuth client by id
+func (r *OAuthClientRepository) FindById(ctx context.Context, clientID uuid.UUID) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{}
+	if err := r.db.GetContext(ctx, client, findClientByIdQuery, clientID); err != nil {
+		return nil, errors.Wrap(err, "OAuthClientRepository.FindById.GetContext")
+	}
+
+	return client, nil
+}
+
+// DeleteById Delete OAuth client by id, sql.ErrNoRows when there is none
+func (r *OAuthClientRepository) DeleteById(ctx context.Context, clientID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, deleteClientByIdQuery, clientID)
+	if err != nil {
+		return errors.Wrap(err, "OAuthClientRepository.DeleteById.ExecContext")
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "OAuthClientRepository.DeleteById.RowsAffected")
+	} else if cnt == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+var clientColumns = []string{"client_id", "name", "client_type", "secret_hash", "redirect_uris", "scopes", "created_at", "updated_at"}
+
+func TestOAuthClientRepository_Create(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	oauthClientPGRepository := NewOAuthClientPGRepository(sqlxDB)
+
+	mockClient := &models.OAuthClient{
+		Name:         "mobile",
+		ClientType:   models.OAuthClientTypePublic,
+		RedirectURIs: pq.StringArray{"com.example.app:/callback"},
+		Scopes:       pq.StringArray{models.APIKeyScopeUsersRead},
+	}
+
+	clientID := uuid.New()
+	mock.ExpectQuery(createClientQuery).
+		WithArgs(mockClient.Name, mockClient.ClientType, mockClient.SecretHash, mockClient.RedirectURIs, mockClient.Scopes).
+		WillReturnRows(sqlmock.NewRows(clientColumns).
+			AddRow(clientID, "mobile", models.OAuthClientTypePublic, "", "{com.example.app:/callback}", "{users:read}", time.Now(), time.Now()))
+
+	createdClient, err := oauthClientPGRepository.Create(context.Background(), mockClient)
+	require.NoError(t, err)
+	require.Equal(t, clientID, createdClient.ClientID)
+	require.Equal(t, pq.StringArray{"com.example.app:/callback"}, createdClient.RedirectURIs)
+	require.Equal(t, pq.StringArray{models.APIKeyScopeUsersRead}, createdClient.Scopes)
+}
+
+func TestOAuthClientRepository_DeleteById(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	oauthClientPGRepository := NewOAuthClientPGRepository(sqlxDB)
+
+	clientID := uuid.New()
+
+	t.Run("Deleted", func(t *testing.T) {
+		mock.ExpectExec(deleteClientByIdQuery).WithArgs(clientID).WillReturnResult(sqlmock.NewResult(0, 1))
+		require.NoError(t, oauthClientPGRepository.DeleteById(context.Background(), clientID))
+	})
+
+	t.Run("Unknown client", func(t *testing.T) {
+		mock.ExpectExec(deleteClientByIdQuery).WithArgs(clientID).WillReturnResult(sqlmock.NewResult(0, 0))
+		require.ErrorIs(t, oauthClientPGRepository.DeleteById(context.Background(), clientID), sql.ErrNoRows)
+	})
+}
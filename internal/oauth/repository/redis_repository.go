@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/oauth"
+)
+
+const (
+	basePrefix = "oauth:code:"
+)
+
+// Authorization code repository
+type authorizationCodeRepo struct {
+	redisClient *redis.Client
+	basePrefix  string
+}
+
+var _ oauth.AuthorizationCodeRepository = (*authorizationCodeRepo)(nil)
+
+// Authorization code repository constructor
+func NewAuthorizationCodeRepository(redisClient *redis.Client) oauth.AuthorizationCodeRepository {
+	return &authorizationCodeRepo{redisClient: redisClient, basePrefix: basePrefix}
+}
+
+// Create store the grant of a new code under the hash of the code
+func (r *authorizationCodeRepo) Create(ctx context.Context, codeHash string, code *models.AuthorizationCode, expire time.Duration) error {
+	codeBytes, err := json.Marshal(code)
+	if err != nil {
+		return errors.WithMessage(err, "authorizationCodeRepo.Create.json.Marshal")
+	}
+
+	if err := r.redisClient.Set(ctx, r.generateKey(codeHash), codeBytes, expire).Err(); err != nil {
+		return errors.Wrap(err, "authorizationCodeRepo.Create.redisClient.Set")
+	}
+	return nil
+}
+
+// Consume get the grant of a code and delete it in one step so a code is only ever exchanged once,
+// redis.Nil when it is unknown, expired or already used
+func (r *authorizationCodeRepo) Consume(ctx context.Context, codeHash string) (*models.AuthorizationCode, error) {
+	key := r.generateKey(codeHash)
+
+	var get *redis.StringCmd
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "authorizationCodeRepo.Consume.redisClient.TxPipelined")
+	}
+
+	code := &models.AuthorizationCode{}
+	if err := json.Unmarshal([]byte(get.Val()), code); err != nil {
+		return nil, errors.Wrap(err, "authorizationCodeRepo.Consume.json.Unmarshal")
+	}
+	return code, nil
+}
+
+func (r *authorizationCodeRepo) generateKey(codeHash string) string {
+	return r.basePrefix + codeHash
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+func TestAuthorizationCodeRepository_Consume(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	codeRepository := NewAuthorizationCodeRepository(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+
+	t.Run("Code is consumed once", func(t *testing.T) {
+		code := &models.AuthorizationCode{ClientID: uuid.New(), UserID: uuid.New(), SessionID: "s", RedirectURI: "https://app.example.com/cb"}
+		require.NoError(t, codeRepository.Create(ctx, "hash", code, time.Minute))
+
+		consumed, err := codeRepository.Consume(ctx, "hash")
+		require.NoError(t, err)
+		require.Equal(t, code, consumed)
+
+		_, err = codeRepository.Consume(ctx, "hash")
+		require.ErrorIs(t, err, redis.Nil)
+	})
+
+	t.Run("Unknown code", func(t *testing.T) {
+		_, err := codeRepository.Consume(ctx, "unknown")
+		require.ErrorIs(t, err, redis.Nil)
+	})
+}
@@ -0,0 +1,14 @@
+package repository
+
+const (
+	createClientQuery = `INSERT INTO oauth_clients (name, client_type, secret_hash, redirect_uris, scopes) VALUES ($1, $2, $3, $4, $5)
+		RETURNING client_id, name, client_type, secret_hash, redirect_uris, scopes, created_at, updated_at`
+
+	findAllClientsQuery = `SELECT client_id, name, client_type, secret_hash, redirect_uris, scopes, created_at, updated_at
+		FROM oauth_clients ORDER BY name`
+
+	findClientByIdQuery = `SELECT client_id, name, client_type, secret_hash, redirect_uris, scopes, created_at, updated_at
+		FROM oauth_clients WHERE client_id = $1`
+
+	deleteClientByIdQuery = `DELETE FROM oauth_clients WHERE client_id = $1`
+)
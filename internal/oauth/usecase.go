@@ -0,0 +1,22 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase.go -package mock
+package oauth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// OAuth UseCase
+type OAuthUseCase interface {
+	CreateClient(ctx context.Context, client *models.OAuthClient) (string, *models.OAuthClient, error)
+	FindAllClients(ctx context.Context) ([]models.OAuthClient, error)
+	FindClientById(ctx context.Context, clientID uuid.UUID) (*models.OAuthClient, error)
+	DeleteClient(ctx context.Context, clientID uuid.UUID) error
+	Authorize(ctx context.Context, sessionID string, grant *models.AuthorizationCode) (string, error)
+	AuthenticateClient(ctx context.Context, clientID uuid.UUID, secret string) (*models.OAuthClient, error)
+	ExchangeCode(ctx context.Context, client *models.OAuthClient, code string, redirectURI string, codeVerifier string) (*models.Session, error)
+	RefreshSession(ctx context.Context, client *models.OAuthClient, refreshToken string) (*models.Session, error)
+}
@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/oauth"
+	"github.com/dinorain/useraja/internal/session"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/utils"
+)
+
+// OAuth use case
+type oauthUseCase struct {
+	cfg        *config.Config
+	logger     logger.Logger
+	clientRepo oauth.OAuthClientPGRepository
+	codeRepo   oauth.AuthorizationCodeRepository
+	sessRepo   session.SessRepository
+}
+
+var _ oauth.OAuthUseCase = (*oauthUseCase)(nil)
+
+// New OAuth use case constructor
+func NewOAuthUseCase(
+	cfg *config.Config,
+	logger logger.Logger,
+	clientRepo oauth.OAuthClientPGRepository,
+	codeRepo oauth.AuthorizationCodeRepository,
+	sessRepo session.SessRepository,
+) *oauthUseCase {
+	return &oauthUseCase{cfg: cfg, logger: logger, clientRepo: clientRepo, codeRepo: codeRepo, sessRepo: sessRepo}
+}
+
+// CreateClient register a new client, confidential clients get a secret which is only ever returned here
+func (u *oauthUseCase) CreateClient(ctx context.Context, client *models.OAuthClient) (string, *models.OAuthClient, error) {
+	var secret string
+	client.SecretHash = ""
+	if client.Confidential() {
+		token, err := utils.GenerateToken()
+		if err != nil {
+			return "", nil, errors.Wrap(err, "utils.GenerateToken")
+		}
+		secret = models.OAuthClientSecretPrefix + token
+		client.SecretHash = utils.HashToken(secret)
+	}
+
+	createdClient, err := u.clientRepo.Create(ctx, client)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "clientRepo.Create")
+	}
+
+	return secret, createdClient, nil
+}
+
+// FindAllClients find every registered client
+func (u *oauthUseCase) FindAllClients(ctx context.Context) ([]models.OAuthClient, error) {
+	clients, err := u.clientRepo.FindAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "clientRepo.FindAll")
+	}
+
+	return clients, nil
+}
+
+// FindClientById find registered client by id
+func (u *oauthUseCase) FindClientById(ctx context.Context, clientID uuid.UUID) (*models.OAuthClient, error) {
+	client, err := u.clientRepo.FindById(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrOAuthClientNotFound.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "clientRepo.FindById")
+	}
+
+	return client, nil
+}
+
+// DeleteClient unregister a client and end its sessions, so its access and refresh tokens stop working
+func (u *oauthUseCase) DeleteClient(ctx context.Context, clientID uuid.UUID) error {
+	if err := u.clientRepo.DeleteById(ctx, clientID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain_errors.ErrOAuthClientNotFound.Wrap(err)
+		}
+		return errors.Wrap(err, "clientRepo.DeleteById")
+	}
+
+	if err := u.sessRepo.DeleteByClientId(ctx, clientID); err != nil {
+		return errors.Wrap(err, "sessRepo.DeleteByClientId")
+	}
+
+	return nil
+}
+
+// Authorize issue an authorization code granting the client of grant access on behalf of the user of the session.
+// The redirect uri must be registered for the client and the scopes allowed to it, no scopes requests every allowed one
+func (u *oauthUseCase) Authorize(ctx context.Context, sessionID string, grant *models.AuthorizationCode) (string, error) {
+	client, err := u.FindClientById(ctx, grant.ClientID)
+	if err != nil {
+		if errors.Is(err, domain_errors.ErrOAuthClientNotFound) {
+			return "", domain_errors.ErrInvalidOAuthClient.Wrap(err)
+		}
+		return "", err
+	}
+
+	if !client.AllowsRedirectURI(grant.RedirectURI) {
+		return "", domain_errors.ErrInvalidRedirectURI
+	}
+
+	if len(grant.Scopes) == 0 {
+		grant.Scopes = client.Scopes
+	}
+	for _, scope := range grant.Scopes {
+		if !client.AllowsScope(scope) {
+			return "", domain_errors.ErrInvalidOAuthScope
+		}
+	}
+
+	sess, err := u.sessRepo.GetSessionById(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", domain_errors.ErrSessionNotFound.Wrap(err)
+		}
+		return "", errors.Wrap(err, "sessRepo.GetSessionById")
+	}
+	// tokens issued to a client can not hand the user over to another client
+	if sess.ClientID != "" {
+		return "", domain_errors.ErrForbidden
+	}
+	grant.UserID = sess.UserID
+	grant.SessionID = sess.SessionID
+
+	code, err := utils.GenerateToken()
+	if err != nil {
+		return "", errors.Wrap(err, "utils.GenerateToken")
+	}
+
+	if err := u.codeRepo.Create(ctx, utils.HashToken(code), grant, time.Duration(u.cfg.OAuth.CodeExpire)*time.Second); err != nil {
+		return "", errors.Wrap(err, "codeRepo.Create")
+	}
+
+	return code, nil
+}
+
+// AuthenticateClient find the client calling the token endpoint, confidential clients must present their secret.
+// domain_errors.ErrInvalidOAuthClient when it is unknown or the secret does not match
+func (u *oauthUseCase) AuthenticateClient(ctx context.Context, clientID uuid.UUID, secret string) (*models.OAuthClient, error) {
+	client, err := u.FindClientById(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, domain_errors.ErrOAuthClientNotFound) {
+			return nil, domain_errors.ErrInvalidOAuthClient.Wrap(err)
+		}
+		return nil, err
+	}
+
+	if client.Confidential() && subtle.ConstantTimeCompare([]byte(utils.HashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, domain_errors.ErrInvalidOAuthClient
+	}
+
+	return client, nil
+}
+
+// ExchangeCode consume an authorization code issued to client and start the session its tokens are issued for.
+// The session that authorized the client must still exist, the client session carries no authentication time so it
+// never passes recent authentication or step-up checks. domain_errors.ErrInvalidGrant when the session ended or the
+// redirect uri or PKCE code verifier do not match
+func (u *oauthUseCase) ExchangeCode(ctx context.Context, client *models.OAuthClient, code string, redirectURI string, codeVerifier string) (*models.Session, error) {
+	grant, err := u.codeRepo.Consume(ctx, utils.HashToken(code))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain_errors.ErrInvalidGrant.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "codeRepo.Consume")
+	}
+
+	if grant.ClientID != client.ClientID || grant.RedirectURI != redirectURI || !grant.VerifyCodeVerifier(codeVerifier) {
+		return nil, domain_errors.ErrInvalidGrant
+	}
+
+	if _, err := u.sessRepo.GetSessionById(ctx, grant.SessionID); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain_errors.ErrInvalidGrant.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "sessRepo.GetSessionById")
+	}
+
+	sess := &models.Session{
+		UserID:   grant.UserID,
+		ClientID: client.ClientID.String(),
+		Scopes:   grant.Scopes,
+	}
+	if _, err := u.sessRepo.CreateSession(ctx, sess, u.cfg.Session.Expire); err != nil {
+		return nil, errors.Wrap(err, "sessRepo.CreateSession")
+	}
+
+	return sess, nil
+}
+
+// RefreshSession session of a refresh token issued to client, domain_errors.ErrInvalidGrant when the token is invalid,
+// its session ended or it was issued to another client
+func (u *oauthUseCase) RefreshSession(ctx context.Context, client *models.OAuthClient, refreshToken string) (*models.Session, error) {
+	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("jwt.SigningMethodHMAC: %v", token.Header["alg"])
+		}
+
+		return []byte(u.cfg.Server.JwtSecretKey), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, domain_errors.ErrInvalidGrant.Wrap(err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, domain_errors.ErrInvalidGrant
+	}
+	sessionID, ok := claims["session_id"].(string)
+	if !ok {
+		return nil, domain_errors.ErrInvalidGrant
+	}
+
+	sess, err := u.sessRepo.GetSessionById(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain_errors.ErrInvalidGrant.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "sessRepo.GetSessionById")
+	}
+
+	if sess.ClientID != client.ClientID.String() {
+		return nil, domain_errors.ErrInvalidGrant
+	}
+
+	return sess, nil
+}
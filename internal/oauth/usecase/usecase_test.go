@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/oauth/mock"
+	mockSessRepo "github.com/dinorain/useraja/internal/session/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/utils"
+)
+
+func TestOAuthUseCase_Clients(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clientRepository := mock.NewMockOAuthClientPGRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	oauthUC := NewOAuthUseCase(&config.Config{}, logger.NewAppLogger(nil), clientRepository, nil, sessRepository)
+
+	ctx := context.Background()
+	clientID := uuid.New()
+
+	var secretHash string
+	t.Run("Confidential client gets a secret", func(t *testing.T) {
+		clientRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, client *models.OAuthClient) (*models.OAuthClient, error) {
+			secretHash = client.SecretHash
+			return &models.OAuthClient{ClientID: clientID, ClientType: client.ClientType, SecretHash: client.SecretHash}, nil
+		})
+
+		secret, createdClient, err := oauthUC.CreateClient(ctx, &models.OAuthClient{Name: "web", ClientType: models.OAuthClientTypeConfidential})
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(secret, models.OAuthClientSecretPrefix))
+		require.Equal(t, utils.HashToken(secret), secretHash)
+		require.Equal(t, clientID, createdClient.ClientID)
+
+		clientRepository.EXPECT().FindById(gomock.Any(), clientID).Return(createdClient, nil)
+		authenticatedClient, err := oauthUC.AuthenticateClient(ctx, clientID, secret)
+		require.NoError(t, err)
+		require.Equal(t, clientID, authenticatedClient.ClientID)
+
+		clientRepository.EXPECT().FindById(gomock.Any(), clientID).Return(createdClient, nil)
+		_, err = oauthUC.AuthenticateClient(ctx, clientID, "ujc_wrong")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidOAuthClient)
+	})
+
+	t.Run("Public client has no secret", func(t *testing.T) {
+		clientRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, client *models.OAuthClient) (*models.OAuthClient, error) {
+			require.Empty(t, client.SecretHash)
+			return &models.OAuthClient{ClientID: clientID, ClientType: client.ClientType}, nil
+		})
+
+		secret, _, err := oauthUC.CreateClient(ctx, &models.OAuthClient{Name: "mobile", ClientType: models.OAuthClientTypePublic, SecretHash: "ignored"})
+		require.NoError(t, err)
+		require.Empty(t, secret)
+	})
+	t.Run("Deleting a client ends its sessions", func(t *testing.T) {
+		clientRepository.EXPECT().DeleteById(gomock.Any(), clientID).Return(nil)
+		sessRepository.EXPECT().DeleteByClientId(gomock.Any(), clientID).Return(nil)
+
+		require.NoError(t, oauthUC.DeleteClient(ctx, clientID))
+	})
+}
+
+func TestOAuthUseCase_AuthorizationCodeFlow(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clientRepository := mock.NewMockOAuthClientPGRepository(ctrl)
+	codeRepository := mock.NewMockAuthorizationCodeRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	cfg := &config.Config{
+		OAuth:   config.OAuth{CodeExpire: 60},
+		Session: config.Session{Expire: 3600},
+		Server:  config.ServerConfig{JwtSecretKey: "secret"},
+	}
+	oauthUC := NewOAuthUseCase(cfg, logger.NewAppLogger(nil), clientRepository, codeRepository, sessRepository)
+
+	ctx := context.Background()
+	client := &models.OAuthClient{
+		ClientID:     uuid.New(),
+		ClientType:   models.OAuthClientTypePublic,
+		RedirectURIs: pq.StringArray{"https://app.example.com/callback"},
+		Scopes:       pq.StringArray{models.APIKeyScopeUsersRead},
+	}
+	userSession := &models.Session{SessionID: "user-session", UserID: uuid.New(), AuthTime: time.Now(), ACR: models.SessionACRBasic, AMR: []string{models.AuthMethodPassword}}
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	hash := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	t.Run("Unregistered redirect uri", func(t *testing.T) {
+		clientRepository.EXPECT().FindById(gomock.Any(), client.ClientID).Return(client, nil)
+
+		_, err := oauthUC.Authorize(ctx, userSession.SessionID, &models.AuthorizationCode{ClientID: client.ClientID, RedirectURI: "https://evil.example.com/callback", CodeChallenge: challenge})
+		require.ErrorIs(t, err, domain_errors.ErrInvalidRedirectURI)
+	})
+
+	t.Run("Scope not allowed to the client", func(t *testing.T) {
+		clientRepository.EXPECT().FindById(gomock.Any(), client.ClientID).Return(client, nil)
+
+		_, err := oauthUC.Authorize(ctx, userSession.SessionID, &models.AuthorizationCode{
+			ClientID:      client.ClientID,
+			RedirectURI:   "https://app.example.com/callback",
+			Scopes:        []string{models.APIKeyScopeUsersWrite},
+			CodeChallenge: challenge,
+		})
+		require.ErrorIs(t, err, domain_errors.ErrInvalidOAuthScope)
+	})
+
+	t.Run("Client session can not authorize", func(t *testing.T) {
+		clientRepository.EXPECT().FindById(gomock.Any(), client.ClientID).Return(client, nil)
+		sessRepository.EXPECT().GetSessionById(gomock.Any(), "client-session").Return(&models.Session{SessionID: "client-session", ClientID: uuid.New().String()}, nil)
+
+		_, err := oauthUC.Authorize(ctx, "client-session", &models.AuthorizationCode{ClientID: client.ClientID, RedirectURI: "https://app.example.com/callback", CodeChallenge: challenge})
+		require.ErrorIs(t, err, domain_errors.ErrForbidden)
+	})
+
+	var code string
+	var grant *models.AuthorizationCode
+	t.Run("Authorize", func(t *testing.T) {
+		clientRepository.EXPECT().FindById(gomock.Any(), client.ClientID).Return(client, nil)
+		sessRepository.EXPECT().GetSessionById(gomock.Any(), userSession.SessionID).Return(userSession, nil)
+		var codeHash string
+		codeRepository.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any(), time.Minute).
+			DoAndReturn(func(ctx context.Context, hash string, authorizationCode *models.AuthorizationCode, expire time.Duration) error {
+				codeHash = hash
+				grant = authorizationCode
+				return nil
+			})
+
+		var err error
+		code, err = oauthUC.Authorize(ctx, userSession.SessionID, &models.AuthorizationCode{ClientID: client.ClientID, RedirectURI: "https://app.example.com/callback", CodeChallenge: challenge})
+		require.NoError(t, err)
+		require.Equal(t, utils.HashToken(code), codeHash)
+		require.Equal(t, userSession.UserID, grant.UserID)
+		require.Equal(t, userSession.SessionID, grant.SessionID)
+		require.Equal(t, []string(client.Scopes), grant.Scopes)
+	})
+
+	t.Run("Wrong code verifier", func(t *testing.T) {
+		codeRepository.EXPECT().Consume(gomock.Any(), utils.HashToken(code)).Return(grant, nil)
+
+		_, err := oauthUC.ExchangeCode(ctx, client, code, "https://app.example.com/callback", "wrong-verifier")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidGrant)
+	})
+
+	t.Run("Used code", func(t *testing.T) {
+		codeRepository.EXPECT().Consume(gomock.Any(), utils.HashToken(code)).Return(nil, redis.Nil)
+
+		_, err := oauthUC.ExchangeCode(ctx, client, code, "https://app.example.com/callback", verifier)
+		require.ErrorIs(t, err, domain_errors.ErrInvalidGrant)
+	})
+
+	t.Run("User logged out before the exchange", func(t *testing.T) {
+		codeRepository.EXPECT().Consume(gomock.Any(), utils.HashToken(code)).Return(grant, nil)
+		sessRepository.EXPECT().GetSessionById(gomock.Any(), userSession.SessionID).Return(nil, redis.Nil)
+
+		_, err := oauthUC.ExchangeCode(ctx, client, code, "https://app.example.com/callback", verifier)
+		require.ErrorIs(t, err, domain_errors.ErrInvalidGrant)
+	})
+
+	var clientSession *models.Session
+	t.Run("Exchange", func(t *testing.T) {
+		codeRepository.EXPECT().Consume(gomock.Any(), utils.HashToken(code)).Return(grant, nil)
+		sessRepository.EXPECT().GetSessionById(gomock.Any(), userSession.SessionID).Return(userSession, nil)
+		sessRepository.EXPECT().CreateSession(gomock.Any(), gomock.Any(), cfg.Session.Expire).
+			DoAndReturn(func(ctx context.Context, session *models.Session, expire int) (string, error) {
+				session.SessionID = "client-session"
+				return session.SessionID, nil
+			})
+
+		var err error
+		clientSession, err = oauthUC.ExchangeCode(ctx, client, code, "https://app.example.com/callback", verifier)
+		require.NoError(t, err)
+		require.Equal(t, "client-session", clientSession.SessionID)
+		require.Equal(t, client.ClientID.String(), clientSession.ClientID)
+		require.Equal(t, userSession.UserID, clientSession.UserID)
+		require.False(t, clientSession.AuthenticatedWithin(time.Hour))
+		require.Empty(t, clientSession.AMR)
+		require.Equal(t, []string(client.Scopes), clientSession.Scopes)
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"session_id": clientSession.SessionID,
+			"exp":        time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		sessRepository.EXPECT().GetSessionById(gomock.Any(), clientSession.SessionID).Return(clientSession, nil).Times(2)
+
+		refreshedSession, err := oauthUC.RefreshSession(ctx, client, refreshToken)
+		require.NoError(t, err)
+		require.Equal(t, clientSession, refreshedSession)
+
+		_, err = oauthUC.RefreshSession(ctx, &models.OAuthClient{ClientID: uuid.New()}, refreshToken)
+		require.ErrorIs(t, err, domain_errors.ErrInvalidGrant)
+	})
+}
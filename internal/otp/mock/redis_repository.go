@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: redis_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOTPRepository is a mock of OTPRepository interface.
+type MockOTPRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPRepositoryMockRecorder
+}
+
+// MockOTPRepositoryMockRecorder is the mock recorder for MockOTPRepository.
+type MockOTPRepositoryMockRecorder struct {
+	mock *MockOTPRepository
+}
+
+// NewMockOTPRepository creates a new mock instance.
+func NewMockOTPRepository(ctrl *gomock.Controller) *MockOTPRepository {
+	mock := &MockOTPRepository{ctrl: ctrl}
+	mock.recorder = &MockOTPRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPRepository) EXPECT() *MockOTPRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOTPRepository) Create(ctx context.Context, key, codeHash string, expire time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, key, codeHash, expire)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOTPRepositoryMockRecorder) Create(ctx, key, codeHash, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOTPRepository)(nil).Create), ctx, key, codeHash, expire)
+}
+
+// Verify mocks base method.
+func (m *MockOTPRepository) Verify(ctx context.Context, key, codeHash string, maxAttempts int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, key, codeHash, maxAttempts)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockOTPRepositoryMockRecorder) Verify(ctx, key, codeHash, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockOTPRepository)(nil).Verify), ctx, key, codeHash, maxAttempts)
+}
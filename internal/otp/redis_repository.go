@@ -0,0 +1,13 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository.go -package mock
+package otp
+
+import (
+	"context"
+	"time"
+)
+
+// One time code repository
+type OTPRepository interface {
+	Create(ctx context.Context, key string, codeHash string, expire time.Duration) error
+	Verify(ctx context.Context, key string, codeHash string, maxAttempts int) (bool, error)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/internal/otp"
+)
+
+const (
+	basePrefix = "otp:"
+)
+
+// verifyScript counts an attempt and compares the code in one step, the code is removed once it matches
+// or the attempts are used up. Returns 1 on a match, 0 otherwise
+var verifyScript = redis.NewScript(`
+local code = redis.call('HGET', KEYS[1], 'code')
+if not code then
+	return 0
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if code == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return 1
+end
+if attempts >= tonumber(ARGV[2]) then
+	redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// One time code repository
+type otpRepo struct {
+	redisClient *redis.Client
+	basePrefix  string
+}
+
+var _ otp.OTPRepository = (*otpRepo)(nil)
+
+// One time code repository constructor
+func NewOTPRepository(redisClient *redis.Client) otp.OTPRepository {
+	return &otpRepo{redisClient: redisClient, basePrefix: basePrefix}
+}
+
+// Create store the hash of a new code for key, replacing the previous code and its attempts
+func (r *otpRepo) Create(ctx context.Context, key string, codeHash string, expire time.Duration) error {
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, r.generateKey(key))
+		pipe.HMSet(ctx, r.generateKey(key), "code", codeHash, "attempts", 0)
+		pipe.Expire(ctx, r.generateKey(key), expire)
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "otpRepo.Create.redisClient.TxPipelined")
+	}
+	return nil
+}
+
+// Verify consume the code of key when codeHash matches, every call counts as one of maxAttempts attempts
+func (r *otpRepo) Verify(ctx context.Context, key string, codeHash string, maxAttempts int) (bool, error) {
+	matched, err := verifyScript.Run(ctx, r.redisClient, []string{r.generateKey(key)}, codeHash, maxAttempts).Int()
+	if err != nil {
+		return false, errors.Wrap(err, "otpRepo.Verify.verifyScript.Run")
+	}
+	return matched == 1, nil
+}
+
+func (r *otpRepo) generateKey(key string) string {
+	return r.basePrefix + key
+}
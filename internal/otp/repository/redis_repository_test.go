@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPRepository_Verify(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	otpRepository := NewOTPRepository(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+
+	t.Run("Match consumes the code", func(t *testing.T) {
+		require.NoError(t, otpRepository.Create(ctx, "login:user", "hash", time.Minute))
+
+		matched, err := otpRepository.Verify(ctx, "login:user", "hash", 3)
+		require.NoError(t, err)
+		require.True(t, matched)
+
+		matched, err = otpRepository.Verify(ctx, "login:user", "hash", 3)
+		require.NoError(t, err)
+		require.False(t, matched)
+	})
+
+	t.Run("Attempts are limited", func(t *testing.T) {
+		require.NoError(t, otpRepository.Create(ctx, "step_up:user", "hash", time.Minute))
+
+		for i := 0; i < 3; i++ {
+			matched, err := otpRepository.Verify(ctx, "step_up:user", "wrong", 3)
+			require.NoError(t, err)
+			require.False(t, matched)
+		}
+
+		matched, err := otpRepository.Verify(ctx, "step_up:user", "hash", 3)
+		require.NoError(t, err)
+		require.False(t, matched)
+	})
+}
@@ -0,0 +1,12 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository.go -package mock
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Rate limit repository
+type RateLimitRepository interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
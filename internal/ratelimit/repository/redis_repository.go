@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/internal/ratelimit"
+)
+
+const (
+	basePrefix = "ratelimit:"
+)
+
+// Rate limit repository
+type rateLimitRepo struct {
+	redisClient *redis.Client
+	basePrefix  string
+}
+
+var _ ratelimit.RateLimitRepository = (*rateLimitRepo)(nil)
+
+// Rate limit repository constructor
+func NewRateLimitRepository(redisClient *redis.Client) ratelimit.RateLimitRepository {
+	return &rateLimitRepo{redisClient: redisClient, basePrefix: basePrefix}
+}
+
+// Allow count a hit of key in its fixed window, once limit is exceeded the time left in the window is returned
+func (r *rateLimitRepo) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	var incr *redis.IntCmd
+	var ttl *redis.DurationCmd
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, r.generateKey(key))
+		ttl = pipe.TTL(ctx, r.generateKey(key))
+		return nil
+	}); err != nil {
+		return false, 0, errors.Wrap(err, "rateLimitRepo.Allow.redisClient.TxPipelined")
+	}
+
+	// the window starts with its first hit, a counter left without expiry is given one too
+	retryAfter := ttl.Val()
+	if retryAfter < 0 {
+		if err := r.redisClient.Expire(ctx, r.generateKey(key), window).Err(); err != nil {
+			return false, 0, errors.Wrap(err, "rateLimitRepo.Allow.redisClient.Expire")
+		}
+		retryAfter = window
+	}
+
+	if incr.Val() > int64(limit) {
+		return false, retryAfter, nil
+	}
+	return true, 0, nil
+}
+
+func (r *rateLimitRepo) generateKey(key string) string {
+	return r.basePrefix + key
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitRepository_Allow(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	rateLimitRepository := NewRateLimitRepository(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := rateLimitRepository.Allow(ctx, "signup:127.0.0.1", 2, time.Minute)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := rateLimitRepository.Allow(ctx, "signup:127.0.0.1", 2, time.Minute)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Equal(t, time.Minute, retryAfter)
+
+	allowed, _, err = rateLimitRepository.Allow(ctx, "signup:127.0.0.2", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	mr.FastForward(time.Minute)
+	allowed, _, err = rateLimitRepository.Allow(ctx, "signup:127.0.0.1", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
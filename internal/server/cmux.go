@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"net"
+
+	"github.com/soheilhy/cmux"
+	"google.golang.org/grpc"
+)
+
+// serveMultiplexed serves gRPC and HTTP on the single listener l.
+// HTTP/2 connections carrying grpc content types are routed to grpcS, everything else
+// (HTTP/1.1 REST, gRPC-Web, swagger) is served by echo.
+func (s *Server) serveMultiplexed(ctx context.Context, cancel context.CancelFunc, l net.Listener, grpcS *grpc.Server) {
+	m := cmux.New(l)
+	m.SetReadTimeout(readTimeout)
+
+	grpcL := m.MatchWithWriters(
+		cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"),
+		cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc+proto"),
+	)
+	httpL := m.Match(cmux.Any())
+
+	go func() {
+		if err := grpcS.Serve(grpcL); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("grpcS.Serve: %v", err)
+			cancel()
+		}
+	}()
+
+	go func() {
+		if err := s.runHttpServer(&httpL); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("s.runHttpServer: %v", err)
+			cancel()
+		}
+	}()
+
+	go func() {
+		s.logger.Infof("Server is listening on single port: %v", l.Addr().String())
+		if err := m.Serve(); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("cmux.Serve: %v", err)
+			cancel()
+		}
+	}()
+}
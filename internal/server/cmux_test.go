@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/middlewares"
+	"github.com/dinorain/useraja/pkg/logger"
+	userService "github.com/dinorain/useraja/proto"
+)
+
+func TestServer_ServeMultiplexed(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Server: config.ServerConfig{SinglePort: true}}
+	appLogger := logger.NewAppLogger(cfg)
+	s := NewAuthServer(appLogger, cfg, nil, nil)
+	s.echo.HideBanner = true
+	s.mw = middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+	s.echo.GET("/ping", func(c echo.Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	grpcS := grpc.NewServer()
+	userService.RegisterUserServiceServer(grpcS, &userService.UnimplementedUserServiceServer{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.serveMultiplexed(ctx, cancel, l, grpcS)
+	defer grpcS.Stop()
+
+	t.Run("HTTP", func(t *testing.T) {
+		res, err := http.Get("http://" + l.Addr().String() + "/ping")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("gRPC", func(t *testing.T) {
+		dialCtx, dialCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer dialCancel()
+
+		conn, err := grpc.DialContext(dialCtx, l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = userService.NewUserServiceClient(conn).GetMe(dialCtx, &userService.GetMeRequest{})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
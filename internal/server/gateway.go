@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/dinorain/useraja/pkg/grpc_errors"
+	userService "github.com/dinorain/useraja/proto"
+)
+
+const (
+	gatewayBasePath = "/api/v2"
+)
+
+// mapGateway mounts the grpc-gateway REST API generated from user.proto on echo
+func (s *Server) mapGateway(ctx context.Context) error {
+	mux := newGatewayMux()
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if err := userService.RegisterUserServiceHandlerFromEndpoint(ctx, mux, s.cfg.Server.Port, opts); err != nil {
+		return err
+	}
+
+	s.echo.GET(gatewayBasePath+"/swagger.json", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, userService.OpenAPIv2)
+	})
+	s.echo.Any(gatewayBasePath+"/*", echo.WrapHandler(mux))
+
+	return nil
+}
+
+func newGatewayMux() *runtime.ServeMux {
+	return runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithErrorHandler(gatewayErrorHandler),
+	)
+}
+
+// gatewayErrorHandler writes the grpc status as json with the http status from grpc_errors.MapGRPCErrCodeToHttpStatus
+func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(err)
+
+	body, mErr := marshaler.Marshal(st.Proto())
+	if mErr != nil {
+		w.Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	w.Header().Set(echo.HeaderContentType, marshaler.ContentType(st.Proto()))
+	w.WriteHeader(grpc_errors.MapGRPCErrCodeToHttpStatus(st.Code()))
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userService "github.com/dinorain/useraja/proto"
+)
+
+type gatewayTestServer struct {
+	userService.UnimplementedUserServiceServer
+}
+
+func (s *gatewayTestServer) FindAll(ctx context.Context, r *userService.FindAllRequest) (*userService.FindAllResponse, error) {
+	if r.GetRole() == "unknown" {
+		return nil, status.Error(codes.NotFound, "role not found")
+	}
+	return &userService.FindAllResponse{Users: []*userService.User{{Uuid: uuid.Nil.String(), FirstName: "FirstName"}}, Page: r.GetPage()}, nil
+}
+
+func (s *gatewayTestServer) Register(ctx context.Context, r *userService.RegisterRequest) (*userService.RegisterResponse, error) {
+	return &userService.RegisterResponse{User: &userService.User{Email: r.GetEmail()}}, nil
+}
+
+func (s *gatewayTestServer) FindByEmail(ctx context.Context, r *userService.FindByEmailRequest) (*userService.FindByEmailResponse, error) {
+	return &userService.FindByEmailResponse{User: &userService.User{Email: r.GetEmail()}}, nil
+}
+
+func (s *gatewayTestServer) FindById(ctx context.Context, r *userService.FindByIdRequest) (*userService.FindByIdResponse, error) {
+	return &userService.FindByIdResponse{User: &userService.User{Uuid: r.GetUuid()}}, nil
+}
+
+func (s *gatewayTestServer) GetMe(ctx context.Context, r *userService.GetMeRequest) (*userService.GetMeResponse, error) {
+	return &userService.GetMeResponse{User: &userService.User{Uuid: "me"}}, nil
+}
+
+func TestGateway(t *testing.T) {
+	t.Parallel()
+
+	mux := newGatewayMux()
+	require.NoError(t, userService.RegisterUserServiceHandlerServer(context.Background(), mux, &gatewayTestServer{}))
+
+	t.Run("FindAll", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/users?page=2", nil)
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusOK, res.Code)
+		body := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+		require.Equal(t, float64(2), body["page"])
+		require.Equal(t, "FirstName", body["users"].([]interface{})[0].(map[string]interface{})["first_name"])
+	})
+
+	t.Run("GetMe", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/users/me", nil)
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Contains(t, res.Body.String(), `"uuid":"me"`)
+	})
+
+	t.Run("Error mapping", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/users?role=unknown", nil)
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusNotFound, res.Code)
+		require.Contains(t, res.Body.String(), "role not found")
+	})
+
+	t.Run("Unauthenticated rpcs are not exposed", func(t *testing.T) {
+		t.Parallel()
+
+		for _, path := range []string{"/api/v2/users/" + uuid.New().String(), "/api/v2/users/email/email@gmail.com"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			res := httptest.NewRecorder()
+			mux.ServeHTTP(res, req)
+
+			require.NotEqual(t, http.StatusOK, res.Code, path)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/users", strings.NewReader(`{"email":"email@gmail.com"}`))
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, req)
+
+		require.NotEqual(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("Unimplemented", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/users/logout", nil)
+		res := httptest.NewRecorder()
+		mux.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusNotImplemented, res.Code)
+	})
+}
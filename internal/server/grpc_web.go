@@ -0,0 +1,51 @@
+package server
+
+import (
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+)
+
+var grpcWebAllowedHeaders = []string{
+	"content-type",
+	"x-grpc-web",
+	"x-user-agent",
+	"grpc-timeout",
+	"authorization",
+	"session_id",
+}
+
+// mapGrpcWeb serves gRPC-Web and its CORS preflight requests with grpcS before echo routing,
+// so grpc-web calls go through the same interceptors as native gRPC calls.
+// Server streaming RPCs are supported, client and bidi streaming are not.
+func (s *Server) mapGrpcWeb(grpcS *grpc.Server) {
+	if !s.cfg.GrpcWeb.Enabled {
+		return
+	}
+
+	wrapped := grpcweb.WrapServer(grpcS,
+		grpcweb.WithOriginFunc(s.isAllowedGrpcWebOrigin),
+		grpcweb.WithAllowedRequestHeaders(grpcWebAllowedHeaders),
+		grpcweb.WithCorsForRegisteredEndpointsOnly(true),
+	)
+
+	s.echo.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if wrapped.IsGrpcWebRequest(req) || wrapped.IsAcceptableGrpcCorsRequest(req) {
+				wrapped.ServeHTTP(c.Response(), req)
+				return nil
+			}
+			return next(c)
+		}
+	})
+}
+
+func (s *Server) isAllowedGrpcWebOrigin(origin string) bool {
+	for _, allowed := range s.cfg.GrpcWeb.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
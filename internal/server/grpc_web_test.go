@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/pkg/logger"
+	userService "github.com/dinorain/useraja/proto"
+)
+
+type grpcWebTestServer struct {
+	userService.UnimplementedUserServiceServer
+}
+
+func (s *grpcWebTestServer) GetMe(ctx context.Context, r *userService.GetMeRequest) (*userService.GetMeResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return &userService.GetMeResponse{User: &userService.User{Uuid: md.Get("session_id")[0]}}, nil
+}
+
+func grpcWebFrame(t *testing.T, msg proto.Message) *bytes.Buffer {
+	payload, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	frame := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	return bytes.NewBuffer(append(frame, payload...))
+}
+
+func TestServer_GrpcWeb(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{GrpcWeb: config.GrpcWeb{Enabled: true, AllowedOrigins: []string{"http://localhost:3000"}}}
+	s := NewAuthServer(logger.NewAppLogger(cfg), cfg, nil, nil)
+
+	intercepted := false
+	grpcS := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		intercepted = true
+		return handler(ctx, req)
+	}))
+	userService.RegisterUserServiceServer(grpcS, &grpcWebTestServer{})
+	s.mapGrpcWeb(grpcS)
+
+	t.Run("Unary call", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/userService.UserService/GetMe", grpcWebFrame(t, &userService.GetMeRequest{}))
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+		req.Header.Set("Origin", "http://localhost:3000")
+		req.Header.Set("session_id", "session")
+		res := httptest.NewRecorder()
+		s.echo.ServeHTTP(res, req)
+
+		require.Equal(t, http.StatusOK, res.Code)
+		require.True(t, intercepted)
+		require.Equal(t, "http://localhost:3000", res.Header().Get("Access-Control-Allow-Origin"))
+
+		body := res.Body.Bytes()
+		require.GreaterOrEqual(t, len(body), 5)
+		size := binary.BigEndian.Uint32(body[1:5])
+		response := &userService.GetMeResponse{}
+		require.NoError(t, proto.Unmarshal(body[5:5+size], response))
+		require.Equal(t, "session", response.User.Uuid)
+	})
+
+	t.Run("CORS preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/userService.UserService/GetMe", nil)
+		req.Header.Set("Origin", "http://localhost:3000")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web,session_id")
+		res := httptest.NewRecorder()
+		s.echo.ServeHTTP(res, req)
+
+		require.Equal(t, "http://localhost:3000", res.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("CORS preflight from unknown origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/userService.UserService/GetMe", nil)
+		req.Header.Set("Origin", "http://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+		res := httptest.NewRecorder()
+		s.echo.ServeHTTP(res, req)
+
+		require.Empty(t, res.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
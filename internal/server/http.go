@@ -9,6 +9,8 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 
 	"github.com/dinorain/useraja/docs"
+	"github.com/dinorain/useraja/internal/middlewares"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
 
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
@@ -23,6 +25,12 @@ const (
 )
 
 func (s *Server) runHttpServer(listener *net.Listener) error {
+	ipExtractor, err := middlewares.IPExtractor(s.cfg.Http.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	s.echo.IPExtractor = ipExtractor
+
 	s.mapRoutes()
 
 	s.echo.Server.ReadTimeout = readTimeout
@@ -52,6 +60,8 @@ func (s *Server) mapRoutes() {
 		DisableStackAll:   true,
 	}))
 	s.echo.Use(middleware.RequestID())
+	s.echo.Use(s.mw.ErrorFormatMiddleware)
+	s.echo.HTTPErrorHandler = s.httpErrorHandler
 	s.echo.Use(middleware.GzipWithConfig(middleware.GzipConfig{
 		Level: gzipLevel,
 		Skipper: func(c echo.Context) bool {
@@ -60,3 +70,13 @@ func (s *Server) mapRoutes() {
 	}))
 	s.echo.Use(middleware.BodyLimit(bodyLimit))
 }
+
+// httpErrorHandler write errors returned by middlewares and unknown routes in the configured error format
+func (s *Server) httpErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	if err := httpErrors.ErrorCtxResponse(c, err, s.cfg.Http.DebugErrorsResponse); err != nil {
+		s.logger.WarnMsg("httpErrors.ErrorCtxResponse", err)
+	}
+}
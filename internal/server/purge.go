@@ -0,0 +1,36 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/dinorain/useraja/internal/user"
+)
+
+const defaultPurgeInterval = time.Hour
+
+// runUserPurge purge soft deleted users past the retention period on every interval until ctx is done
+func (s *Server) runUserPurge(ctx context.Context, userUC user.UserUseCase) {
+	interval := s.cfg.Purge.Interval * time.Minute
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := userUC.PurgeDeleted(ctx)
+			if err != nil {
+				s.logger.Errorf("userUC.PurgeDeleted: %v", err)
+			}
+			if purged > 0 {
+				s.logger.Infof("purged %d soft deleted users", purged)
+			}
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"reflect"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,8 +21,20 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	"github.com/dinorain/useraja/config"
+	accessTokenRepository "github.com/dinorain/useraja/internal/accesstoken/repository"
+	deviceRepository "github.com/dinorain/useraja/internal/device/repository"
+	idempotencyRepository "github.com/dinorain/useraja/internal/idempotency/repository"
 	"github.com/dinorain/useraja/internal/interceptors"
 	"github.com/dinorain/useraja/internal/middlewares"
+	"github.com/dinorain/useraja/internal/models"
+	oauthDeliveryHTTP "github.com/dinorain/useraja/internal/oauth/delivery/http/handlers"
+	oauthRepository "github.com/dinorain/useraja/internal/oauth/repository"
+	oauthUseCase "github.com/dinorain/useraja/internal/oauth/usecase"
+	otpRepository "github.com/dinorain/useraja/internal/otp/repository"
+	rateLimitRepository "github.com/dinorain/useraja/internal/ratelimit/repository"
+	serviceAccountDeliveryHTTP "github.com/dinorain/useraja/internal/serviceaccount/delivery/http/handlers"
+	serviceAccountRepository "github.com/dinorain/useraja/internal/serviceaccount/repository"
+	serviceAccountUseCase "github.com/dinorain/useraja/internal/serviceaccount/usecase"
 	sessRepository "github.com/dinorain/useraja/internal/session/repository"
 	sessUseCase "github.com/dinorain/useraja/internal/session/usecase"
 	authServerGRPC "github.com/dinorain/useraja/internal/user/delivery/grpc/service"
@@ -28,9 +42,33 @@ import (
 	userRepository "github.com/dinorain/useraja/internal/user/repository"
 	userUseCase "github.com/dinorain/useraja/internal/user/usecase"
 	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/mailer"
+	"github.com/dinorain/useraja/pkg/sms"
 	userService "github.com/dinorain/useraja/proto"
 )
 
+// recentAuthMethods rpcs the user must have authenticated recently for
+var recentAuthMethods = []string{
+	"/userService.UserService/DeactivateMe",
+}
+
+// apiKeyMethodScopes rpcs service account keys may call and the scope each needs
+var apiKeyMethodScopes = map[string]string{
+	"/userService.UserService/FindAll":        models.APIKeyScopeUsersRead,
+	"/userService.UserService/FindById":       models.APIKeyScopeUsersRead,
+	"/userService.UserService/FindByEmail":    models.APIKeyScopeUsersRead,
+	"/userService.UserService/StreamUsers":    models.APIKeyScopeUsersRead,
+	"/userService.UserService/ExportUsers":    models.APIKeyScopeUsersRead,
+	"/userService.UserService/SuspendUser":    models.APIKeyScopeUsersWrite,
+	"/userService.UserService/ReactivateUser": models.APIKeyScopeUsersWrite,
+	"/userService.UserService/Register":       models.APIKeyScopeUsersWrite,
+}
+
+// oauthClientMethods rpcs sessions of OAuth clients may call without a scope
+var oauthClientMethods = []string{
+	"/userService.UserService/Logout",
+}
+
 type Server struct {
 	logger      logger.Logger
 	cfg         *config.Config
@@ -46,22 +84,42 @@ func NewAuthServer(logger logger.Logger, cfg *config.Config, db *sqlx.DB, redisC
 	return &Server{
 		logger:      logger,
 		cfg:         cfg,
-		v:           validator.New(),
+		v:           newValidator(),
 		echo:        echo.New(),
 		db:          db,
 		redisClient: redisClient,
 	}
 }
 
+// newValidator request dto validator reporting fields by their json names
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
 // Run service
 func (s *Server) Run() error {
-	s.mw = middlewares.NewMiddlewareManager(s.logger, s.cfg)
-	im := interceptors.NewInterceptorManager(s.logger, s.cfg)
+	idempotencyRepo := idempotencyRepository.NewIdempotencyRepository(s.redisClient)
+	rateLimitRepo := rateLimitRepository.NewRateLimitRepository(s.redisClient)
 	userRepo := userRepository.NewUserPGRepository(s.db)
 	sessRepo := sessRepository.NewSessionRepository(s.redisClient, s.cfg)
 	userRedisRepo := userRepository.NewUserRedisRepo(s.redisClient, s.logger)
-	userUC := userUseCase.NewUserUseCase(s.cfg, s.logger, userRepo, userRedisRepo)
+	otpRepo := otpRepository.NewOTPRepository(s.redisClient)
+	deviceRepo := deviceRepository.NewDevicePGRepository(s.db)
+	accessTokenRepo := accessTokenRepository.NewAccessTokenPGRepository(s.db)
+	userUC := userUseCase.NewUserUseCase(s.cfg, s.logger, userRepo, userRedisRepo, sessRepo, mailer.NewMailer(s.cfg, s.logger), otpRepo, sms.NewSMSSender(s.cfg, s.logger), rateLimitRepo, deviceRepo, accessTokenRepo)
 	sessUC := sessUseCase.NewSessionUseCase(sessRepo, s.cfg)
+	serviceAccountUC := serviceAccountUseCase.NewServiceAccountUseCase(s.cfg, s.logger, serviceAccountRepository.NewServiceAccountPGRepository(s.db))
+	oauthUC := oauthUseCase.NewOAuthUseCase(s.cfg, s.logger, oauthRepository.NewOAuthClientPGRepository(s.db), oauthRepository.NewAuthorizationCodeRepository(s.redisClient), sessRepo)
+	s.mw = middlewares.NewMiddlewareManager(s.logger, s.cfg, idempotencyRepo, rateLimitRepo, sessUC, serviceAccountUC, userUC)
+	im := interceptors.NewInterceptorManager(s.logger, s.cfg, sessUC, serviceAccountUC, userUC)
 
 	l, err := net.Listen("tcp", s.cfg.Server.Port)
 	if err != nil {
@@ -79,6 +137,17 @@ func (s *Server) Run() error {
 		grpc.ChainUnaryInterceptor(
 			grpc_ctxtags.UnaryServerInterceptor(),
 			grpcrecovery.UnaryServerInterceptor(),
+			im.APIKeyAuth(apiKeyMethodScopes, oauthClientMethods...),
+			interceptors.ForMethods(
+				im.RequireRecentAuth(time.Duration(s.cfg.Session.ReauthMaxAge)*time.Second),
+				recentAuthMethods...,
+			),
+		),
+		grpc.StreamInterceptor(im.StreamLogger),
+		grpc.ChainStreamInterceptor(
+			grpc_ctxtags.StreamServerInterceptor(),
+			grpcrecovery.StreamServerInterceptor(),
+			im.StreamAPIKeyAuth(apiKeyMethodScopes, oauthClientMethods...),
 		),
 	)
 
@@ -88,31 +157,45 @@ func (s *Server) Run() error {
 
 	authGRPCServer := authServerGRPC.NewAuthServerGRPC(s.logger, s.cfg, userUC, sessUC)
 	userService.RegisterUserServiceServer(grpcS, authGRPCServer)
+	s.mapGrpcWeb(grpcS)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
 
 	userHandlers := userDeliveryHTTP.NewUserHandlersHTTP(s.echo.Group("user"), s.logger, s.cfg, s.mw, s.v, userUC, sessUC)
 	userHandlers.UserMapRoutes()
 
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
-	defer cancel()
+	serviceAccountHandlers := serviceAccountDeliveryHTTP.NewServiceAccountHandlersHTTP(s.echo.Group("service-accounts"), s.logger, s.cfg, s.mw, s.v, serviceAccountUC)
+	serviceAccountHandlers.ServiceAccountMapRoutes()
 
-	go func() {
-		s.logger.Infof("Server is listening on port: %v", s.cfg.Server.Port)
-		if err := grpcS.Serve(l); err != nil {
-			s.logger.Fatal(err)
-		}
-	}()
+	oauthHandlers := oauthDeliveryHTTP.NewOAuthHandlersHTTP(s.echo.Group("oauth"), s.logger, s.cfg, s.mw, s.v, oauthUC, userUC)
+	oauthHandlers.OAuthMapRoutes()
 
-	go func() {
-		if err := s.runHttpServer(nil); err != nil {
-			s.logger.Errorf("s.runHttpServer: %v", err)
-			cancel()
-		}
-	}()
+	if err := s.mapGateway(ctx); err != nil {
+		return err
+	}
 
-	//s.logger.Infof("Listening on %s\n", l.Addr().String())
-	//if err := m.Serve(); err != nil {
-	//	s.logger.Fatal(err)
-	//}
+	if s.cfg.Purge.Enabled {
+		go s.runUserPurge(ctx, userUC)
+	}
+
+	if s.cfg.Server.SinglePort {
+		s.serveMultiplexed(ctx, cancel, l, grpcS)
+	} else {
+		go func() {
+			s.logger.Infof("Server is listening on port: %v", s.cfg.Server.Port)
+			if err := grpcS.Serve(l); err != nil {
+				s.logger.Fatal(err)
+			}
+		}()
+
+		go func() {
+			if err := s.runHttpServer(nil); err != nil {
+				s.logger.Errorf("s.runHttpServer: %v", err)
+				cancel()
+			}
+		}()
+	}
 
 	<-ctx.Done()
 	grpcS.GracefulStop()
@@ -0,0 +1,33 @@
+package serviceaccount
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// AuthScheme authorization header scheme of API keys, "Authorization: ApiKey uja_..."
+const AuthScheme = "ApiKey"
+
+type apiKeyCtxKey struct{}
+
+// ParseAuthorization API key of an authorization header value, false when it uses another scheme
+func ParseAuthorization(header string) (string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], AuthScheme) {
+		return "", false
+	}
+	return strings.TrimSpace(parts[1]), true
+}
+
+// NewContext ctx carrying the API key the request authenticated with
+func NewContext(ctx context.Context, key *models.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyCtxKey{}, key)
+}
+
+// FromContext API key the request authenticated with, false when it did not use one
+func FromContext(ctx context.Context) (*models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyCtxKey{}).(*models.APIKey)
+	return key, ok && key != nil
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+type APIKeyCreateRequestDto struct {
+	Scopes    []string `json:"scopes" validate:"required,min=1,dive,oneof=users:read users:write"`
+	ExpiresIn int      `json:"expires_in" validate:"omitempty,gte=60"`
+}
+
+type APIKeyResponseDto struct {
+	KeyID            uuid.UUID  `json:"key_id"`
+	ServiceAccountID uuid.UUID  `json:"service_account_id"`
+	Prefix           string     `json:"prefix"`
+	Scopes           []string   `json:"scopes"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// APIKeyCreateResponseDto the key is only ever shown in this response
+type APIKeyCreateResponseDto struct {
+	Key    string             `json:"key"`
+	APIKey *APIKeyResponseDto `json:"api_key"`
+}
+
+type APIKeysResponseDto struct {
+	Keys []*APIKeyResponseDto `json:"keys"`
+}
+
+func APIKeyResponseFromModel(key *models.APIKey) *APIKeyResponseDto {
+	return &APIKeyResponseDto{
+		KeyID:            key.KeyID,
+		ServiceAccountID: key.ServiceAccountID,
+		Prefix:           key.Prefix,
+		Scopes:           key.Scopes,
+		ExpiresAt:        key.ExpiresAt,
+		LastUsedAt:       key.LastUsedAt,
+		RevokedAt:        key.RevokedAt,
+		CreatedAt:        key.CreatedAt,
+	}
+}
+
+func APIKeysResponseFromModels(keys []models.APIKey) *APIKeysResponseDto {
+	res := &APIKeysResponseDto{Keys: make([]*APIKeyResponseDto, 0, len(keys))}
+	for i := range keys {
+		res.Keys = append(res.Keys, APIKeyResponseFromModel(&keys[i]))
+	}
+	return res
+}
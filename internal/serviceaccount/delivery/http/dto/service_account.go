@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+type ServiceAccountCreateRequestDto struct {
+	Name        string `json:"name" validate:"required,lte=64"`
+	Description string `json:"description" validate:"lte=250"`
+}
+
+type ServiceAccountResponseDto struct {
+	ServiceAccountID uuid.UUID `json:"service_account_id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type ServiceAccountsResponseDto struct {
+	ServiceAccounts []*ServiceAccountResponseDto `json:"service_accounts"`
+}
+
+func ServiceAccountResponseFromModel(account *models.ServiceAccount) *ServiceAccountResponseDto {
+	return &ServiceAccountResponseDto{
+		ServiceAccountID: account.ServiceAccountID,
+		Name:             account.Name,
+		Description:      account.Description,
+		CreatedAt:        account.CreatedAt,
+		UpdatedAt:        account.UpdatedAt,
+	}
+}
+
+func ServiceAccountsResponseFromModels(accounts []models.ServiceAccount) *ServiceAccountsResponseDto {
+	res := &ServiceAccountsResponseDto{ServiceAccounts: make([]*ServiceAccountResponseDto, 0, len(accounts))}
+	for i := range accounts {
+		res.ServiceAccounts = append(res.ServiceAccounts, ServiceAccountResponseFromModel(&accounts[i]))
+	}
+	return res
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/middlewares"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	"github.com/dinorain/useraja/internal/serviceaccount/delivery/http/dto"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+type serviceAccountHandlersHTTP struct {
+	group            *echo.Group
+	logger           logger.Logger
+	cfg              *config.Config
+	mw               middlewares.MiddlewareManager
+	v                *validator.Validate
+	serviceAccountUC serviceaccount.ServiceAccountUseCase
+}
+
+var _ serviceaccount.ServiceAccountHandlers = (*serviceAccountHandlersHTTP)(nil)
+
+func NewServiceAccountHandlersHTTP(
+	group *echo.Group,
+	logger logger.Logger,
+	cfg *config.Config,
+	mw middlewares.MiddlewareManager,
+	v *validator.Validate,
+	serviceAccountUC serviceaccount.ServiceAccountUseCase,
+) *serviceAccountHandlersHTTP {
+	return &serviceAccountHandlersHTTP{group: group, logger: logger, cfg: cfg, mw: mw, v: v, serviceAccountUC: serviceAccountUC}
+}
+
+// Create
+// @Tags Service accounts
+// @Summary Create service account
+// @Description Admin create a service account, non human callers authenticate with its API keys
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body dto.ServiceAccountCreateRequestDto true "Payload"
+// @Success 201 {object} dto.ServiceAccountResponseDto
+// @Router /service-accounts [post]
+func (h *serviceAccountHandlersHTTP) Create() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		createDto := &dto.ServiceAccountCreateRequestDto{}
+		if err := c.Bind(createDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, createDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		createdAccount, err := h.serviceAccountUC.Create(ctx, &models.ServiceAccount{
+			Name:        createDto.Name,
+			Description: createDto.Description,
+		})
+		if err != nil {
+			h.logger.Errorf("serviceAccountUC.Create: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusCreated, dto.ServiceAccountResponseFromModel(createdAccount))
+	}
+}
+
+// FindAll
+// @Tags Service accounts
+// @Summary List service accounts
+// @Description Admin list every service account
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.ServiceAccountsResponseDto
+// @Router /service-accounts [get]
+func (h *serviceAccountHandlersHTTP) FindAll() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		accounts, err := h.serviceAccountUC.FindAll(c.Request().Context())
+		if err != nil {
+			h.logger.Errorf("serviceAccountUC.FindAll: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.ServiceAccountsResponseFromModels(accounts))
+	}
+}
+
+// CreateKey
+// @Tags Service accounts
+// @Summary Create API key
+// @Description Admin issue an API key with scopes to a service account, the key is only returned in this response.
+// @Description Callers send it as "Authorization: ApiKey <key>"
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Service account ID"
+// @Param payload body dto.APIKeyCreateRequestDto true "Payload"
+// @Success 201 {object} dto.APIKeyCreateResponseDto
+// @Router /service-accounts/{id}/keys [post]
+func (h *serviceAccountHandlersHTTP) CreateKey() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		serviceAccountID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		createDto := &dto.APIKeyCreateRequestDto{}
+		if err := c.Bind(createDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, createDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		key, createdKey, err := h.serviceAccountUC.CreateKey(ctx, serviceAccountID, createDto.Scopes, time.Duration(createDto.ExpiresIn)*time.Second)
+		if err != nil {
+			h.logger.Errorf("serviceAccountUC.CreateKey: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusCreated, dto.APIKeyCreateResponseDto{Key: key, APIKey: dto.APIKeyResponseFromModel(createdKey)})
+	}
+}
+
+// FindKeys
+// @Tags Service accounts
+// @Summary List API keys
+// @Description Admin list every key of a service account, including expired and revoked ones
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Service account ID"
+// @Success 200 {object} dto.APIKeysResponseDto
+// @Router /service-accounts/{id}/keys [get]
+func (h *serviceAccountHandlersHTTP) FindKeys() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		serviceAccountID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		keys, err := h.serviceAccountUC.FindKeys(c.Request().Context(), serviceAccountID)
+		if err != nil {
+			h.logger.Errorf("serviceAccountUC.FindKeys: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.APIKeysResponseFromModels(keys))
+	}
+}
+
+// RotateKey
+// @Tags Service accounts
+// @Summary Rotate API key
+// @Description Admin replace an active key with a new one of the same scopes, the old key keeps working for the configured grace period.
+// @Description The new key is only returned in this response
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Service account ID"
+// @Param key_id path string true "Key ID"
+// @Success 201 {object} dto.APIKeyCreateResponseDto
+// @Router /service-accounts/{id}/keys/{key_id}/rotate [post]
+func (h *serviceAccountHandlersHTTP) RotateKey() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		serviceAccountID, keyID, err := h.keyParams(c)
+		if err != nil {
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		key, createdKey, err := h.serviceAccountUC.RotateKey(c.Request().Context(), serviceAccountID, keyID)
+		if err != nil {
+			h.logger.Errorf("serviceAccountUC.RotateKey: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusCreated, dto.APIKeyCreateResponseDto{Key: key, APIKey: dto.APIKeyResponseFromModel(createdKey)})
+	}
+}
+
+// RevokeKey
+// @Tags Service accounts
+// @Summary Revoke API key
+// @Description Admin revoke a key of a service account, it is rejected immediately
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Service account ID"
+// @Param key_id path string true "Key ID"
+// @Success 204
+// @Router /service-accounts/{id}/keys/{key_id} [delete]
+func (h *serviceAccountHandlersHTTP) RevokeKey() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		serviceAccountID, keyID, err := h.keyParams(c)
+		if err != nil {
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.serviceAccountUC.RevokeKey(c.Request().Context(), serviceAccountID, keyID); err != nil {
+			h.logger.Errorf("serviceAccountUC.RevokeKey: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// keyParams service account and key ids of the request path
+func (h *serviceAccountHandlersHTTP) keyParams(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	serviceAccountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.logger.WarnMsg("uuid.FromString", err)
+		return uuid.Nil, uuid.Nil, domain_errors.InvalidField("id", err)
+	}
+
+	keyID, err := uuid.Parse(c.Param("key_id"))
+	if err != nil {
+		h.logger.WarnMsg("uuid.FromString", err)
+		return uuid.Nil, uuid.Nil, domain_errors.InvalidField("key_id", err)
+	}
+
+	return serviceAccountID, keyID, nil
+}
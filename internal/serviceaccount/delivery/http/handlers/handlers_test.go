@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/middlewares"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount/delivery/http/dto"
+	"github.com/dinorain/useraja/internal/serviceaccount/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestServiceAccountsHandler_CreateKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceAccountUC := mock.NewMockServiceAccountUseCase(ctrl)
+
+	appLogger := logger.NewAppLogger(nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, serviceAccountUC, nil)
+
+	e := echo.New()
+	v := validator.New()
+	cfg := &config.Config{}
+	handlers := NewServiceAccountHandlersHTTP(e.Group("service-accounts"), appLogger, cfg, mw, v, serviceAccountUC)
+
+	serviceAccountID := uuid.New()
+	serve := func(reqDto *dto.APIKeyCreateRequestDto) *httptest.ResponseRecorder {
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(reqDto)
+
+		req := httptest.NewRequest(http.MethodPost, "/service-accounts/"+serviceAccountID.String()+"/keys", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(serviceAccountID.String())
+		require.NoError(t, handlers.CreateKey()(ctx))
+		return res
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		keyID := uuid.New()
+		serviceAccountUC.EXPECT().CreateKey(gomock.Any(), serviceAccountID, []string{models.APIKeyScopeUsersRead}, gomock.Any()).
+			Return("uja_secret", &models.APIKey{KeyID: keyID, ServiceAccountID: serviceAccountID, Prefix: "uja_secret", KeyHash: "hash"}, nil)
+
+		res := serve(&dto.APIKeyCreateRequestDto{Scopes: []string{models.APIKeyScopeUsersRead}})
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.False(t, strings.Contains(res.Body.String(), "hash"))
+
+		resDto := &dto.APIKeyCreateResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), resDto))
+		require.Equal(t, "uja_secret", resDto.Key)
+		require.Equal(t, keyID, resDto.APIKey.KeyID)
+	})
+
+	t.Run("Unknown scope", func(t *testing.T) {
+		res := serve(&dto.APIKeyCreateRequestDto{Scopes: []string{"users:delete"}})
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Unknown service account", func(t *testing.T) {
+		serviceAccountUC.EXPECT().CreateKey(gomock.Any(), serviceAccountID, gomock.Any(), gomock.Any()).Return("", nil, domain_errors.ErrServiceAccountNotFound)
+
+		res := serve(&dto.APIKeyCreateRequestDto{Scopes: []string{models.APIKeyScopeUsersWrite}})
+		require.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
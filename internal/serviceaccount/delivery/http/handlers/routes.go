@@ -0,0 +1,11 @@
+package handlers
+
+func (h *serviceAccountHandlersHTTP) ServiceAccountMapRoutes() {
+	h.group.Use(h.mw.IsLoggedIn(), h.mw.IsAdmin)
+	h.group.POST("", h.Create())
+	h.group.GET("", h.FindAll())
+	h.group.POST("/:id/keys", h.CreateKey())
+	h.group.GET("/:id/keys", h.FindKeys())
+	h.group.POST("/:id/keys/:key_id/rotate", h.RotateKey())
+	h.group.DELETE("/:id/keys/:key_id", h.RevokeKey())
+}
@@ -0,0 +1,13 @@
+package serviceaccount
+
+import "github.com/labstack/echo/v4"
+
+// Service account HTTP Handlers interface
+type ServiceAccountHandlers interface {
+	Create() echo.HandlerFunc
+	FindAll() echo.HandlerFunc
+	CreateKey() echo.HandlerFunc
+	FindKeys() echo.HandlerFunc
+	RotateKey() echo.HandlerFunc
+	RevokeKey() echo.HandlerFunc
+}
@@ -0,0 +1,157 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pg_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/dinorain/useraja/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockServiceAccountPGRepository is a mock of ServiceAccountPGRepository interface.
+type MockServiceAccountPGRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceAccountPGRepositoryMockRecorder
+}
+
+// MockServiceAccountPGRepositoryMockRecorder is the mock recorder for MockServiceAccountPGRepository.
+type MockServiceAccountPGRepositoryMockRecorder struct {
+	mock *MockServiceAccountPGRepository
+}
+
+// NewMockServiceAccountPGRepository creates a new mock instance.
+func NewMockServiceAccountPGRepository(ctrl *gomock.Controller) *MockServiceAccountPGRepository {
+	mock := &MockServiceAccountPGRepository{ctrl: ctrl}
+	mock.recorder = &MockServiceAccountPGRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceAccountPGRepository) EXPECT() *MockServiceAccountPGRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockServiceAccountPGRepository) Authenticate(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, keyHash)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockServiceAccountPGRepositoryMockRecorder) Authenticate(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockServiceAccountPGRepository)(nil).Authenticate), ctx, keyHash)
+}
+
+// Create mocks base method.
+func (m *MockServiceAccountPGRepository) Create(ctx context.Context, account *models.ServiceAccount) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, account)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockServiceAccountPGRepositoryMockRecorder) Create(ctx, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockServiceAccountPGRepository)(nil).Create), ctx, account)
+}
+
+// CreateKey mocks base method.
+func (m *MockServiceAccountPGRepository) CreateKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, key)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockServiceAccountPGRepositoryMockRecorder) CreateKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockServiceAccountPGRepository)(nil).CreateKey), ctx, key)
+}
+
+// ExpireKey mocks base method.
+func (m *MockServiceAccountPGRepository) ExpireKey(ctx context.Context, serviceAccountID, keyID uuid.UUID, expiresAt time.Time) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireKey", ctx, serviceAccountID, keyID, expiresAt)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireKey indicates an expected call of ExpireKey.
+func (mr *MockServiceAccountPGRepositoryMockRecorder) ExpireKey(ctx, serviceAccountID, keyID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireKey", reflect.TypeOf((*MockServiceAccountPGRepository)(nil).ExpireKey), ctx, serviceAccountID, keyID, expiresAt)
+}
+
+// FindAll mocks base method.
+func (m *MockServiceAccountPGRepository) FindAll(ctx context.Context) ([]models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx)
+	ret0, _ := ret[0].([]models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockServiceAccountPGRepositoryMockRecorder) FindAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockServiceAccountPGRepository)(nil).FindAll), ctx)
+}
+
+// FindById mocks base method.
+func (m *MockServiceAccountPGRepository) FindById(ctx context.Context, serviceAccountID uuid.UUID) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, serviceAccountID)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockServiceAccountPGRepositoryMockRecorder) FindById(ctx, serviceAccountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockServiceAccountPGRepository)(nil).FindById), ctx, serviceAccountID)
+}
+
+// FindKeysByServiceAccountId mocks base method.
+func (m *MockServiceAccountPGRepository) FindKeysByServiceAccountId(ctx context.Context, serviceAccountID uuid.UUID) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKeysByServiceAccountId", ctx, serviceAccountID)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindKeysByServiceAccountId indicates an expected call of FindKeysByServiceAccountId.
+func (mr *MockServiceAccountPGRepositoryMockRecorder) FindKeysByServiceAccountId(ctx, serviceAccountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKeysByServiceAccountId", reflect.TypeOf((*MockServiceAccountPGRepository)(nil).FindKeysByServiceAccountId), ctx, serviceAccountID)
+}
+
+// RevokeKey mocks base method.
+func (m *MockServiceAccountPGRepository) RevokeKey(ctx context.Context, serviceAccountID, keyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, serviceAccountID, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockServiceAccountPGRepositoryMockRecorder) RevokeKey(ctx, serviceAccountID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockServiceAccountPGRepository)(nil).RevokeKey), ctx, serviceAccountID, keyID)
+}
@@ -0,0 +1,159 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/dinorain/useraja/internal/models"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockServiceAccountUseCase is a mock of ServiceAccountUseCase interface.
+type MockServiceAccountUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceAccountUseCaseMockRecorder
+}
+
+// MockServiceAccountUseCaseMockRecorder is the mock recorder for MockServiceAccountUseCase.
+type MockServiceAccountUseCaseMockRecorder struct {
+	mock *MockServiceAccountUseCase
+}
+
+// NewMockServiceAccountUseCase creates a new mock instance.
+func NewMockServiceAccountUseCase(ctrl *gomock.Controller) *MockServiceAccountUseCase {
+	mock := &MockServiceAccountUseCase{ctrl: ctrl}
+	mock.recorder = &MockServiceAccountUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceAccountUseCase) EXPECT() *MockServiceAccountUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockServiceAccountUseCase) Authenticate(ctx context.Context, key string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, key)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockServiceAccountUseCaseMockRecorder) Authenticate(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockServiceAccountUseCase)(nil).Authenticate), ctx, key)
+}
+
+// Create mocks base method.
+func (m *MockServiceAccountUseCase) Create(ctx context.Context, account *models.ServiceAccount) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, account)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockServiceAccountUseCaseMockRecorder) Create(ctx, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockServiceAccountUseCase)(nil).Create), ctx, account)
+}
+
+// CreateKey mocks base method.
+func (m *MockServiceAccountUseCase) CreateKey(ctx context.Context, serviceAccountID uuid.UUID, scopes []string, expire time.Duration) (string, *models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, serviceAccountID, scopes, expire)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*models.APIKey)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockServiceAccountUseCaseMockRecorder) CreateKey(ctx, serviceAccountID, scopes, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockServiceAccountUseCase)(nil).CreateKey), ctx, serviceAccountID, scopes, expire)
+}
+
+// FindAll mocks base method.
+func (m *MockServiceAccountUseCase) FindAll(ctx context.Context) ([]models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", ctx)
+	ret0, _ := ret[0].([]models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockServiceAccountUseCaseMockRecorder) FindAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockServiceAccountUseCase)(nil).FindAll), ctx)
+}
+
+// FindById mocks base method.
+func (m *MockServiceAccountUseCase) FindById(ctx context.Context, serviceAccountID uuid.UUID) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, serviceAccountID)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockServiceAccountUseCaseMockRecorder) FindById(ctx, serviceAccountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockServiceAccountUseCase)(nil).FindById), ctx, serviceAccountID)
+}
+
+// FindKeys mocks base method.
+func (m *MockServiceAccountUseCase) FindKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKeys", ctx, serviceAccountID)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindKeys indicates an expected call of FindKeys.
+func (mr *MockServiceAccountUseCaseMockRecorder) FindKeys(ctx, serviceAccountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKeys", reflect.TypeOf((*MockServiceAccountUseCase)(nil).FindKeys), ctx, serviceAccountID)
+}
+
+// RevokeKey mocks base method.
+func (m *MockServiceAccountUseCase) RevokeKey(ctx context.Context, serviceAccountID, keyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, serviceAccountID, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockServiceAccountUseCaseMockRecorder) RevokeKey(ctx, serviceAccountID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockServiceAccountUseCase)(nil).RevokeKey), ctx, serviceAccountID, keyID)
+}
+
+// RotateKey mocks base method.
+func (m *MockServiceAccountUseCase) RotateKey(ctx context.Context, serviceAccountID, keyID uuid.UUID) (string, *models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateKey", ctx, serviceAccountID, keyID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*models.APIKey)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RotateKey indicates an expected call of RotateKey.
+func (mr *MockServiceAccountUseCaseMockRecorder) RotateKey(ctx, serviceAccountID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateKey", reflect.TypeOf((*MockServiceAccountUseCase)(nil).RotateKey), ctx, serviceAccountID, keyID)
+}
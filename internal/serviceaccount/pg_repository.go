@@ -0,0 +1,23 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository.go -package mock
+package serviceaccount
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// Service account Postgresql repository
+type ServiceAccountPGRepository interface {
+	Create(ctx context.Context, account *models.ServiceAccount) (*models.ServiceAccount, error)
+	FindAll(ctx context.Context) ([]models.ServiceAccount, error)
+	FindById(ctx context.Context, serviceAccountID uuid.UUID) (*models.ServiceAccount, error)
+	CreateKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error)
+	FindKeysByServiceAccountId(ctx context.Context, serviceAccountID uuid.UUID) ([]models.APIKey, error)
+	ExpireKey(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID, expiresAt time.Time) (*models.APIKey, error)
+	RevokeKey(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID) error
+	Authenticate(ctx context.Context, keyHash string) (*models.APIKey, error)
+}
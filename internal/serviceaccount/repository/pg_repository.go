@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+)
+
+// Service account repository
+type ServiceAccountRepository struct {
+	db *sqlx.DB
+}
+
+var _ serviceaccount.ServiceAccountPGRepository = (*ServiceAccountRepository)(nil)
+
+// Service account repository constructor
+func NewServiceAccountPGRepository(db *sqlx.DB) *ServiceAccountRepository {
+	return &ServiceAccountRepository{db: db}
+}
+
+// Create new service account
+func (r *ServiceAccountRepository) Create(ctx context.Context, account *models.ServiceAccount) (*models.ServiceAccount, error) {
+	createdAccount := &models.ServiceAccount{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createServiceAccountQuery,
+		account.Name,
+		account.Description,
+	).StructScan(createdAccount); err != nil {
+		return nil, errors.Wrap(err, "ServiceAccountRepository.Create.QueryRowxContext")
+	}
+
+	return createdAccount, nil
+}
+
+// FindAll Find every service account by name
+func (r *ServiceAccountRepository) FindAll(ctx context.Context) ([]models.ServiceAccount, error) {
+	var accounts []models.ServiceAccount
+	if err := r.db.SelectContext(ctx, &accounts, findAllServiceAccountsQuery); err != nil {
+		return nil, errors.Wrap(err, "ServiceAccountRepository.FindAll.SelectContext")
+	}
+
+	return accounts, nil
+}
+
+// FindById Find service account by id
+func (r *ServiceAccountRepository) FindById(ctx context.Context, serviceAccountID uuid.UUID) (*models.ServiceAccount, error) {
+	account := &models.ServiceAccount{}
+	if err := r.db.GetContext(ctx, account, findServiceAccountByIdQuery, serviceAccountID); err != nil {
+		return nil, errors.Wrap(err, "ServiceAccountRepository.FindById.GetContext")
+	}
+
+	return account, nil
+}
+
+// CreateKey Create new API key of a service account
+func (r *ServiceAccountRepository) CreateKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	createdKey := &models.APIKey{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		createKeyQuery,
+		key.ServiceAccountID,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes,
+		key.ExpiresAt,
+	).StructScan(createdKey); err != nil {
+		return nil, errors.Wrap(err, "ServiceAccountRepository.CreateKey.QueryRowxContext")
+	}
+
+	return createdKey, nil
+}
+
+// FindKeysByServiceAccountId Find every key of a service account, newest first
+func (r *ServiceAccountRepository) FindKeysByServiceAccountId(ctx context.Context, serviceAccountID uuid.UUID) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.SelectContext(ctx, &keys, findKeysByServiceAccountIdQuery, serviceAccountID); err != nil {
+		return nil, errors.Wrap(err, "ServiceAccountRepository.FindKeysByServiceAccountId.SelectContext")
+	}
+
+	return keys, nil
+}
+
+// ExpireKey Make an active key of a service account expire no later than expiresAt, sql.ErrNoRows when there is none
+func (r *ServiceAccountRepository) ExpireKey(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID, expiresAt time.Time) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	if err := r.db.QueryRowxContext(ctx, expireKeyQuery, serviceAccountID, keyID, expiresAt).StructScan(key); err != nil {
+		return nil, errors.Wrap(err, "ServiceAccountRepository.ExpireKey.QueryRowxContext")
+	}
+
+	return key, nil
+}
+
+// RevokeKey Revoke a key of a service account, sql.ErrNoRows when it has no such unrevoked key
+func (r *ServiceAccountRepository) RevokeKey(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, revokeKeyQuery, serviceAccountID, keyID)
+	if err != nil {
+		return errors.Wrap(err, "ServiceAccountRepository.RevokeKey.ExecContext")
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "ServiceAccountRepository.RevokeKey.RowsAffected")
+	} else if cnt == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Authenticate Find the active key with the hash and record it was used now, sql.ErrNoRows when there is none
+func (r *ServiceAccountRepository) Authenticate(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	if err := r.db.QueryRowxContext(ctx, authenticateKeyQuery, keyHash).StructScan(key); err != nil {
+		return nil, errors.Wrap(err, "ServiceAccountRepository.Authenticate.QueryRowxContext")
+	}
+
+	return key, nil
+}
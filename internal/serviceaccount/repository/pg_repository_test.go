@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+var keyColumns = []string{"key_id", "service_account_id", "prefix", "key_hash", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at"}
+
+func TestServiceAccountRepository_Create(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	serviceAccountPGRepository := NewServiceAccountPGRepository(sqlxDB)
+
+	serviceAccountID := uuid.New()
+	mock.ExpectQuery(createServiceAccountQuery).WithArgs("billing-sync", "Nightly billing export").WillReturnRows(
+		sqlmock.NewRows([]string{"service_account_id", "name", "description", "created_at", "updated_at"}).
+			AddRow(serviceAccountID, "billing-sync", "Nightly billing export", time.Now(), time.Now()),
+	)
+
+	createdAccount, err := serviceAccountPGRepository.Create(context.Background(), &models.ServiceAccount{Name: "billing-sync", Description: "Nightly billing export"})
+	require.NoError(t, err)
+	require.Equal(t, serviceAccountID, createdAccount.ServiceAccountID)
+}
+
+func TestServiceAccountRepository_CreateKey(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	serviceAccountPGRepository := NewServiceAccountPGRepository(sqlxDB)
+
+	expiresAt := time.Now().Add(time.Hour)
+	mockKey := &models.APIKey{
+		ServiceAccountID: uuid.New(),
+		Prefix:           "uja_abcdefgh",
+		KeyHash:          "hash",
+		Scopes:           pq.StringArray{models.APIKeyScopeUsersRead},
+		ExpiresAt:        &expiresAt,
+	}
+
+	keyID := uuid.New()
+	mock.ExpectQuery(createKeyQuery).
+		WithArgs(mockKey.ServiceAccountID, mockKey.Prefix, mockKey.KeyHash, mockKey.Scopes, mockKey.ExpiresAt).
+		WillReturnRows(sqlmock.NewRows(keyColumns).AddRow(keyID, mockKey.ServiceAccountID, mockKey.Prefix, mockKey.KeyHash, "{users:read}", expiresAt, nil, nil, time.Now()))
+
+	createdKey, err := serviceAccountPGRepository.CreateKey(context.Background(), mockKey)
+	require.NoError(t, err)
+	require.Equal(t, keyID, createdKey.KeyID)
+	require.Equal(t, pq.StringArray{models.APIKeyScopeUsersRead}, createdKey.Scopes)
+	require.Nil(t, createdKey.LastUsedAt)
+}
+
+func TestServiceAccountRepository_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	serviceAccountPGRepository := NewServiceAccountPGRepository(sqlxDB)
+
+	keyID := uuid.New()
+	mock.ExpectQuery(authenticateKeyQuery).WithArgs("hash").WillReturnRows(
+		sqlmock.NewRows(keyColumns).AddRow(keyID, uuid.New(), "uja_abcdefgh", "hash", "{users:read,users:write}", nil, time.Now(), nil, time.Now()),
+	)
+	key, err := serviceAccountPGRepository.Authenticate(context.Background(), "hash")
+	require.NoError(t, err)
+	require.Equal(t, keyID, key.KeyID)
+	require.True(t, key.HasScope(models.APIKeyScopeUsersWrite))
+	require.NotNil(t, key.LastUsedAt)
+
+	mock.ExpectQuery(authenticateKeyQuery).WithArgs("revoked").WillReturnRows(sqlmock.NewRows(keyColumns))
+	_, err = serviceAccountPGRepository.Authenticate(context.Background(), "revoked")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestServiceAccountRepository_RevokeKey(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	serviceAccountPGRepository := NewServiceAccountPGRepository(sqlxDB)
+
+	serviceAccountID := uuid.New()
+	keyID := uuid.New()
+
+	mock.ExpectExec(revokeKeyQuery).WithArgs(serviceAccountID, keyID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, serviceAccountPGRepository.RevokeKey(context.Background(), serviceAccountID, keyID))
+
+	mock.ExpectExec(revokeKeyQuery).WithArgs(serviceAccountID, keyID).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.ErrorIs(t, serviceAccountPGRepository.RevokeKey(context.Background(), serviceAccountID, keyID), sql.ErrNoRows)
+}
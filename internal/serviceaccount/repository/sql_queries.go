@@ -0,0 +1,31 @@
+package repository
+
+const (
+	createServiceAccountQuery = `INSERT INTO service_accounts (name, description) VALUES ($1, $2)
+		RETURNING service_account_id, name, description, created_at, updated_at`
+
+	findAllServiceAccountsQuery = `SELECT service_account_id, name, description, created_at, updated_at
+		FROM service_accounts ORDER BY name`
+
+	findServiceAccountByIdQuery = `SELECT service_account_id, name, description, created_at, updated_at
+		FROM service_accounts WHERE service_account_id = $1`
+
+	createKeyQuery = `INSERT INTO api_keys (service_account_id, prefix, key_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5)
+		RETURNING key_id, service_account_id, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+	findKeysByServiceAccountIdQuery = `SELECT key_id, service_account_id, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys WHERE service_account_id = $1 ORDER BY created_at DESC`
+
+	// expireKeyQuery only ever brings the expiry of an active key forward
+	expireKeyQuery = `UPDATE api_keys SET expires_at = LEAST(COALESCE(expires_at, $3), $3)
+		WHERE service_account_id = $1 AND key_id = $2 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING key_id, service_account_id, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+	revokeKeyQuery = `UPDATE api_keys SET revoked_at = NOW()
+		WHERE service_account_id = $1 AND key_id = $2 AND revoked_at IS NULL`
+
+	// authenticateKeyQuery records a use of an active key, no row when the key is unknown, revoked or expired
+	authenticateKeyQuery = `UPDATE api_keys SET last_used_at = NOW()
+		WHERE key_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING key_id, service_account_id, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+)
@@ -0,0 +1,23 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase.go -package mock
+package serviceaccount
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// Service account UseCase
+type ServiceAccountUseCase interface {
+	Create(ctx context.Context, account *models.ServiceAccount) (*models.ServiceAccount, error)
+	FindAll(ctx context.Context) ([]models.ServiceAccount, error)
+	FindById(ctx context.Context, serviceAccountID uuid.UUID) (*models.ServiceAccount, error)
+	CreateKey(ctx context.Context, serviceAccountID uuid.UUID, scopes []string, expire time.Duration) (string, *models.APIKey, error)
+	FindKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]models.APIKey, error)
+	RotateKey(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID) (string, *models.APIKey, error)
+	RevokeKey(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID) error
+	Authenticate(ctx context.Context, key string) (*models.APIKey, error)
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/utils"
+)
+
+const uniqueViolation = "23505"
+
+// Service account use case
+type serviceAccountUseCase struct {
+	cfg                *config.Config
+	logger             logger.Logger
+	serviceAccountRepo serviceaccount.ServiceAccountPGRepository
+}
+
+var _ serviceaccount.ServiceAccountUseCase = (*serviceAccountUseCase)(nil)
+
+// New service account use case constructor
+func NewServiceAccountUseCase(cfg *config.Config, logger logger.Logger, serviceAccountRepo serviceaccount.ServiceAccountPGRepository) *serviceAccountUseCase {
+	return &serviceAccountUseCase{cfg: cfg, logger: logger, serviceAccountRepo: serviceAccountRepo}
+}
+
+// Create new service account
+func (u *serviceAccountUseCase) Create(ctx context.Context, account *models.ServiceAccount) (*models.ServiceAccount, error) {
+	createdAccount, err := u.serviceAccountRepo.Create(ctx, account)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return nil, domain_errors.ErrServiceAccountExists.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "serviceAccountRepo.Create")
+	}
+
+	return createdAccount, nil
+}
+
+// FindAll find every service account
+func (u *serviceAccountUseCase) FindAll(ctx context.Context) ([]models.ServiceAccount, error) {
+	accounts, err := u.serviceAccountRepo.FindAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "serviceAccountRepo.FindAll")
+	}
+
+	return accounts, nil
+}
+
+// FindById find service account by id
+func (u *serviceAccountUseCase) FindById(ctx context.Context, serviceAccountID uuid.UUID) (*models.ServiceAccount, error) {
+	account, err := u.serviceAccountRepo.FindById(ctx, serviceAccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrServiceAccountNotFound.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "serviceAccountRepo.FindById")
+	}
+
+	return account, nil
+}
+
+// CreateKey issue a new key with scopes to the service account, expiring after expire or the configured default when 0.
+// The plaintext key is only ever returned here
+func (u *serviceAccountUseCase) CreateKey(ctx context.Context, serviceAccountID uuid.UUID, scopes []string, expire time.Duration) (string, *models.APIKey, error) {
+	if _, err := u.FindById(ctx, serviceAccountID); err != nil {
+		return "", nil, err
+	}
+
+	return u.createKey(ctx, serviceAccountID, scopes, expire)
+}
+
+// FindKeys find every key of the service account, including expired and revoked ones
+func (u *serviceAccountUseCase) FindKeys(ctx context.Context, serviceAccountID uuid.UUID) ([]models.APIKey, error) {
+	if _, err := u.FindById(ctx, serviceAccountID); err != nil {
+		return nil, err
+	}
+
+	keys, err := u.serviceAccountRepo.FindKeysByServiceAccountId(ctx, serviceAccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "serviceAccountRepo.FindKeysByServiceAccountId")
+	}
+
+	return keys, nil
+}
+
+// RotateKey issue a new key with the scopes of an active key, which keeps working for the configured grace period
+// so callers can switch over
+func (u *serviceAccountUseCase) RotateKey(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID) (string, *models.APIKey, error) {
+	grace := time.Duration(u.cfg.APIKey.RotationGrace) * time.Second
+	oldKey, err := u.serviceAccountRepo.ExpireKey(ctx, serviceAccountID, keyID, time.Now().Add(grace))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, domain_errors.ErrAPIKeyNotFound.Wrap(err)
+		}
+		return "", nil, errors.Wrap(err, "serviceAccountRepo.ExpireKey")
+	}
+
+	return u.createKey(ctx, serviceAccountID, oldKey.Scopes, 0)
+}
+
+// RevokeKey stop accepting a key of the service account immediately
+func (u *serviceAccountUseCase) RevokeKey(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID) error {
+	if err := u.serviceAccountRepo.RevokeKey(ctx, serviceAccountID, keyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain_errors.ErrAPIKeyNotFound.Wrap(err)
+		}
+		return errors.Wrap(err, "serviceAccountRepo.RevokeKey")
+	}
+
+	return nil
+}
+
+// Authenticate find the active key and record it was used now, domain_errors.ErrInvalidAPIKey when it is unknown,
+// revoked or expired
+func (u *serviceAccountUseCase) Authenticate(ctx context.Context, key string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, models.APIKeyPrefix) {
+		return nil, domain_errors.ErrInvalidAPIKey
+	}
+
+	foundKey, err := u.serviceAccountRepo.Authenticate(ctx, utils.HashToken(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidAPIKey.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "serviceAccountRepo.Authenticate")
+	}
+
+	return foundKey, nil
+}
+
+func (u *serviceAccountUseCase) createKey(ctx context.Context, serviceAccountID uuid.UUID, scopes []string, expire time.Duration) (string, *models.APIKey, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "utils.GenerateToken")
+	}
+	key := models.APIKeyPrefix + token
+
+	if expire == 0 {
+		expire = time.Duration(u.cfg.APIKey.Expire) * time.Second
+	}
+	var expiresAt *time.Time
+	if expire > 0 {
+		at := time.Now().Add(expire)
+		expiresAt = &at
+	}
+
+	createdKey, err := u.serviceAccountRepo.CreateKey(ctx, &models.APIKey{
+		ServiceAccountID: serviceAccountID,
+		Prefix:           key[:models.APIKeyDisplayLength],
+		KeyHash:          utils.HashToken(key),
+		Scopes:           scopes,
+		ExpiresAt:        expiresAt,
+	})
+	if err != nil {
+		return "", nil, errors.Wrap(err, "serviceAccountRepo.CreateKey")
+	}
+
+	return key, createdKey, nil
+}
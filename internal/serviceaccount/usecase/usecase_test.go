@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/utils"
+)
+
+func TestServiceAccountUseCase_Create(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceAccountRepository := mock.NewMockServiceAccountPGRepository(ctrl)
+	serviceAccountUC := NewServiceAccountUseCase(&config.Config{}, logger.NewAppLogger(nil), serviceAccountRepository)
+
+	account := &models.ServiceAccount{Name: "billing-sync"}
+	serviceAccountRepository.EXPECT().Create(gomock.Any(), account).Return(nil, &pq.Error{Code: uniqueViolation})
+
+	_, err := serviceAccountUC.Create(context.Background(), account)
+	require.ErrorIs(t, err, domain_errors.ErrServiceAccountExists)
+}
+
+func TestServiceAccountUseCase_Keys(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceAccountRepository := mock.NewMockServiceAccountPGRepository(ctrl)
+	cfg := &config.Config{APIKey: config.APIKey{Expire: 3600, RotationGrace: 600}}
+	serviceAccountUC := NewServiceAccountUseCase(cfg, logger.NewAppLogger(nil), serviceAccountRepository)
+
+	serviceAccountID := uuid.New()
+	keyID := uuid.New()
+	ctx := context.Background()
+	scopes := []string{models.APIKeyScopeUsersRead}
+
+	var key string
+	t.Run("Create", func(t *testing.T) {
+		serviceAccountRepository.EXPECT().FindById(gomock.Any(), serviceAccountID).Return(&models.ServiceAccount{ServiceAccountID: serviceAccountID}, nil)
+		serviceAccountRepository.EXPECT().CreateKey(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+			require.Equal(t, serviceAccountID, apiKey.ServiceAccountID)
+			require.Equal(t, pq.StringArray(scopes), apiKey.Scopes)
+			require.Len(t, apiKey.Prefix, models.APIKeyDisplayLength)
+			require.WithinDuration(t, time.Now().Add(time.Hour), *apiKey.ExpiresAt, time.Minute)
+			apiKey.KeyID = keyID
+			return apiKey, nil
+		})
+
+		created, apiKey, err := serviceAccountUC.CreateKey(ctx, serviceAccountID, scopes, 0)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(created, models.APIKeyPrefix))
+		require.True(t, strings.HasPrefix(created, apiKey.Prefix))
+		require.Equal(t, utils.HashToken(created), apiKey.KeyHash)
+		key = created
+	})
+
+	t.Run("Create for unknown account", func(t *testing.T) {
+		serviceAccountRepository.EXPECT().FindById(gomock.Any(), serviceAccountID).Return(nil, sql.ErrNoRows)
+
+		_, _, err := serviceAccountUC.CreateKey(ctx, serviceAccountID, scopes, 0)
+		require.ErrorIs(t, err, domain_errors.ErrServiceAccountNotFound)
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		serviceAccountRepository.EXPECT().Authenticate(gomock.Any(), utils.HashToken(key)).Return(&models.APIKey{KeyID: keyID}, nil)
+
+		apiKey, err := serviceAccountUC.Authenticate(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, keyID, apiKey.KeyID)
+	})
+
+	t.Run("Authenticate revoked", func(t *testing.T) {
+		serviceAccountRepository.EXPECT().Authenticate(gomock.Any(), utils.HashToken(key)).Return(nil, sql.ErrNoRows)
+
+		_, err := serviceAccountUC.Authenticate(ctx, key)
+		require.ErrorIs(t, err, domain_errors.ErrInvalidAPIKey)
+	})
+
+	t.Run("Authenticate without prefix", func(t *testing.T) {
+		_, err := serviceAccountUC.Authenticate(ctx, "not-a-key")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidAPIKey)
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		serviceAccountRepository.EXPECT().ExpireKey(gomock.Any(), serviceAccountID, keyID, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, serviceAccountID uuid.UUID, keyID uuid.UUID, expiresAt time.Time) (*models.APIKey, error) {
+				require.WithinDuration(t, time.Now().Add(10*time.Minute), expiresAt, time.Minute)
+				return &models.APIKey{KeyID: keyID, ServiceAccountID: serviceAccountID, Scopes: scopes}, nil
+			})
+		serviceAccountRepository.EXPECT().CreateKey(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+			require.Equal(t, pq.StringArray(scopes), apiKey.Scopes)
+			apiKey.KeyID = uuid.New()
+			return apiKey, nil
+		})
+
+		rotated, apiKey, err := serviceAccountUC.RotateKey(ctx, serviceAccountID, keyID)
+		require.NoError(t, err)
+		require.NotEqual(t, key, rotated)
+		require.NotEqual(t, keyID, apiKey.KeyID)
+	})
+
+	t.Run("Revoke unknown", func(t *testing.T) {
+		serviceAccountRepository.EXPECT().RevokeKey(gomock.Any(), serviceAccountID, keyID).Return(sql.ErrNoRows)
+
+		err := serviceAccountUC.RevokeKey(ctx, serviceAccountID, keyID)
+		require.ErrorIs(t, err, domain_errors.ErrAPIKeyNotFound)
+	})
+}
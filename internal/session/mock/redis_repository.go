@@ -10,6 +10,7 @@ import (
 
 	models "github.com/dinorain/useraja/internal/models"
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 )
 
 // MockSessRepository is a mock of SessRepository interface.
@@ -50,6 +51,20 @@ func (mr *MockSessRepositoryMockRecorder) CreateSession(ctx, session, expire int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessRepository)(nil).CreateSession), ctx, session, expire)
 }
 
+// DeleteByClientId mocks base method.
+func (m *MockSessRepository) DeleteByClientId(ctx context.Context, clientID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByClientId", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByClientId indicates an expected call of DeleteByClientId.
+func (mr *MockSessRepositoryMockRecorder) DeleteByClientId(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByClientId", reflect.TypeOf((*MockSessRepository)(nil).DeleteByClientId), ctx, clientID)
+}
+
 // DeleteById mocks base method.
 func (m *MockSessRepository) DeleteById(ctx context.Context, sessionID string) error {
 	m.ctrl.T.Helper()
@@ -64,6 +79,20 @@ func (mr *MockSessRepositoryMockRecorder) DeleteById(ctx, sessionID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockSessRepository)(nil).DeleteById), ctx, sessionID)
 }
 
+// DeleteByUserId mocks base method.
+func (m *MockSessRepository) DeleteByUserId(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserId", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserId indicates an expected call of DeleteByUserId.
+func (mr *MockSessRepositoryMockRecorder) DeleteByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserId", reflect.TypeOf((*MockSessRepository)(nil).DeleteByUserId), ctx, userID)
+}
+
 // GetSessionById mocks base method.
 func (m *MockSessRepository) GetSessionById(ctx context.Context, sessionID string) (*models.Session, error) {
 	m.ctrl.T.Helper()
@@ -78,3 +107,17 @@ func (mr *MockSessRepositoryMockRecorder) GetSessionById(ctx, sessionID interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionById", reflect.TypeOf((*MockSessRepository)(nil).GetSessionById), ctx, sessionID)
 }
+
+// UpdateSession mocks base method.
+func (m *MockSessRepository) UpdateSession(ctx context.Context, session *models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSession", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSession indicates an expected call of UpdateSession.
+func (mr *MockSessRepositoryMockRecorder) UpdateSession(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockSessRepository)(nil).UpdateSession), ctx, session)
+}
@@ -4,6 +4,8 @@ package session
 import (
 	"context"
 
+	"github.com/google/uuid"
+
 	"github.com/dinorain/useraja/internal/models"
 )
 
@@ -11,5 +13,8 @@ import (
 type SessRepository interface {
 	CreateSession(ctx context.Context, session *models.Session, expire int) (string, error)
 	GetSessionById(ctx context.Context, sessionID string) (*models.Session, error)
+	UpdateSession(ctx context.Context, session *models.Session) error
 	DeleteById(ctx context.Context, sessionID string) error
+	DeleteByUserId(ctx context.Context, userID uuid.UUID) error
+	DeleteByClientId(ctx context.Context, clientID uuid.UUID) error
 }
@@ -19,6 +19,16 @@ const (
 	basePrefix = "sessions:"
 )
 
+// updateScript overwrites a session with its remaining expiry in one step, so a session that expires or is revoked
+// meanwhile is not brought back outside of the user and client indexes. Returns nil when the session is gone
+var updateScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl <= 0 then
+	return false
+end
+return redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+`)
+
 // Session repository
 type sessionRepo struct {
 	redisClient *redis.Client
@@ -42,8 +52,20 @@ func (s *sessionRepo) CreateSession(ctx context.Context, sess *models.Session, e
 	if err != nil {
 		return "", errors.WithMessage(err, "sessionRepo.CreateSession.json.Marshal")
 	}
-	if err = s.redisClient.Set(ctx, sessionKey, sessBytes, time.Second*time.Duration(expire)).Err(); err != nil {
-		return "", errors.Wrap(err, "sessionRepo.CreateSession.redisClient.Set")
+	// the user and client indexes live as long as their newest session so revocation reaches every live session
+	userKey := s.generateUserKey(sess.UserID)
+	if _, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey, sessBytes, time.Second*time.Duration(expire))
+		pipe.SAdd(ctx, userKey, sess.SessionID)
+		pipe.Expire(ctx, userKey, time.Second*time.Duration(expire))
+		if sess.ClientID != "" {
+			clientKey := s.generateClientKey(sess.ClientID)
+			pipe.SAdd(ctx, clientKey, sess.SessionID)
+			pipe.Expire(ctx, clientKey, time.Second*time.Duration(expire))
+		}
+		return nil
+	}); err != nil {
+		return "", errors.Wrap(err, "sessionRepo.CreateSession.redisClient.TxPipelined")
 	}
 	return sess.SessionID, nil
 }
@@ -62,6 +84,18 @@ func (s *sessionRepo) GetSessionById(ctx context.Context, sessionID string) (*mo
 	return sess, nil
 }
 
+// Update session keeping its expiry, redis.Nil when the session expired
+func (s *sessionRepo) UpdateSession(ctx context.Context, sess *models.Session) error {
+	sessBytes, err := json.Marshal(&sess)
+	if err != nil {
+		return errors.WithMessage(err, "sessionRepo.UpdateSession.json.Marshal")
+	}
+	if err := updateScript.Run(ctx, s.redisClient, []string{s.generateKey(sess.SessionID)}, sessBytes).Err(); err != nil {
+		return errors.Wrap(err, "sessionRepo.UpdateSession.updateScript.Run")
+	}
+	return nil
+}
+
 // Delete session by id
 func (s *sessionRepo) DeleteById(ctx context.Context, sessionID string) error {
 	if err := s.redisClient.Del(ctx, s.generateKey(sessionID)).Err(); err != nil {
@@ -70,6 +104,54 @@ func (s *sessionRepo) DeleteById(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+// Delete every session of the user
+func (s *sessionRepo) DeleteByUserId(ctx context.Context, userID uuid.UUID) error {
+	userKey := s.generateUserKey(userID)
+	sessionIDs, err := s.redisClient.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteByUserId.redisClient.SMembers")
+	}
+
+	keys := make([]string, 0, len(sessionIDs)+1)
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, s.generateKey(sessionID))
+	}
+	keys = append(keys, userKey)
+
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteByUserId.redisClient.Del")
+	}
+	return nil
+}
+
+// Delete every session of the OAuth client
+func (s *sessionRepo) DeleteByClientId(ctx context.Context, clientID uuid.UUID) error {
+	clientKey := s.generateClientKey(clientID.String())
+	sessionIDs, err := s.redisClient.SMembers(ctx, clientKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteByClientId.redisClient.SMembers")
+	}
+
+	keys := make([]string, 0, len(sessionIDs)+1)
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, s.generateKey(sessionID))
+	}
+	keys = append(keys, clientKey)
+
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteByClientId.redisClient.Del")
+	}
+	return nil
+}
+
 func (s *sessionRepo) generateKey(sessionID string) string {
 	return fmt.Sprintf("%s: %s", s.basePrefix, sessionID)
 }
+
+func (s *sessionRepo) generateUserKey(userID uuid.UUID) string {
+	return fmt.Sprintf("%suser: %s", s.basePrefix, userID.String())
+}
+
+func (s *sessionRepo) generateClientKey(clientID string) string {
+	return fmt.Sprintf("%sclient: %s", s.basePrefix, clientID)
+}
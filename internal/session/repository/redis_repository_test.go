@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis"
 	"github.com/go-redis/redis/v8"
@@ -65,6 +66,50 @@ func TestGetSessionById(t *testing.T) {
 	})
 }
 
+func TestUpdateSession(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("UpdateSession", func(t *testing.T) {
+		sess := &models.Session{UserID: uuid.New()}
+		sessionID, err := sessRepository.CreateSession(context.Background(), sess, 10)
+		require.NoError(t, err)
+
+		authTime := time.Now().Truncate(time.Second)
+		err = sessRepository.UpdateSession(context.Background(), &models.Session{SessionID: sessionID, UserID: sess.UserID, AuthTime: authTime, ACR: models.SessionACRStepUp})
+		require.NoError(t, err)
+
+		s, err := sessRepository.GetSessionById(context.Background(), sessionID)
+		require.NoError(t, err)
+		require.Equal(t, models.SessionACRStepUp, s.ACR)
+		require.True(t, authTime.Equal(s.AuthTime))
+
+		redisClient := sessRepository.(*sessionRepo).redisClient
+		ttl, err := redisClient.TTL(context.Background(), sessRepository.(*sessionRepo).generateKey(sessionID)).Result()
+		require.NoError(t, err)
+		require.True(t, ttl > 0 && ttl <= 10*time.Second)
+	})
+
+	t.Run("Revoked session", func(t *testing.T) {
+		sess := &models.Session{UserID: uuid.New()}
+		sessionID, err := sessRepository.CreateSession(context.Background(), sess, 10)
+		require.NoError(t, err)
+		require.NoError(t, sessRepository.DeleteByUserId(context.Background(), sess.UserID))
+
+		err = sessRepository.UpdateSession(context.Background(), &models.Session{SessionID: sessionID, UserID: sess.UserID, ACR: models.SessionACRStepUp})
+		require.ErrorIs(t, err, redis.Nil)
+
+		_, err = sessRepository.GetSessionById(context.Background(), sessionID)
+		require.ErrorIs(t, err, redis.Nil)
+	})
+
+	t.Run("Expired session", func(t *testing.T) {
+		err := sessRepository.UpdateSession(context.Background(), &models.Session{SessionID: uuid.New().String()})
+		require.ErrorIs(t, err, redis.Nil)
+	})
+}
+
 func TestDeleteSession(t *testing.T) {
 	t.Parallel()
 
@@ -76,3 +121,54 @@ func TestDeleteSession(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestDeleteSessionsByUserId(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("DeleteByUserId", func(t *testing.T) {
+		userUUID := uuid.New()
+		first, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userUUID}, 10)
+		require.NoError(t, err)
+		second, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userUUID}, 10)
+		require.NoError(t, err)
+		other, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: uuid.New()}, 10)
+		require.NoError(t, err)
+
+		require.NoError(t, sessRepository.DeleteByUserId(context.Background(), userUUID))
+
+		for _, sessionID := range []string{first, second} {
+			_, err = sessRepository.GetSessionById(context.Background(), sessionID)
+			require.ErrorIs(t, err, redis.Nil)
+		}
+		_, err = sessRepository.GetSessionById(context.Background(), other)
+		require.NoError(t, err)
+	})
+}
+
+func TestDeleteSessionsByClientId(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("DeleteByClientId", func(t *testing.T) {
+		userUUID := uuid.New()
+		clientUUID := uuid.New()
+		clientSession, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userUUID, ClientID: clientUUID.String()}, 10)
+		require.NoError(t, err)
+		userSession, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userUUID}, 10)
+		require.NoError(t, err)
+		other, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userUUID, ClientID: uuid.New().String()}, 10)
+		require.NoError(t, err)
+
+		require.NoError(t, sessRepository.DeleteByClientId(context.Background(), clientUUID))
+
+		_, err = sessRepository.GetSessionById(context.Background(), clientSession)
+		require.ErrorIs(t, err, redis.Nil)
+		for _, sessionID := range []string{userSession, other} {
+			_, err = sessRepository.GetSessionById(context.Background(), sessionID)
+			require.NoError(t, err)
+		}
+	})
+}
@@ -3,9 +3,13 @@ package usecase
 import (
 	"context"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
 	"github.com/dinorain/useraja/config"
 	"github.com/dinorain/useraja/internal/models"
 	"github.com/dinorain/useraja/internal/session"
+	"github.com/dinorain/useraja/pkg/domain_errors"
 )
 
 // Session use case
@@ -33,5 +37,12 @@ func (u *sessionUC) DeleteById(ctx context.Context, sessionID string) error {
 
 // get session by id
 func (u *sessionUC) GetSessionById(ctx context.Context, sessionID string) (*models.Session, error) {
-	return u.sessionRepo.GetSessionById(ctx, sessionID)
+	session, err := u.sessionRepo.GetSessionById(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain_errors.ErrSessionNotFound.Wrap(err)
+		}
+		return nil, err
+	}
+	return session, nil
 }
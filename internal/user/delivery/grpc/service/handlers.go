@@ -2,41 +2,64 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/dinorain/useraja/internal/accesstoken"
 	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount"
+	"github.com/dinorain/useraja/pkg/domain_errors"
 	"github.com/dinorain/useraja/pkg/grpc_errors"
 	"github.com/dinorain/useraja/pkg/utils"
 	userService "github.com/dinorain/useraja/proto"
 )
 
-// Register new user
+// Register new user, admin only
 func (u *usersServiceGRPC) Register(ctx context.Context, r *userService.RegisterRequest) (*userService.RegisterResponse, error) {
+	sessionUser, err := u.getSessionUserFromCtx(ctx)
+	if err != nil {
+		u.logger.Errorf("getSessionUserFromCtx: %v", err)
+		return nil, u.errorResponse(err, "getSessionUserFromCtx")
+	}
+
+	if sessionUser.Role != models.UserRoleAdmin {
+		u.logger.Warnf("models.UserRoleAdmin: %v", sessionUser.Role)
+		return nil, u.errorResponse(domain_errors.ErrForbidden, "Register")
+	}
+
 	user, err := u.registerReqToUserModel(r)
 	if err != nil {
 		u.logger.Errorf("registerReqToUserModel: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "registerReqToUserModel: %v", err)
+		return nil, u.errorResponse(err, "registerReqToUserModel")
 	}
 
 	if err := utils.ValidateStruct(ctx, user); err != nil {
 		u.logger.Errorf("ValidateStruct: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "ValidateStruct: %v", err)
+		return nil, u.errorResponse(err, "ValidateStruct")
 	}
 
 	createdUser, err := u.userUC.Register(ctx, user)
 	if err != nil {
 		u.logger.Errorf("userUC.Register: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "Register: %v", err)
+		return nil, u.errorResponse(err, "Register")
+	}
+
+	userProto, err := u.applyReadMask(u.userModelToProto(createdUser), r.GetReadMask())
+	if err != nil {
+		u.logger.Errorf("applyReadMask: %v", err)
+		return nil, u.errorResponse(err, "applyReadMask")
 	}
 
-	return &userService.RegisterResponse{User: u.userModelToProto(createdUser)}, nil
+	return &userService.RegisterResponse{User: userProto}, nil
 }
 
 // Login user with email and password
@@ -44,24 +67,44 @@ func (u *usersServiceGRPC) Login(ctx context.Context, r *userService.LoginReques
 	email := r.GetEmail()
 	if !utils.ValidateEmail(email) {
 		u.logger.Errorf("ValidateEmail: %v", email)
-		return nil, status.Errorf(codes.InvalidArgument, "ValidateEmail: %v", email)
+		return nil, u.errorResponse(domain_errors.InvalidField("email", errors.New("invalid email")), "ValidateEmail")
 	}
 
 	user, err := u.userUC.Login(ctx, email, r.GetPassword())
 	if err != nil {
 		u.logger.Errorf("userUC.Login: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "Login: %v", err)
+		return nil, u.errorResponse(err, "Login")
 	}
 
 	session, err := u.sessUC.CreateSession(ctx, &models.Session{
-		UserID: user.UserID,
+		UserID:   user.UserID,
+		AuthTime: time.Now(),
+		ACR:      models.SessionACRBasic,
+		AMR:      []string{models.AuthMethodPassword},
 	}, u.cfg.Session.Expire)
 	if err != nil {
 		u.logger.Errorf("sessUC.CreateSession: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.CreateSession: %v", err)
+		return nil, u.errorResponse(err, "sessUC.CreateSession")
+	}
+
+	accessToken, refreshToken, err := u.userUC.GenerateTokenPair(user, session)
+	if err != nil {
+		u.logger.Errorf("userUC.GenerateTokenPair: %v", err)
+		return nil, u.errorResponse(err, "userUC.GenerateTokenPair")
+	}
+
+	userProto, err := u.applyReadMask(u.userModelToProto(user), r.GetReadMask())
+	if err != nil {
+		u.logger.Errorf("applyReadMask: %v", err)
+		return nil, u.errorResponse(err, "applyReadMask")
 	}
 
-	return &userService.LoginResponse{User: u.userModelToProto(user), SessionId: session}, err
+	return &userService.LoginResponse{
+		User:         userProto,
+		SessionId:    session,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
 }
 
 // FindByEmail find user by email address
@@ -69,16 +112,22 @@ func (u *usersServiceGRPC) FindByEmail(ctx context.Context, r *userService.FindB
 	email := r.GetEmail()
 	if !utils.ValidateEmail(email) {
 		u.logger.Errorf("ValidateEmail: %v", email)
-		return nil, status.Errorf(codes.InvalidArgument, "ValidateEmail: %v", email)
+		return nil, u.errorResponse(domain_errors.InvalidField("email", errors.New("invalid email")), "ValidateEmail")
 	}
 
 	user, err := u.userUC.FindByEmail(ctx, email)
 	if err != nil {
 		u.logger.Errorf("userUC.FindByEmail: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.FindByEmail: %v", err)
+		return nil, u.errorResponse(err, "userUC.FindByEmail")
 	}
 
-	return &userService.FindByEmailResponse{User: u.userModelToProto(user)}, err
+	userProto, err := u.applyReadMask(u.userModelToProto(user), r.GetReadMask())
+	if err != nil {
+		u.logger.Errorf("applyReadMask: %v", err)
+		return nil, u.errorResponse(err, "applyReadMask")
+	}
+
+	return &userService.FindByEmailResponse{User: userProto}, nil
 }
 
 // FindById find user by uuid
@@ -86,16 +135,90 @@ func (u *usersServiceGRPC) FindById(ctx context.Context, r *userService.FindById
 	userUUID, err := uuid.Parse(r.GetUuid())
 	if err != nil {
 		u.logger.Errorf("uuid.Parse: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "uuid.Parse: %v", err)
+		return nil, u.errorResponse(domain_errors.InvalidField("uuid", err), "uuid.Parse")
 	}
 
 	user, err := u.userUC.CachedFindById(ctx, userUUID)
 	if err != nil {
 		u.logger.Errorf("userUC.CachedFindById: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.CachedFindById: %v", err)
+		return nil, u.errorResponse(err, "userUC.CachedFindById")
+	}
+
+	userProto, err := u.applyReadMask(u.userModelToProto(user), r.GetReadMask())
+	if err != nil {
+		u.logger.Errorf("applyReadMask: %v", err)
+		return nil, u.errorResponse(err, "applyReadMask")
+	}
+
+	return &userService.FindByIdResponse{User: userProto}, nil
+}
+
+// UpdateById update user by uuid if it was not modified since the request version, admins may update any user
+func (u *usersServiceGRPC) UpdateById(ctx context.Context, r *userService.UpdateByIdRequest) (*userService.UpdateByIdResponse, error) {
+	userUUID, err := uuid.Parse(r.GetUuid())
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, u.errorResponse(domain_errors.InvalidField("uuid", err), "uuid.Parse")
+	}
+
+	if r.GetVersion() < 1 {
+		u.logger.Errorf("version: %v", r.GetVersion())
+		return nil, u.errorResponse(domain_errors.InvalidField("version", errors.New("version of the user is required")), "version")
+	}
+
+	sessionUser, err := u.getSessionUserFromCtx(ctx)
+	if err != nil {
+		u.logger.Errorf("getSessionUserFromCtx: %v", err)
+		return nil, u.errorResponse(err, "getSessionUserFromCtx")
+	}
+
+	if sessionUser.Role != models.UserRoleAdmin && sessionUser.UserID != userUUID {
+		u.logger.Warnf("models.UserRoleAdmin: %v", sessionUser.Role)
+		return nil, u.errorResponse(domain_errors.ErrForbidden, "UpdateById")
+	}
+
+	if r.Password != nil {
+		if err := u.requireStepUp(ctx); err != nil {
+			u.logger.Warnf("requireStepUp: %v", err)
+			return nil, u.errorResponse(err, "requireStepUp")
+		}
+	}
+
+	user, err := u.userUC.FindById(ctx, userUUID)
+	if err != nil {
+		u.logger.Errorf("userUC.FindById: %v", err)
+		return nil, u.errorResponse(err, "userUC.FindById")
+	}
+
+	if user.Version != r.GetVersion() {
+		u.logger.Warnf("user.Version: %v, version: %v", user.Version, r.GetVersion())
+		return nil, u.errorResponse(domain_errors.ErrVersionMismatch, "UpdateById")
+	}
+
+	user, err = u.updateReqToUserModel(user, r)
+	if err != nil {
+		u.logger.Errorf("updateReqToUserModel: %v", err)
+		return nil, u.errorResponse(err, "updateReqToUserModel")
+	}
+
+	if err := utils.ValidateStruct(ctx, user); err != nil {
+		u.logger.Errorf("ValidateStruct: %v", err)
+		return nil, u.errorResponse(err, "ValidateStruct")
+	}
+
+	updatedUser, err := u.userUC.UpdateById(ctx, user)
+	if err != nil {
+		u.logger.Errorf("userUC.UpdateById: %v", err)
+		return nil, u.errorResponse(err, "userUC.UpdateById")
+	}
+
+	if r.Password != nil {
+		if err := u.userUC.RevokeTrustedDevices(ctx, userUUID); err != nil {
+			u.logger.Errorf("userUC.RevokeTrustedDevices: %v", err)
+		}
 	}
 
-	return &userService.FindByIdResponse{User: u.userModelToProto(user)}, nil
+	return &userService.UpdateByIdResponse{User: u.userModelToProto(updatedUser)}, nil
 }
 
 // GetMe to get session id from, ctx metadata, find user by uuid and returns it
@@ -103,25 +226,33 @@ func (u *usersServiceGRPC) GetMe(ctx context.Context, r *userService.GetMeReques
 	sessID, err := u.getSessionIDFromCtx(ctx)
 	if err != nil {
 		u.logger.Errorf("getSessionIDFromCtx: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.getSessionIDFromCtx: %v", err)
+		return nil, u.errorResponse(err, "sessUC.getSessionIDFromCtx")
 	}
 
 	session, err := u.sessUC.GetSessionById(ctx, sessID)
 	if err != nil {
 		u.logger.Errorf("sessUC.GetSessionById: %v", err)
-		if errors.Is(err, redis.Nil) {
-			return nil, status.Errorf(codes.NotFound, "sessUC.GetSessionById: %v", grpc_errors.ErrNotFound)
-		}
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.GetSessionById: %v", err)
+		return nil, u.errorResponse(err, "sessUC.GetSessionById")
 	}
 
 	user, err := u.userUC.CachedFindById(ctx, session.UserID)
 	if err != nil {
 		u.logger.Errorf("userUC.CachedFindById: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "userUC.CachedFindById: %v", err)
+		return nil, u.errorResponse(err, "userUC.CachedFindById")
+	}
+
+	if !user.IsActive() {
+		u.logger.Warnf("user.IsActive: %v", user.Status)
+		return nil, u.errorResponse(domain_errors.ErrUserInactive, "GetMe")
+	}
+
+	userProto, err := u.applyReadMask(u.userModelToProto(user), r.GetReadMask())
+	if err != nil {
+		u.logger.Errorf("applyReadMask: %v", err)
+		return nil, u.errorResponse(err, "applyReadMask")
 	}
 
-	return &userService.GetMeResponse{User: u.userModelToProto(user)}, nil
+	return &userService.GetMeResponse{User: userProto}, nil
 }
 
 // Logout user, delete current session
@@ -129,17 +260,259 @@ func (u *usersServiceGRPC) Logout(ctx context.Context, request *userService.Logo
 	sessID, err := u.getSessionIDFromCtx(ctx)
 	if err != nil {
 		u.logger.Errorf("getSessionIDFromCtx: %v", err)
-		return nil, err
+		return nil, u.errorResponse(err, "getSessionIDFromCtx")
 	}
 
 	if err := u.sessUC.DeleteById(ctx, sessID); err != nil {
 		u.logger.Errorf("sessUC.DeleteById: %v", err)
-		return nil, status.Errorf(grpc_errors.ParseGRPCErrStatusCode(err), "sessUC.DeleteById: %v", err)
+		return nil, u.errorResponse(err, "sessUC.DeleteById")
 	}
 
 	return &userService.LogoutResponse{}, nil
 }
 
+// FindAll find a page of users matching the request filters
+func (u *usersServiceGRPC) FindAll(ctx context.Context, r *userService.FindAllRequest) (*userService.FindAllResponse, error) {
+	sessionUser, err := u.getSessionUserFromCtx(ctx)
+	if err != nil {
+		u.logger.Errorf("getSessionUserFromCtx: %v", err)
+		return nil, u.errorResponse(err, "getSessionUserFromCtx")
+	}
+
+	if r.GetIncludeDeleted() && sessionUser.Role != models.UserRoleAdmin {
+		u.logger.Warnf("models.UserRoleAdmin: %v", sessionUser.Role)
+		return nil, u.errorResponse(domain_errors.ErrForbidden, "FindAll")
+	}
+
+	if err := u.validateReadMask(r.GetReadMask()); err != nil {
+		u.logger.Errorf("validateReadMask: %v", err)
+		return nil, u.errorResponse(err, "validateReadMask")
+	}
+
+	pq := utils.NewPaginationFromQueryParams(strconv.Itoa(int(r.GetSize())), strconv.Itoa(int(r.GetPage())))
+	pq.SetOrderBy(r.GetOrderBy())
+	pq.SetCursor(r.GetPageToken())
+
+	filter := &models.UserFilter{Role: r.GetRole(), Search: strings.TrimSpace(r.GetSearch()), IncludeDeleted: r.GetIncludeDeleted()}
+	if r.GetCreatedFrom() != nil {
+		createdFrom := r.GetCreatedFrom().AsTime()
+		filter.CreatedFrom = &createdFrom
+	}
+	if r.GetCreatedTo() != nil {
+		createdTo := r.GetCreatedTo().AsTime()
+		filter.CreatedTo = &createdTo
+	}
+	if r.GetVerified() != nil {
+		verified := r.GetVerified().GetValue()
+		filter.Verified = &verified
+	}
+
+	usersList, err := u.userUC.FindAll(ctx, filter, pq)
+	if err != nil {
+		u.logger.Errorf("userUC.FindAll: %v", err)
+		return nil, u.errorResponse(err, "userUC.FindAll")
+	}
+
+	usersProto := make([]*userService.User, 0, len(usersList.Users))
+	for i := range usersList.Users {
+		userProto, err := u.applyReadMask(u.userModelToProto(&usersList.Users[i]), r.GetReadMask())
+		if err != nil {
+			u.logger.Errorf("applyReadMask: %v", err)
+			return nil, u.errorResponse(err, "applyReadMask")
+		}
+		usersProto = append(usersProto, userProto)
+	}
+
+	return &userService.FindAllResponse{
+		Users:         usersProto,
+		TotalCount:    int32(usersList.TotalCount),
+		TotalPages:    int32(usersList.TotalPages),
+		Page:          int32(usersList.Page),
+		Size:          int32(usersList.Size),
+		HasMore:       usersList.HasMore,
+		NextPageToken: usersList.NextCursor,
+		PrevPageToken: usersList.PrevCursor,
+	}, nil
+}
+
+// StreamUsers stream every user in batches ordered by creation time
+func (u *usersServiceGRPC) StreamUsers(r *userService.StreamUsersRequest, stream userService.UserService_StreamUsersServer) error {
+	ctx := stream.Context()
+	if _, err := u.getSessionUserFromCtx(ctx); err != nil {
+		u.logger.Errorf("getSessionUserFromCtx: %v", err)
+		return u.errorResponse(err, "getSessionUserFromCtx")
+	}
+
+	if err := u.streamUsers(ctx, &models.UserFilter{}, r.GetBatchSize(), r.GetReadMask(), func(users []*userService.User) error {
+		return stream.Send(&userService.StreamUsersResponse{Users: users})
+	}); err != nil {
+		u.logger.Errorf("streamUsers: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ExportUsers admin only stream of users matching the request filters
+func (u *usersServiceGRPC) ExportUsers(r *userService.ExportUsersRequest, stream userService.UserService_ExportUsersServer) error {
+	ctx := stream.Context()
+	user, err := u.getSessionUserFromCtx(ctx)
+	if err != nil {
+		u.logger.Errorf("getSessionUserFromCtx: %v", err)
+		return u.errorResponse(err, "getSessionUserFromCtx")
+	}
+
+	if user.Role != models.UserRoleAdmin {
+		u.logger.Warnf("models.UserRoleAdmin: %v", user.Role)
+		return u.errorResponse(domain_errors.ErrForbidden, "ExportUsers")
+	}
+
+	filter := &models.UserFilter{Role: r.GetRole()}
+	if r.GetCreatedFrom() != nil {
+		createdFrom := r.GetCreatedFrom().AsTime()
+		filter.CreatedFrom = &createdFrom
+	}
+	if r.GetCreatedTo() != nil {
+		createdTo := r.GetCreatedTo().AsTime()
+		filter.CreatedTo = &createdTo
+	}
+
+	if err := u.streamUsers(ctx, filter, r.GetBatchSize(), r.GetReadMask(), func(users []*userService.User) error {
+		return stream.Send(&userService.ExportUsersResponse{Users: users})
+	}); err != nil {
+		u.logger.Errorf("streamUsers: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// SuspendUser admin only suspend of a user, its sessions are revoked
+func (u *usersServiceGRPC) SuspendUser(ctx context.Context, r *userService.SuspendUserRequest) (*userService.SuspendUserResponse, error) {
+	user, err := u.changeStatus(ctx, r.GetUuid(), models.UserStatusSuspended, r.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	return &userService.SuspendUserResponse{User: u.userModelToProto(user)}, nil
+}
+
+// ReactivateUser admin only reactivation of a suspended, locked or deactivated user
+func (u *usersServiceGRPC) ReactivateUser(ctx context.Context, r *userService.ReactivateUserRequest) (*userService.ReactivateUserResponse, error) {
+	user, err := u.changeStatus(ctx, r.GetUuid(), models.UserStatusActive, r.GetReason())
+	if err != nil {
+		return nil, err
+	}
+
+	return &userService.ReactivateUserResponse{User: u.userModelToProto(user)}, nil
+}
+
+// DeactivateMe deactivate the session user, its sessions are revoked
+func (u *usersServiceGRPC) DeactivateMe(ctx context.Context, r *userService.DeactivateMeRequest) (*userService.DeactivateMeResponse, error) {
+	sessionUser, err := u.getSessionUserFromCtx(ctx)
+	if err != nil {
+		u.logger.Errorf("getSessionUserFromCtx: %v", err)
+		return nil, u.errorResponse(err, "getSessionUserFromCtx")
+	}
+
+	user, err := u.userUC.ChangeStatus(ctx, &models.UserStatusChange{
+		UserID:  sessionUser.UserID,
+		Status:  models.UserStatusDeactivated,
+		Reason:  strings.TrimSpace(r.GetReason()),
+		ActorID: &sessionUser.UserID,
+	})
+	if err != nil {
+		u.logger.Errorf("userUC.ChangeStatus: %v", err)
+		return nil, u.errorResponse(err, "userUC.ChangeStatus")
+	}
+
+	return &userService.DeactivateMeResponse{User: u.userModelToProto(user)}, nil
+}
+
+// Reauthenticate check the password of the current user again, refreshing the authentication time of the session
+func (u *usersServiceGRPC) Reauthenticate(ctx context.Context, r *userService.ReauthenticateRequest) (*userService.ReauthenticateResponse, error) {
+	sessID, err := u.getSessionIDFromCtx(ctx)
+	if err != nil {
+		u.logger.Errorf("getSessionIDFromCtx: %v", err)
+		return nil, u.errorResponse(err, "getSessionIDFromCtx")
+	}
+
+	session, err := u.sessUC.GetSessionById(ctx, sessID)
+	if err != nil {
+		u.logger.Errorf("sessUC.GetSessionById: %v", err)
+		return nil, u.errorResponse(err, "sessUC.GetSessionById")
+	}
+
+	session, err = u.userUC.Reauthenticate(ctx, session, r.GetPassword())
+	if err != nil {
+		u.logger.Errorf("userUC.Reauthenticate: %v", err)
+		return nil, u.errorResponse(err, "userUC.Reauthenticate")
+	}
+
+	return &userService.ReauthenticateResponse{AuthTime: timestamppb.New(session.AuthTime), Amr: session.AMR}, nil
+}
+
+// changeStatus admin only status change of the user with the given uuid
+func (u *usersServiceGRPC) changeStatus(ctx context.Context, userID string, status string, reason string) (*models.User, error) {
+	sessionUser, err := u.getSessionUserFromCtx(ctx)
+	if err != nil {
+		u.logger.Errorf("getSessionUserFromCtx: %v", err)
+		return nil, u.errorResponse(err, "getSessionUserFromCtx")
+	}
+
+	if sessionUser.Role != models.UserRoleAdmin {
+		u.logger.Warnf("models.UserRoleAdmin: %v", sessionUser.Role)
+		return nil, u.errorResponse(domain_errors.ErrForbidden, "changeStatus")
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		u.logger.Errorf("uuid.Parse: %v", err)
+		return nil, u.errorResponse(domain_errors.InvalidField("uuid", err), "uuid.Parse")
+	}
+
+	user, err := u.userUC.ChangeStatus(ctx, &models.UserStatusChange{
+		UserID:  userUUID,
+		Status:  status,
+		Reason:  strings.TrimSpace(reason),
+		ActorID: &sessionUser.UserID,
+	})
+	if err != nil {
+		u.logger.Errorf("userUC.ChangeStatus: %v", err)
+		return nil, u.errorResponse(err, "userUC.ChangeStatus")
+	}
+
+	return user, nil
+}
+
+func (u *usersServiceGRPC) streamUsers(
+	ctx context.Context,
+	filter *models.UserFilter,
+	batchSize int32,
+	mask *fieldmaskpb.FieldMask,
+	send func(users []*userService.User) error,
+) error {
+	if err := u.validateReadMask(mask); err != nil {
+		return u.errorResponse(err, "validateReadMask")
+	}
+
+	if err := u.userUC.StreamAll(ctx, filter, int(batchSize), func(users []models.User) error {
+		usersProto := make([]*userService.User, 0, len(users))
+		for i := range users {
+			userProto, err := u.applyReadMask(u.userModelToProto(&users[i]), mask)
+			if err != nil {
+				return err
+			}
+			usersProto = append(usersProto, userProto)
+		}
+		return send(usersProto)
+	}); err != nil {
+		return u.errorResponse(err, "userUC.StreamAll")
+	}
+
+	return nil
+}
+
 func (u *usersServiceGRPC) registerReqToUserModel(r *userService.RegisterRequest) (*models.User, error) {
 	avatar := r.GetAvatar()
 	userCandidate := &models.User{
@@ -158,31 +531,155 @@ func (u *usersServiceGRPC) registerReqToUserModel(r *userService.RegisterRequest
 	return userCandidate, nil
 }
 
+func (u *usersServiceGRPC) updateReqToUserModel(updateCandidate *models.User, r *userService.UpdateByIdRequest) (*models.User, error) {
+	if r.FirstName != nil {
+		updateCandidate.FirstName = strings.TrimSpace(r.GetFirstName().GetValue())
+	}
+	if r.LastName != nil {
+		updateCandidate.LastName = strings.TrimSpace(r.GetLastName().GetValue())
+	}
+	if r.Avatar != nil {
+		avatar := strings.TrimSpace(r.GetAvatar().GetValue())
+		updateCandidate.Avatar = &avatar
+	}
+	if r.Password != nil {
+		updateCandidate.Password = r.GetPassword().GetValue()
+		if err := updateCandidate.HashPassword(); err != nil {
+			return nil, err
+		}
+	}
+
+	return updateCandidate, nil
+}
+
 func (u *usersServiceGRPC) userModelToProto(user *models.User) *userService.User {
 	userProto := &userService.User{
 		Uuid:      user.UserID.String(),
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
-		Password:  user.Password,
 		Email:     user.Email,
 		Role:      user.Role,
+		Status:    user.Status,
+		Version:   user.Version,
 		Avatar:    user.GetAvatar(),
 		CreatedAt: timestamppb.New(user.CreatedAt),
 		UpdatedAt: timestamppb.New(user.UpdatedAt),
 	}
+	if user.EmailVerifiedAt != nil {
+		userProto.EmailVerifiedAt = timestamppb.New(*user.EmailVerifiedAt)
+	}
+	if user.PhoneNumber != nil {
+		userProto.PhoneNumber = *user.PhoneNumber
+	}
+	if user.PhoneVerifiedAt != nil {
+		userProto.PhoneVerifiedAt = timestamppb.New(*user.PhoneVerifiedAt)
+	}
+	if user.DeletedAt != nil {
+		userProto.DeletedAt = timestamppb.New(*user.DeletedAt)
+	}
 	return userProto
 }
 
+func (u *usersServiceGRPC) validateReadMask(mask *fieldmaskpb.FieldMask) error {
+	fields := (&userService.User{}).ProtoReflect().Descriptor().Fields()
+	for _, path := range mask.GetPaths() {
+		if fields.ByName(protoreflect.Name(path)) == nil {
+			return domain_errors.InvalidField("read_mask", fmt.Errorf("invalid field path %q", path))
+		}
+	}
+	return nil
+}
+
+// applyReadMask keeps only the top level user fields listed in mask, an empty mask returns every field
+func (u *usersServiceGRPC) applyReadMask(userProto *userService.User, mask *fieldmaskpb.FieldMask) (*userService.User, error) {
+	if len(mask.GetPaths()) == 0 {
+		return userProto, nil
+	}
+
+	if err := u.validateReadMask(mask); err != nil {
+		return nil, err
+	}
+
+	src := userProto.ProtoReflect()
+	fields := src.Descriptor().Fields()
+	masked := &userService.User{}
+	dst := masked.ProtoReflect()
+	for _, path := range mask.GetPaths() {
+		fd := fields.ByName(protoreflect.Name(path))
+		if src.Has(fd) {
+			dst.Set(fd, src.Get(fd))
+		}
+	}
+
+	return masked, nil
+}
+
 func (u *usersServiceGRPC) getSessionIDFromCtx(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return "", status.Errorf(codes.Unauthenticated, "metadata.FromIncomingContext: %v", grpc_errors.ErrNoCtxMetaData)
+		return "", grpc_errors.ErrNoCtxMetaData
 	}
 
 	sessionID := md.Get("session_id")
-	if sessionID[0] == "" {
-		return "", status.Errorf(codes.PermissionDenied, "md.Get sessionId: %v", grpc_errors.ErrInvalidSessionId)
+	if len(sessionID) == 0 || sessionID[0] == "" {
+		return "", grpc_errors.ErrInvalidSessionId
 	}
 
 	return sessionID[0], nil
 }
+
+// getSessionUserFromCtx find the user of the session referenced by the ctx metadata. Calls made with a service account
+// key act as an admin named after the service account and calls made with a personal access token as its user,
+// the APIKeyAuth interceptor already checked they may call the rpc
+func (u *usersServiceGRPC) getSessionUserFromCtx(ctx context.Context) (*models.User, error) {
+	if apiKey, ok := serviceaccount.FromContext(ctx); ok {
+		return &models.User{UserID: apiKey.ServiceAccountID, Role: models.UserRoleAdmin, Status: models.UserStatusActive}, nil
+	}
+	if _, tokenUser, ok := accesstoken.FromContext(ctx); ok {
+		return tokenUser, nil
+	}
+
+	sessID, err := u.getSessionIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := u.sessUC.GetSessionById(ctx, sessID)
+	if err != nil {
+		return nil, errors.Wrap(err, "sessUC.GetSessionById")
+	}
+
+	user, err := u.userUC.CachedFindById(ctx, session.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "userUC.CachedFindById")
+	}
+
+	if !user.IsActive() {
+		return nil, domain_errors.ErrUserInactive
+	}
+
+	return user, nil
+}
+
+// requireStepUp reject the call unless the ctx session verified a step-up code recently
+func (u *usersServiceGRPC) requireStepUp(ctx context.Context) error {
+	sessID, err := u.getSessionIDFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+
+	session, err := u.sessUC.GetSessionById(ctx, sessID)
+	if err != nil {
+		return errors.Wrap(err, "sessUC.GetSessionById")
+	}
+
+	if !session.SteppedUp(time.Duration(u.cfg.OTP.StepUpMaxAge) * time.Second) {
+		return domain_errors.ErrStepUpRequired
+	}
+	return nil
+}
+
+// errorResponse grpc status error with rich details, internal causes are only exposed in debug mode
+func (u *usersServiceGRPC) errorResponse(err error, msg string) error {
+	return grpc_errors.ErrorResponse(err, msg, u.cfg.Server.DebugErrorsResponse)
+}
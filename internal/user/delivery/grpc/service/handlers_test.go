@@ -4,17 +4,30 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/dinorain/useraja/config"
 	"github.com/dinorain/useraja/internal/models"
 	mockSessUC "github.com/dinorain/useraja/internal/session/mock"
 	"github.com/dinorain/useraja/internal/user/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/grpc_errors"
 	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/utils"
 	userService "github.com/dinorain/useraja/proto"
 )
 
@@ -26,7 +39,8 @@ func TestUsersService_Register(t *testing.T) {
 	userUC := mock.NewMockUserUseCase(ctrl)
 	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
-	authServerGRPC := NewAuthServerGRPC(apiLogger, nil, userUC, sessUC)
+	cfg := &config.Config{}
+	authServerGRPC := NewAuthServerGRPC(apiLogger, cfg, userUC, sessUC)
 
 	reqValue := &userService.RegisterRequest{
 		Email:     "email@gmail.com",
@@ -37,6 +51,14 @@ func TestUsersService_Register(t *testing.T) {
 		Avatar:    "",
 	}
 
+	adminCtx := func() context.Context {
+		sessionUUID := uuid.New().String()
+		adminUUID := uuid.New()
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: adminUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), adminUUID).Return(&models.User{UserID: adminUUID, Role: models.UserRoleAdmin, Status: models.UserStatusActive}, nil)
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+	}
+
 	t.Run("Register", func(t *testing.T) {
 		t.Parallel()
 		userID := uuid.New()
@@ -52,11 +74,89 @@ func TestUsersService_Register(t *testing.T) {
 
 		userUC.EXPECT().Register(gomock.Any(), gomock.Any()).Return(user, nil)
 
-		response, err := authServerGRPC.Register(context.Background(), reqValue)
+		response, err := authServerGRPC.Register(adminCtx(), reqValue)
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Equal(t, reqValue.Email, response.User.Email)
 	})
+
+	t.Run("Validation error details", func(t *testing.T) {
+		t.Parallel()
+
+		response, err := authServerGRPC.Register(adminCtx(), &userService.RegisterRequest{
+			Email:     "invalid",
+			FirstName: "FirstName",
+			LastName:  "LastName",
+			Password:  "Password",
+			Role:      "user",
+		})
+		require.Error(t, err)
+		require.Nil(t, response)
+
+		st := status.Convert(err)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+
+		var info *errdetails.ErrorInfo
+		var badRequest *errdetails.BadRequest
+		for _, detail := range st.Details() {
+			switch d := detail.(type) {
+			case *errdetails.ErrorInfo:
+				info = d
+			case *errdetails.BadRequest:
+				badRequest = d
+			}
+		}
+		require.NotNil(t, info)
+		require.Equal(t, domain_errors.ReasonValidationFailed, info.Reason)
+		require.Equal(t, grpc_errors.ErrorDomain, info.Domain)
+		require.NotNil(t, badRequest)
+		require.Len(t, badRequest.FieldViolations, 1)
+		require.Equal(t, "email", badRequest.FieldViolations[0].Field)
+	})
+
+	t.Run("Internal error hides cause", func(t *testing.T) {
+		t.Parallel()
+
+		userUC.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil, errors.New("pq: connection refused"))
+
+		response, err := authServerGRPC.Register(adminCtx(), &userService.RegisterRequest{
+			Email:     "internal@gmail.com",
+			FirstName: "FirstName",
+			LastName:  "LastName",
+			Password:  "Password",
+			Role:      "user",
+		})
+		require.Error(t, err)
+		require.Nil(t, response)
+
+		st := status.Convert(err)
+		require.Equal(t, codes.Internal, st.Code())
+		require.NotContains(t, st.Message(), "pq: connection refused")
+		require.Equal(t, domain_errors.ReasonInternal, grpc_errors.ParseGRPCErrReason(err))
+	})
+
+	t.Run("Forbidden for non admin", func(t *testing.T) {
+		t.Parallel()
+
+		sessionUUID := uuid.New().String()
+		userUUID := uuid.New()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: userUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil)
+
+		response, err := authServerGRPC.Register(ctx, reqValue)
+		require.Nil(t, response)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		t.Parallel()
+
+		response, err := authServerGRPC.Register(context.Background(), reqValue)
+		require.Nil(t, response)
+		require.Error(t, err)
+	})
 }
 
 func TestUsersService_Login(t *testing.T) {
@@ -92,14 +192,20 @@ func TestUsersService_Login(t *testing.T) {
 		}
 
 		userUC.EXPECT().Login(gomock.Any(), reqValue.Email, reqValue.Password).Return(user, nil)
-		sessUC.EXPECT().CreateSession(gomock.Any(), &models.Session{
-			UserID: user.UserID,
-		}, cfg.Session.Expire).Return(session, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), gomock.AssignableToTypeOf(&models.Session{}), cfg.Session.Expire).
+			DoAndReturn(func(ctx context.Context, s *models.Session, expire int) (string, error) {
+				require.Equal(t, user.UserID, s.UserID)
+				require.Equal(t, models.SessionACRBasic, s.ACR)
+				return session, nil
+			})
+		userUC.EXPECT().GenerateTokenPair(user, session).Return("at", "rt", nil)
 
 		response, err := authServerGRPC.Login(context.Background(), reqValue)
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Equal(t, reqValue.Email, response.User.Email)
+		require.Equal(t, "at", response.AccessToken)
+		require.Equal(t, "rt", response.RefreshToken)
 	})
 }
 
@@ -140,6 +246,46 @@ func TestUsersService_FindByID(t *testing.T) {
 		require.NotNil(t, response)
 		require.Equal(t, reqValue.Uuid, response.User.Uuid)
 	})
+
+	t.Run("FindByID with read mask", func(t *testing.T) {
+		t.Parallel()
+		user := &models.User{
+			UserID:    userUUID,
+			Email:     "email@gmail.com",
+			FirstName: "FirstName",
+			LastName:  "LastName",
+			Password:  "Password",
+			Role:      "user",
+			Avatar:    nil,
+		}
+
+		userUC.EXPECT().CachedFindById(gomock.Any(), user.UserID).Return(user, nil)
+
+		response, err := authServerGRPC.FindById(context.Background(), &userService.FindByIdRequest{
+			Uuid:     userUUID.String(),
+			ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"uuid", "email"}},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		require.Equal(t, user.UserID.String(), response.User.Uuid)
+		require.Equal(t, user.Email, response.User.Email)
+		require.Empty(t, response.User.FirstName)
+		require.Nil(t, response.User.CreatedAt)
+	})
+
+	t.Run("FindByID with invalid read mask", func(t *testing.T) {
+		t.Parallel()
+
+		userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID}, nil)
+
+		response, err := authServerGRPC.FindById(context.Background(), &userService.FindByIdRequest{
+			Uuid:     userUUID.String(),
+			ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"password"}},
+		})
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
 }
 
 func TestUsersService_FindByEmail(t *testing.T) {
@@ -208,6 +354,7 @@ func TestUsersService_GetMe(t *testing.T) {
 			Password:  "Password",
 			Role:      "user",
 			Avatar:    nil,
+			Status:    models.UserStatusActive,
 		}
 
 		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID}, nil)
@@ -231,6 +378,65 @@ func TestUsersService_GetMe(t *testing.T) {
 	})
 }
 
+func TestUsersService_FindAll(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, &config.Config{}, userUC, sessUC)
+
+	sessionUUID := uuid.New().String()
+	userUUID := uuid.New()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+	verified := true
+	pagination := utils.NewPaginationQuery(5, 2)
+	pagination.SetOrderBy("-created_at")
+	pagination.SetCursor("page-token")
+
+	sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: userUUID}, nil)
+	userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil)
+	userUC.EXPECT().FindAll(gomock.Any(), &models.UserFilter{Role: models.UserRoleUser, Search: "first", Verified: &verified}, pagination).Return(&models.UsersList{
+		TotalCount: 6,
+		TotalPages: 2,
+		Page:       2,
+		Size:       5,
+		PrevCursor: "prev-token",
+		Users:      []models.User{{UserID: uuid.New(), Email: "a@gmail.com"}},
+	}, nil)
+
+	response, err := authServerGRPC.FindAll(ctx, &userService.FindAllRequest{
+		Page:      2,
+		Size:      5,
+		Role:      models.UserRoleUser,
+		Search:    " first ",
+		Verified:  wrapperspb.Bool(true),
+		OrderBy:   "-created_at",
+		ReadMask:  &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+		PageToken: "page-token",
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Users, 1)
+	require.Equal(t, "a@gmail.com", response.Users[0].Email)
+	require.Empty(t, response.Users[0].Uuid)
+	require.Equal(t, int32(6), response.TotalCount)
+	require.Equal(t, int32(2), response.TotalPages)
+	require.False(t, response.HasMore)
+	require.Equal(t, "prev-token", response.PrevPageToken)
+	require.Empty(t, response.NextPageToken)
+
+	t.Run("Include deleted is admin only", func(t *testing.T) {
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: userUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil)
+
+		_, err := authServerGRPC.FindAll(ctx, &userService.FindAllRequest{IncludeDeleted: true})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
 func TestUsersService_Logout(t *testing.T) {
 	t.Parallel()
 
@@ -269,3 +475,298 @@ func TestUsersService_Logout(t *testing.T) {
 		require.NotNil(t, response)
 	})
 }
+
+type streamUsersServerMock struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*userService.StreamUsersResponse
+}
+
+func (s *streamUsersServerMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *streamUsersServerMock) Send(response *userService.StreamUsersResponse) error {
+	s.responses = append(s.responses, response)
+	return nil
+}
+
+type exportUsersServerMock struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*userService.ExportUsersResponse
+}
+
+func (s *exportUsersServerMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *exportUsersServerMock) Send(response *userService.ExportUsersResponse) error {
+	s.responses = append(s.responses, response)
+	return nil
+}
+
+func TestUsersService_StreamUsers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, &config.Config{}, userUC, sessUC)
+
+	sessionUUID := uuid.New().String()
+	userUUID := uuid.New()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+	sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: userUUID}, nil)
+	userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil)
+	userUC.EXPECT().StreamAll(gomock.Any(), &models.UserFilter{}, 2, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, filter *models.UserFilter, batchSize int, send func(users []models.User) error) error {
+			if err := send([]models.User{{UserID: uuid.New(), Email: "a@gmail.com"}, {UserID: uuid.New(), Email: "b@gmail.com"}}); err != nil {
+				return err
+			}
+			return send([]models.User{{UserID: uuid.New(), Email: "c@gmail.com"}})
+		},
+	)
+
+	stream := &streamUsersServerMock{ctx: ctx}
+	err := authServerGRPC.StreamUsers(&userService.StreamUsersRequest{
+		BatchSize: 2,
+		ReadMask:  &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+	}, stream)
+	require.NoError(t, err)
+	require.Len(t, stream.responses, 2)
+	require.Len(t, stream.responses[0].Users, 2)
+	require.Equal(t, "a@gmail.com", stream.responses[0].Users[0].Email)
+	require.Empty(t, stream.responses[0].Users[0].Uuid)
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		err := authServerGRPC.StreamUsers(&userService.StreamUsersRequest{}, &streamUsersServerMock{ctx: context.Background()})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestUsersService_ExportUsers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, &config.Config{}, userUC, sessUC)
+
+	createdFrom := time.Now().Add(-time.Hour).UTC()
+	reqValue := &userService.ExportUsersRequest{
+		Role:        models.UserRoleUser,
+		CreatedFrom: timestamppb.New(createdFrom),
+	}
+
+	t.Run("Admin", func(t *testing.T) {
+		t.Parallel()
+
+		sessionUUID := uuid.New().String()
+		adminUUID := uuid.New()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: adminUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), adminUUID).Return(&models.User{UserID: adminUUID, Role: models.UserRoleAdmin, Status: models.UserStatusActive}, nil)
+		userUC.EXPECT().StreamAll(gomock.Any(), &models.UserFilter{Role: models.UserRoleUser, CreatedFrom: &createdFrom}, 0, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, filter *models.UserFilter, batchSize int, send func(users []models.User) error) error {
+				return send([]models.User{{UserID: uuid.New(), Role: models.UserRoleUser}})
+			},
+		)
+
+		stream := &exportUsersServerMock{ctx: ctx}
+		require.NoError(t, authServerGRPC.ExportUsers(reqValue, stream))
+		require.Len(t, stream.responses, 1)
+	})
+
+	t.Run("Forbidden for non admin", func(t *testing.T) {
+		t.Parallel()
+
+		sessionUUID := uuid.New().String()
+		userUUID := uuid.New()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: userUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil)
+
+		err := authServerGRPC.ExportUsers(reqValue, &exportUsersServerMock{ctx: ctx})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestUsersService_SuspendUser(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, &config.Config{}, userUC, sessUC)
+
+	targetUUID := uuid.New()
+	reqValue := &userService.SuspendUserRequest{Uuid: targetUUID.String(), Reason: " abuse "}
+
+	t.Run("Admin", func(t *testing.T) {
+		t.Parallel()
+
+		sessionUUID := uuid.New().String()
+		adminUUID := uuid.New()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: adminUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), adminUUID).Return(&models.User{UserID: adminUUID, Role: models.UserRoleAdmin, Status: models.UserStatusActive}, nil)
+		userUC.EXPECT().ChangeStatus(gomock.Any(), &models.UserStatusChange{
+			UserID:  targetUUID,
+			Status:  models.UserStatusSuspended,
+			Reason:  "abuse",
+			ActorID: &adminUUID,
+		}).Return(&models.User{UserID: targetUUID, Status: models.UserStatusSuspended}, nil)
+
+		response, err := authServerGRPC.SuspendUser(ctx, reqValue)
+		require.NoError(t, err)
+		require.Equal(t, models.UserStatusSuspended, response.User.Status)
+	})
+
+	t.Run("Forbidden for non admin", func(t *testing.T) {
+		t.Parallel()
+
+		sessionUUID := uuid.New().String()
+		userUUID := uuid.New()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: userUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil)
+
+		_, err := authServerGRPC.SuspendUser(ctx, reqValue)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Inactive session user", func(t *testing.T) {
+		t.Parallel()
+
+		sessionUUID := uuid.New().String()
+		adminUUID := uuid.New()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: adminUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), adminUUID).Return(&models.User{UserID: adminUUID, Role: models.UserRoleAdmin, Status: models.UserStatusSuspended}, nil)
+
+		_, err := authServerGRPC.SuspendUser(ctx, reqValue)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestUsersService_UpdateById(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, &config.Config{}, userUC, sessUC)
+
+	userUUID := uuid.New()
+
+	newCtx := func() context.Context {
+		sessionUUID := uuid.New().String()
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(&models.Session{SessionID: sessionUUID, UserID: userUUID}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil)
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		ctx := newCtx()
+		userUC.EXPECT().FindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, FirstName: "FirstName", LastName: "LastName", Role: models.UserRoleUser, Version: 3}, nil)
+		userUC.EXPECT().UpdateById(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, user *models.User) (*models.User, error) {
+				require.Equal(t, "Changed", user.FirstName)
+				require.Equal(t, int64(3), user.Version)
+				user.Version++
+				return user, nil
+			},
+		)
+
+		response, err := authServerGRPC.UpdateById(ctx, &userService.UpdateByIdRequest{
+			Uuid:      userUUID.String(),
+			FirstName: wrapperspb.String(" Changed "),
+			Version:   3,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Changed", response.User.FirstName)
+		require.Equal(t, int64(4), response.User.Version)
+	})
+
+	t.Run("Stale version", func(t *testing.T) {
+		ctx := newCtx()
+		userUC.EXPECT().FindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Version: 4}, nil)
+
+		_, err := authServerGRPC.UpdateById(ctx, &userService.UpdateByIdRequest{
+			Uuid:      userUUID.String(),
+			FirstName: wrapperspb.String("Changed"),
+			Version:   3,
+		})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("Missing version", func(t *testing.T) {
+		_, err := authServerGRPC.UpdateById(context.Background(), &userService.UpdateByIdRequest{Uuid: userUUID.String()})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Password change requires step-up", func(t *testing.T) {
+		sessionUUID := uuid.New().String()
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Times(2).Return(&models.Session{
+			SessionID: sessionUUID,
+			UserID:    userUUID,
+			AuthTime:  time.Now(),
+			ACR:       models.SessionACRBasic,
+		}, nil)
+		userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}, nil)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+
+		_, err := authServerGRPC.UpdateById(ctx, &userService.UpdateByIdRequest{
+			Uuid:     userUUID.String(),
+			Password: wrapperspb.String("changed"),
+			Version:  3,
+		})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		require.Equal(t, domain_errors.ReasonStepUpRequired, grpc_errors.ParseGRPCErrReason(err))
+	})
+}
+
+func TestUsersService_Reauthenticate(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+	authServerGRPC := NewAuthServerGRPC(apiLogger, &config.Config{}, userUC, sessUC)
+
+	userUUID := uuid.New()
+	sessionUUID := uuid.New().String()
+	session := &models.Session{SessionID: sessionUUID, UserID: userUUID}
+	authTime := time.Now()
+
+	sessUC.EXPECT().GetSessionById(gomock.Any(), sessionUUID).Return(session, nil)
+	userUC.EXPECT().Reauthenticate(gomock.Any(), session, "password").Return(&models.Session{
+		SessionID: sessionUUID,
+		UserID:    userUUID,
+		AuthTime:  authTime,
+		AMR:       []string{models.AuthMethodPassword},
+	}, nil)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("session_id", sessionUUID))
+	response, err := authServerGRPC.Reauthenticate(ctx, &userService.ReauthenticateRequest{Password: "password"})
+	require.NoError(t, err)
+	require.True(t, authTime.Equal(response.GetAuthTime().AsTime()))
+	require.Equal(t, []string{models.AuthMethodPassword}, response.GetAmr())
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+type AccessTokenCreateRequestDto struct {
+	Name      string   `json:"name" validate:"required,lte=64"`
+	Scopes    []string `json:"scopes" validate:"required,min=1,dive,oneof=users:read users:write"`
+	ExpiresIn int      `json:"expires_in" validate:"omitempty,gte=60"`
+}
+
+type AccessTokenResponseDto struct {
+	TokenID    uuid.UUID  `json:"token_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AccessTokenCreateResponseDto the token is only ever shown in this response
+type AccessTokenCreateResponseDto struct {
+	Token       string                  `json:"token"`
+	AccessToken *AccessTokenResponseDto `json:"access_token"`
+}
+
+type AccessTokensResponseDto struct {
+	Tokens []*AccessTokenResponseDto `json:"tokens"`
+}
+
+func AccessTokenResponseFromModel(token *models.PersonalAccessToken) *AccessTokenResponseDto {
+	return &AccessTokenResponseDto{
+		TokenID:    token.TokenID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.Scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func AccessTokensResponseFromModels(tokens []models.PersonalAccessToken) *AccessTokensResponseDto {
+	res := &AccessTokensResponseDto{Tokens: make([]*AccessTokenResponseDto, 0, len(tokens))}
+	for i := range tokens {
+		res.Tokens = append(res.Tokens, AccessTokenResponseFromModel(&tokens[i]))
+	}
+	return res
+}
@@ -0,0 +1,13 @@
+package dto
+
+type UserInviteRequestDto struct {
+	Email     string `json:"email" validate:"required,lte=60,email"`
+	FirstName string `json:"first_name" validate:"required,lte=30"`
+	LastName  string `json:"last_name" validate:"required,lte=30"`
+	Role      string `json:"role" validate:"required,oneof=admin user"`
+}
+
+type UserAcceptInvitationRequestDto struct {
+	Token    string `json:"token" validate:"required,lte=100"`
+	Password string `json:"password" validate:"required"`
+}
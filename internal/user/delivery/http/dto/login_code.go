@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserLoginCodeRequestDto struct {
+	Email string `json:"email" validate:"required,lte=60,email"`
+}
+
+type UserLoginCodeVerifyRequestDto struct {
+	Email string `json:"email" validate:"required,lte=60,email"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type UserStepUpRequestDto struct {
+	Code           string `json:"code" validate:"required,len=6,numeric"`
+	RememberDevice bool   `json:"remember_device"`
+	DeviceName     string `json:"device_name" validate:"omitempty,lte=128"`
+}
+
+type UserAuthContextResponseDto struct {
+	AuthTime time.Time `json:"auth_time"`
+	ACR      string    `json:"acr"`
+	AMR      []string  `json:"amr"`
+	// DeviceID of the device trusted by the step-up, if it was asked to remember it
+	DeviceID *uuid.UUID `json:"device_id,omitempty"`
+}
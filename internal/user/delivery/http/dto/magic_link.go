@@ -0,0 +1,9 @@
+package dto
+
+type UserMagicLinkRequestDto struct {
+	Email string `json:"email" validate:"required,lte=60,email"`
+}
+
+type UserMagicLinkVerifyRequestDto struct {
+	Token string `json:"token" validate:"required,lte=100"`
+}
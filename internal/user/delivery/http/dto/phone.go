@@ -0,0 +1,18 @@
+package dto
+
+type UserPhoneRequestDto struct {
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+}
+
+type UserPhoneVerifyRequestDto struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type UserSMSLoginCodeRequestDto struct {
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+}
+
+type UserSMSLoginRequestDto struct {
+	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+	Code        string `json:"code" validate:"required,len=6,numeric"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type UserReauthenticateRequestDto struct {
+	Password string `json:"password" validate:"required"`
+}
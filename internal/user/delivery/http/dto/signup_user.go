@@ -0,0 +1,17 @@
+package dto
+
+type UserSignupRequestDto struct {
+	Email      string `json:"email" validate:"required,lte=60,email"`
+	FirstName  string `json:"first_name" validate:"required,lte=30"`
+	LastName   string `json:"last_name" validate:"required,lte=30"`
+	Password   string `json:"password" validate:"required"`
+	InviteCode string `json:"invite_code" validate:"omitempty,lte=100"`
+}
+
+type UserVerifyEmailRequestDto struct {
+	Token string `json:"token" validate:"required,lte=100"`
+}
+
+type UserResendVerificationRequestDto struct {
+	Email string `json:"email" validate:"required,lte=60,email"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+type TrustedDeviceResponseDto struct {
+	DeviceID    uuid.UUID `json:"device_id"`
+	Name        string    `json:"name"`
+	IP          string    `json:"ip"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type TrustedDevicesResponseDto struct {
+	Devices []*TrustedDeviceResponseDto `json:"devices"`
+}
+
+func TrustedDeviceResponseFromModel(device *models.TrustedDevice) *TrustedDeviceResponseDto {
+	return &TrustedDeviceResponseDto{
+		DeviceID:    device.DeviceID,
+		Name:        device.Name,
+		IP:          device.IP,
+		FirstSeenAt: device.FirstSeenAt,
+		LastSeenAt:  device.LastSeenAt,
+		ExpiresAt:   device.ExpiresAt,
+	}
+}
+
+func TrustedDevicesResponseFromModels(devices []models.TrustedDevice) *TrustedDevicesResponseDto {
+	res := &TrustedDevicesResponseDto{Devices: make([]*TrustedDeviceResponseDto, 0, len(devices))}
+	for i := range devices {
+		res.Devices = append(res.Devices, TrustedDeviceResponseFromModel(&devices[i]))
+	}
+	return res
+}
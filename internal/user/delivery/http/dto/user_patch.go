@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
+// UserPatchDocument mutable user fields merge patches and json patches are applied to, password is write only
+type UserPatchDocument struct {
+	FirstName string  `json:"first_name" validate:"required,lte=30"`
+	LastName  string  `json:"last_name" validate:"required,lte=30"`
+	Email     string  `json:"email" validate:"required,lte=60,email"`
+	Role      string  `json:"role" validate:"required,oneof=admin user"`
+	Avatar    *string `json:"avatar" validate:"omitempty,lte=250"`
+	Password  *string `json:"password,omitempty" validate:"omitempty,gte=1"`
+}
+
+// userPatchableFields document fields each role may change
+var userPatchableFields = map[string][]string{
+	models.UserRoleUser:  {"first_name", "last_name", "avatar", "password"},
+	models.UserRoleAdmin: {"first_name", "last_name", "avatar", "password", "email", "role"},
+}
+
+// CanPatchUserField reports whether a user with the given role may change field
+func CanPatchUserField(role string, field string) bool {
+	for _, allowed := range userPatchableFields[role] {
+		if allowed == field {
+			return true
+		}
+	}
+	return false
+}
+
+func UserPatchDocumentFromModel(user *models.User) *UserPatchDocument {
+	return &UserPatchDocument{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      user.Role,
+		Avatar:    user.Avatar,
+	}
+}
+
+// ApplyTo copy the patched document into user, a new password is hashed
+func (d *UserPatchDocument) ApplyTo(user *models.User) error {
+	user.FirstName = strings.TrimSpace(d.FirstName)
+	user.LastName = strings.TrimSpace(d.LastName)
+	user.Email = strings.ToLower(strings.TrimSpace(d.Email))
+	user.Role = d.Role
+	user.Avatar = d.Avatar
+	if d.Avatar != nil {
+		avatar := strings.TrimSpace(*d.Avatar)
+		user.Avatar = &avatar
+	}
+	if d.Password != nil {
+		user.Password = *d.Password
+		return user.HashPassword()
+	}
+	return nil
+}
@@ -14,9 +14,15 @@ type UserResponseDto struct {
 	FirstName       string    `json:"first_name"`
 	LastName        string    `json:"last_name"`
 	Role            string    `json:"role"`
+	Status          string    `json:"status"`
+	Version         int64     `json:"version"`
 	Avatar          *string   `json:"avatar"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PhoneNumber     *string    `json:"phone_number"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 func UserResponseFromModel(user *models.User) *UserResponseDto {
@@ -26,8 +32,14 @@ func UserResponseFromModel(user *models.User) *UserResponseDto {
 		FirstName:       user.FirstName,
 		LastName:        user.LastName,
 		Role:            user.Role,
+		Status:          user.Status,
+		Version:         user.Version,
 		Avatar:          user.Avatar,
 		CreatedAt:       user.CreatedAt,
 		UpdatedAt:       user.UpdatedAt,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PhoneNumber:     user.PhoneNumber,
+		PhoneVerifiedAt: user.PhoneVerifiedAt,
+		DeletedAt:       user.DeletedAt,
 	}
 }
@@ -0,0 +1,5 @@
+package dto
+
+type UserStatusRequestDto struct {
+	Reason string `json:"reason" validate:"omitempty,lte=250"`
+}
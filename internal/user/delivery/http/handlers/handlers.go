@@ -1,24 +1,33 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/go-playground/validator"
-	"github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/accesstoken"
 	"github.com/dinorain/useraja/internal/middlewares"
 	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/serviceaccount"
 	"github.com/dinorain/useraja/internal/session"
 	"github.com/dinorain/useraja/internal/user"
 	"github.com/dinorain/useraja/internal/user/delivery/http/dto"
 	"github.com/dinorain/useraja/pkg/constants"
+	"github.com/dinorain/useraja/pkg/domain_errors"
 	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
 	"github.com/dinorain/useraja/pkg/logger"
 	"github.com/dinorain/useraja/pkg/utils"
@@ -90,6 +99,118 @@ func (h *userHandlersHTTP) Register() echo.HandlerFunc {
 	}
 }
 
+// Signup
+// @Tags Users
+// @Summary Sign up
+// @Description Public self-service signup allowed by the signup policy, the user is pending until its email is verified when verification is required
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserSignupRequestDto true "Payload"
+// @Success 201 {object} dto.UserResponseDto
+// @Router /user/signup [post]
+func (h *userHandlersHTTP) Signup() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		signupDto := &dto.UserSignupRequestDto{}
+		if err := c.Bind(signupDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, signupDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user := &models.User{
+			Email:     signupDto.Email,
+			FirstName: strings.TrimSpace(signupDto.FirstName),
+			LastName:  strings.TrimSpace(signupDto.LastName),
+			Password:  signupDto.Password,
+		}
+		if err := user.PrepareCreate(); err != nil {
+			h.logger.Errorf("PrepareCreate: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		createdUser, err := h.userUC.Signup(ctx, user, signupDto.InviteCode)
+		if err != nil {
+			h.logger.Errorf("userUC.Signup: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusCreated, dto.UserResponseFromModel(createdUser))
+	}
+}
+
+// VerifyEmail
+// @Tags Users
+// @Summary Verify email
+// @Description Verify the email address with the emailed token and activate a pending user
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserVerifyEmailRequestDto true "Payload"
+// @Success 200 {object} dto.UserResponseDto
+// @Router /user/verify-email [post]
+func (h *userHandlersHTTP) VerifyEmail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		verifyDto := &dto.UserVerifyEmailRequestDto{}
+		if err := c.Bind(verifyDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, verifyDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user, err := h.userUC.VerifyEmail(ctx, verifyDto.Token)
+		if err != nil {
+			h.logger.Errorf("userUC.VerifyEmail: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.UserResponseFromModel(user))
+	}
+}
+
+// ResendEmailVerification
+// @Tags Users
+// @Summary Resend email verification
+// @Description Email a new verification link, the response is the same whether the account exists or not
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserResendVerificationRequestDto true "Payload"
+// @Success 202
+// @Router /user/verify-email/resend [post]
+func (h *userHandlersHTTP) ResendEmailVerification() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		resendDto := &dto.UserResendVerificationRequestDto{}
+		if err := c.Bind(resendDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, resendDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.ResendEmailVerification(ctx, resendDto.Email); err != nil {
+			h.logger.Errorf("userUC.ResendEmailVerification: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
 // Login
 // @Tags Users
 // @Summary User login
@@ -117,7 +238,7 @@ func (h *userHandlersHTTP) Login() echo.HandlerFunc {
 		email := loginDto.Email
 		if !utils.ValidateEmail(email) {
 			h.logger.Errorf("ValidateEmail: %v", email)
-			return httpErrors.ErrorCtxResponse(c, errors.New("invalid email"), h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("email", errors.New("invalid email")), h.cfg.Http.DebugErrorsResponse)
 		}
 
 		user, err := h.userUC.Login(ctx, email, loginDto.Password)
@@ -126,109 +247,1016 @@ func (h *userHandlersHTTP) Login() echo.HandlerFunc {
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		session, err := h.sessUC.CreateSession(ctx, &models.Session{
-			UserID: user.UserID,
-		}, h.cfg.Session.Expire)
+		return h.loginResponse(c, user, models.AuthMethodPassword)
+	}
+}
+
+// RequestMagicLink
+// @Tags Users
+// @Summary Request magic link
+// @Description Email a one time login link bound to this browser by a nonce cookie, the response is the same whether the account exists or not
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserMagicLinkRequestDto true "Payload"
+// @Success 202
+// @Router /user/magic-link [post]
+func (h *userHandlersHTTP) RequestMagicLink() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		magicLinkDto := &dto.UserMagicLinkRequestDto{}
+		if err := c.Bind(magicLinkDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, magicLinkDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		nonce, err := utils.GenerateToken()
 		if err != nil {
-			h.logger.Errorf("sessUC.CreateSession: %v", err)
+			h.logger.Errorf("utils.GenerateToken: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.RequestMagicLink(ctx, magicLinkDto.Email, nonce); err != nil {
+			h.logger.Errorf("userUC.RequestMagicLink: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		c.SetCookie(h.magicLinkCookie(nonce, h.cfg.MagicLink.Expire))
+
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+// VerifyMagicLink
+// @Tags Users
+// @Summary Verify magic link
+// @Description Log in with the emailed magic link token from the browser that requested it
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserMagicLinkVerifyRequestDto true "Payload"
+// @Success 201 {object} dto.UserLoginResponseDto
+// @Router /user/magic-link/verify [post]
+func (h *userHandlersHTTP) VerifyMagicLink() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		verifyDto := &dto.UserMagicLinkVerifyRequestDto{}
+		if err := c.Bind(verifyDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, verifyDto); err != nil {
+			h.logger.WarnMsg("validate", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		accessToken, refreshToken, err := h.userUC.GenerateTokenPair(user, session)
+		nonce := ""
+		if cookie, err := c.Cookie(h.cfg.MagicLink.CookieName); err == nil {
+			nonce = cookie.Value
+		}
+
+		user, err := h.userUC.LoginWithMagicLink(ctx, verifyDto.Token, nonce)
 		if err != nil {
-			return err
+			h.logger.Errorf("userUC.LoginWithMagicLink: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		return c.JSON(http.StatusCreated, dto.UserLoginResponseDto{UserID: user.UserID, Tokens: &dto.UserRefreshTokenResponseDto{
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
-		}})
+		c.SetCookie(h.magicLinkCookie("", -1))
+
+		return h.loginResponse(c, user, models.AuthMethodMagicLink)
 	}
 }
 
-// FindAll
+// RequestLoginCode
 // @Tags Users
-// @Summary Find all users
-// @Description Admin find all users
+// @Summary Request login code
+// @Description Email a one time login code, the response is the same whether the account exists or not
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserLoginCodeRequestDto true "Payload"
+// @Success 202
+// @Router /user/login/code/request [post]
+func (h *userHandlersHTTP) RequestLoginCode() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		codeDto := &dto.UserLoginCodeRequestDto{}
+		if err := c.Bind(codeDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, codeDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.RequestLoginCode(ctx, codeDto.Email); err != nil {
+			h.logger.Errorf("userUC.RequestLoginCode: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+// LoginWithCode
+// @Tags Users
+// @Summary Login with code
+// @Description User login with email and an emailed one time code
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserLoginCodeVerifyRequestDto true "Payload"
+// @Success 201 {object} dto.UserLoginResponseDto
+// @Router /user/login/code [post]
+func (h *userHandlersHTTP) LoginWithCode() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		codeDto := &dto.UserLoginCodeVerifyRequestDto{}
+		if err := c.Bind(codeDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, codeDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user, err := h.userUC.LoginWithCode(ctx, codeDto.Email, codeDto.Code)
+		if err != nil {
+			h.logger.Errorf("userUC.LoginWithCode: %v", codeDto.Email)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return h.loginResponse(c, user, models.AuthMethodOTP)
+	}
+}
+
+// RequestSMSLoginCode
+// @Tags Users
+// @Summary Request SMS login code
+// @Description Text a one time login code to a verified phone number, the response is the same whether the number belongs to an account or not
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserSMSLoginCodeRequestDto true "Payload"
+// @Success 202
+// @Router /user/login/sms/request [post]
+func (h *userHandlersHTTP) RequestSMSLoginCode() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		codeDto := &dto.UserSMSLoginCodeRequestDto{}
+		if err := c.Bind(codeDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, codeDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.RequestSMSLoginCode(ctx, codeDto.PhoneNumber); err != nil {
+			h.logger.Errorf("userUC.RequestSMSLoginCode: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+// LoginWithSMSCode
+// @Tags Users
+// @Summary Login with SMS code
+// @Description User login with a verified phone number and a texted one time code
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserSMSLoginRequestDto true "Payload"
+// @Success 201 {object} dto.UserLoginResponseDto
+// @Router /user/login/sms [post]
+func (h *userHandlersHTTP) LoginWithSMSCode() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		loginDto := &dto.UserSMSLoginRequestDto{}
+		if err := c.Bind(loginDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, loginDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user, err := h.userUC.LoginWithSMSCode(ctx, loginDto.PhoneNumber, loginDto.Code)
+		if err != nil {
+			h.logger.Errorf("userUC.LoginWithSMSCode: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return h.loginResponse(c, user, models.AuthMethodSMS)
+	}
+}
+
+// RequestPhoneVerification
+// @Tags Users
+// @Summary Set phone number
+// @Description Set the phone number of the current user and text it a verification code, a number the user already verified stays verified
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param size query string false "pagination size"
-// @Param page query string false "pagination page"
-// @Success 200 {object} dto.UserFindResponseDto
-// @Router /user [get]
-func (h *userHandlersHTTP) FindAll() echo.HandlerFunc {
+// @Param payload body dto.UserPhoneRequestDto true "Payload"
+// @Success 202 {object} dto.UserResponseDto
+// @Router /user/me/phone [post]
+func (h *userHandlersHTTP) RequestPhoneVerification() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
-		pq := utils.NewPaginationFromQueryParams(c.QueryParam(constants.Size), c.QueryParam(constants.Page))
-		users, err := h.userUC.FindAll(ctx, pq)
+
+		phoneDto := &dto.UserPhoneRequestDto{}
+		if err := c.Bind(phoneDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, phoneDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		_, userID, _, err := h.getSessionIDFromCtx(c)
 		if err != nil {
-			h.logger.Errorf("userUC.FindAll: %v", err)
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user, err := h.userUC.RequestPhoneVerification(ctx, userUUID, phoneDto.PhoneNumber)
+		if err != nil {
+			h.logger.Errorf("userUC.RequestPhoneVerification: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusAccepted, dto.UserResponseFromModel(user))
+	}
+}
+
+// VerifyPhoneNumber
+// @Tags Users
+// @Summary Verify phone number
+// @Description Verify the phone number of the current user with the texted code
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body dto.UserPhoneVerifyRequestDto true "Payload"
+// @Success 200 {object} dto.UserResponseDto
+// @Router /user/me/phone/verify [post]
+func (h *userHandlersHTTP) VerifyPhoneNumber() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		verifyDto := &dto.UserPhoneVerifyRequestDto{}
+		if err := c.Bind(verifyDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, verifyDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		_, userID, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user, err := h.userUC.VerifyPhoneNumber(ctx, userUUID, verifyDto.Code)
+		if err != nil {
+			h.logger.Errorf("userUC.VerifyPhoneNumber: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.UserResponseFromModel(user))
+	}
+}
+
+// RequestStepUpCode
+// @Tags Users
+// @Summary Request step-up code
+// @Description Email the current user a one time code confirming sensitive operations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 202
+// @Router /user/me/step-up/request [post]
+func (h *userHandlersHTTP) RequestStepUpCode() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		_, userID, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.RequestStepUpCode(ctx, userUUID); err != nil {
+			h.logger.Errorf("userUC.RequestStepUpCode: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+// StepUp
+// @Tags Users
+// @Summary Step up
+// @Description Verify an emailed step-up code, the current session may then perform sensitive operations for a while.
+// @Description With remember_device the browser gets a trusted device cookie and later logins from it skip the second factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body dto.UserStepUpRequestDto true "Payload"
+// @Success 200 {object} dto.UserAuthContextResponseDto
+// @Router /user/me/step-up [post]
+func (h *userHandlersHTTP) StepUp() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		stepUpDto := &dto.UserStepUpRequestDto{}
+		if err := c.Bind(stepUpDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, stepUpDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		sessionID, _, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		session, err := h.sessUC.GetSessionById(ctx, sessionID)
+		if err != nil {
+			h.logger.Errorf("sessUC.GetSessionById: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		session, err = h.userUC.StepUp(ctx, session, stepUpDto.Code)
+		if err != nil {
+			h.logger.Errorf("userUC.StepUp: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		res := dto.UserAuthContextResponseDto{AuthTime: session.AuthTime, ACR: session.ACR, AMR: session.AMR}
+		if stepUpDto.RememberDevice && h.cfg.TrustedDevice.Expire > 0 {
+			name := stepUpDto.DeviceName
+			if name == "" {
+				name = c.Request().UserAgent()
+			}
+
+			token, device, err := h.userUC.TrustDevice(ctx, session.UserID, name, c.RealIP())
+			if err != nil {
+				h.logger.Errorf("userUC.TrustDevice: %v", err)
+				return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+			}
+
+			c.SetCookie(h.trustedDeviceCookie(token, h.cfg.TrustedDevice.Expire))
+			res.DeviceID = &device.DeviceID
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// Reauthenticate
+// @Tags Users
+// @Summary Reauthenticate
+// @Description Confirm the password of the current user again, the current session then counts as recently authenticated
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body dto.UserReauthenticateRequestDto true "Payload"
+// @Success 200 {object} dto.UserAuthContextResponseDto
+// @Router /user/me/reauthenticate [post]
+func (h *userHandlersHTTP) Reauthenticate() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		reauthDto := &dto.UserReauthenticateRequestDto{}
+		if err := c.Bind(reauthDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, reauthDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		sessionID, _, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		session, err := h.sessUC.GetSessionById(ctx, sessionID)
+		if err != nil {
+			h.logger.Errorf("sessUC.GetSessionById: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		session, err = h.userUC.Reauthenticate(ctx, session, reauthDto.Password)
+		if err != nil {
+			h.logger.Errorf("userUC.Reauthenticate: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.UserAuthContextResponseDto{AuthTime: session.AuthTime, ACR: session.ACR, AMR: session.AMR})
+	}
+}
+
+// FindTrustedDevices
+// @Tags Users
+// @Summary List trusted devices
+// @Description List the devices of the current user that skip the second factor when logging in
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.TrustedDevicesResponseDto
+// @Router /user/me/devices [get]
+func (h *userHandlersHTTP) FindTrustedDevices() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		_, userID, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		devices, err := h.userUC.FindTrustedDevices(ctx, userUUID)
+		if err != nil {
+			h.logger.Errorf("userUC.FindTrustedDevices: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.TrustedDevicesResponseFromModels(devices))
+	}
+}
+
+// RevokeTrustedDevice
+// @Tags Users
+// @Summary Revoke trusted device
+// @Description Forget a trusted device of the current user, logins from it ask for the second factor again
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param device_id path string true "Device ID"
+// @Success 204
+// @Router /user/me/devices/{device_id} [delete]
+func (h *userHandlersHTTP) RevokeTrustedDevice() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		deviceID, err := uuid.Parse(c.Param("device_id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("device_id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		_, userID, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.RevokeTrustedDevice(ctx, userUUID, deviceID); err != nil {
+			h.logger.Errorf("userUC.RevokeTrustedDevice: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// CreateAccessToken
+// @Tags Users
+// @Summary Create personal access token
+// @Description Create a personal access token to script against the API as the current user, sent as "Authorization: Bearer <token>".
+// @Description Scopes can not exceed the permissions of the user and the token is only returned in this response
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body dto.AccessTokenCreateRequestDto true "Payload"
+// @Success 201 {object} dto.AccessTokenCreateResponseDto
+// @Router /user/me/tokens [post]
+func (h *userHandlersHTTP) CreateAccessToken() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		createDto := &dto.AccessTokenCreateRequestDto{}
+		if err := c.Bind(createDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, createDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		_, userID, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		token, createdToken, err := h.userUC.CreateAccessToken(ctx, userUUID, createDto.Name, createDto.Scopes, time.Duration(createDto.ExpiresIn)*time.Second)
+		if err != nil {
+			h.logger.Errorf("userUC.CreateAccessToken: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusCreated, dto.AccessTokenCreateResponseDto{Token: token, AccessToken: dto.AccessTokenResponseFromModel(createdToken)})
+	}
+}
+
+// FindAccessTokens
+// @Tags Users
+// @Summary List personal access tokens
+// @Description List the personal access tokens of the current user, including expired ones
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.AccessTokensResponseDto
+// @Router /user/me/tokens [get]
+func (h *userHandlersHTTP) FindAccessTokens() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		_, userID, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		tokens, err := h.userUC.FindAccessTokens(ctx, userUUID)
+		if err != nil {
+			h.logger.Errorf("userUC.FindAccessTokens: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.AccessTokensResponseFromModels(tokens))
+	}
+}
+
+// RevokeAccessToken
+// @Tags Users
+// @Summary Revoke personal access token
+// @Description Revoke a personal access token of the current user, it is rejected immediately
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param token_id path string true "Token ID"
+// @Success 204
+// @Router /user/me/tokens/{token_id} [delete]
+func (h *userHandlersHTTP) RevokeAccessToken() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		tokenID, err := uuid.Parse(c.Param("token_id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("token_id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		_, userID, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.RevokeAccessToken(ctx, userUUID, tokenID); err != nil {
+			h.logger.Errorf("userUC.RevokeAccessToken: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// Invite
+// @Tags Users
+// @Summary Invite user
+// @Description Admin create a pending user without a password and email it a link to choose one
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body dto.UserInviteRequestDto true "Payload"
+// @Success 201 {object} dto.UserResponseDto
+// @Router /user/invitations [post]
+func (h *userHandlersHTTP) Invite() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		inviteDto := &dto.UserInviteRequestDto{}
+		if err := c.Bind(inviteDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, inviteDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		invitedUser, err := h.userUC.Invite(ctx, &models.User{
+			Email:     strings.ToLower(strings.TrimSpace(inviteDto.Email)),
+			FirstName: strings.TrimSpace(inviteDto.FirstName),
+			LastName:  strings.TrimSpace(inviteDto.LastName),
+			Role:      inviteDto.Role,
+		})
+		if err != nil {
+			h.logger.Errorf("userUC.Invite: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusCreated, dto.UserResponseFromModel(invitedUser))
+	}
+}
+
+// ResendInvitation
+// @Tags Users
+// @Summary Resend invitation
+// @Description Admin email a new invitation link to an invited user, the previous link stops working
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Success 202
+// @Router /user/{id}/invitation/resend [post]
+func (h *userHandlersHTTP) ResendInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.ResendInvitation(ctx, userUUID); err != nil {
+			h.logger.Errorf("userUC.ResendInvitation: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+// RevokeInvitation
+// @Tags Users
+// @Summary Revoke invitation
+// @Description Admin delete an invited user who did not accept yet, its link stops working
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Success 204
+// @Router /user/{id}/invitation [delete]
+func (h *userHandlersHTTP) RevokeInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.userUC.RevokeInvitation(ctx, userUUID); err != nil {
+			h.logger.Errorf("userUC.RevokeInvitation: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// AcceptInvitation
+// @Tags Users
+// @Summary Accept invitation
+// @Description Choose the password of an invited user with the emailed token, the account is activated and logged in
+// @Accept json
+// @Produce json
+// @Param payload body dto.UserAcceptInvitationRequestDto true "Payload"
+// @Success 201 {object} dto.UserLoginResponseDto
+// @Router /user/invitations/accept [post]
+func (h *userHandlersHTTP) AcceptInvitation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		acceptDto := &dto.UserAcceptInvitationRequestDto{}
+		if err := c.Bind(acceptDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, acceptDto); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user, err := h.userUC.AcceptInvitation(ctx, acceptDto.Token, acceptDto.Password)
+		if err != nil {
+			h.logger.Errorf("userUC.AcceptInvitation: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return h.loginResponse(c, user, models.AuthMethodPassword)
+	}
+}
+
+// FindAll
+// @Tags Users
+// @Summary Find all users
+// @Description Find users matching the filters, sorted by a whitelisted field
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param size query string false "pagination size"
+// @Param page query string false "pagination page"
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page, replaces page"
+// @Param role query string false "user role" Enums(admin, user)
+// @Param search query string false "search email, first and last name"
+// @Param created_from query string false "created at or after, RFC 3339"
+// @Param created_to query string false "created before, RFC 3339"
+// @Param verified query boolean false "email verification status"
+// @Param include_deleted query boolean false "include soft deleted users, admin only"
+// @Param orderBy query string false "sort field, prefixed with - for descending" Enums(created_at, -created_at, updated_at, -updated_at, email, -email, first_name, -first_name, last_name, -last_name, role, -role)
+// @Param fields query string false "comma separated user fields to return"
+// @Success 200 {object} dto.UserFindResponseDto
+// @Router /user [get]
+func (h *userHandlersHTTP) FindAll() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		pq := utils.NewPaginationFromQueryParams(c.QueryParam(constants.Size), c.QueryParam(constants.Page))
+		pq.SetOrderBy(c.QueryParam(constants.OrderBy))
+		pq.SetCursor(c.QueryParam(constants.Cursor))
+
+		filter, err := h.userFilterFromQuery(c)
+		if err != nil {
+			h.logger.WarnMsg("userFilterFromQuery", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		usersList, err := h.userUC.FindAll(ctx, filter, pq)
+		if err != nil {
+			h.logger.Errorf("userUC.FindAll: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		users := make([]*dto.UserResponseDto, 0, len(usersList.Users))
+		for i := range usersList.Users {
+			users = append(users, dto.UserResponseFromModel(&usersList.Users[i]))
+		}
+
+		data, err := h.selectFields(c, users)
+		if err != nil {
+			h.logger.WarnMsg("selectFields", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField(constants.Fields, err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.UserFindResponseDto{
+			Data: data,
+			Meta: utils.PaginationMetaDto{
+				Limit:      pq.GetLimit(),
+				Offset:     pq.GetOffset(),
+				Page:       pq.GetPage(),
+				TotalCount: usersList.TotalCount,
+				TotalPages: usersList.TotalPages,
+				HasMore:    usersList.HasMore,
+				NextCursor: usersList.NextCursor,
+				PrevCursor: usersList.PrevCursor,
+			},
+		})
+	}
+}
+
+// FindById
+// @Tags Users
+// @Summary Find user
+// @Description Find existing user by id
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param fields query string false "comma separated user fields to return"
+// @Success 200 {object} dto.UserResponseDto
+// @Router /user/{id} [get]
+func (h *userHandlersHTTP) FindById() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user, err := h.userUC.CachedFindById(ctx, userUUID)
+		if err != nil {
+			h.logger.Errorf("userUC.CachedFindById: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		data, err := h.selectFields(c, dto.UserResponseFromModel(user))
+		if err != nil {
+			h.logger.WarnMsg("selectFields", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField(constants.Fields, err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		c.Response().Header().Set(constants.HeaderETag, utils.FormatETag(user.Version))
+		return c.JSON(http.StatusOK, data)
+	}
+}
+
+// UpdateById
+// @Tags Users
+// @Summary Update user
+// @Description Update existing user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param If-Match header string true "ETag of the user being updated"
+// @Param payload body dto.UserUpdateRequestDto true "Payload"
+// @Success 200 {object} dto.UserResponseDto
+// @Router /user/{id} [put]
+func (h *userHandlersHTTP) UpdateById() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		sessionID, userID, role, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if role != models.UserRoleAdmin && userID != userUUID.String() {
+			h.logger.Warnf("models.UserRoleAdmin: %v", role)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrForbidden, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		version, err := h.ifMatchVersion(c)
+		if err != nil {
+			h.logger.WarnMsg("ifMatchVersion", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		updateDto := &dto.UserUpdateRequestDto{}
+		if err := c.Bind(updateDto); err != nil {
+			h.logger.WarnMsg("bind", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := h.v.StructCtx(ctx, updateDto); err != nil {
+			h.logger.WarnMsg("validate", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		return c.JSON(http.StatusOK, dto.UserFindResponseDto{
-			Data: users,
-			Meta: utils.PaginationMetaDto{
-				Limit:  pq.GetLimit(),
-				Offset: pq.GetOffset(),
-				Page:   pq.GetPage(),
-			},
-		})
-	}
-}
+		if updateDto.Password != nil {
+			if err := h.requireStepUp(c, sessionID); err != nil {
+				h.logger.WarnMsg("requireStepUp", err)
+				return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+			}
+		}
 
-// FindById
-// @Tags Users
-// @Summary Find user
-// @Description Find existing user by id
-// @Accept json
-// @Produce json
-// @Security ApiKeyAuth
-// @Success 200 {object} dto.UserResponseDto
-// @Router /user/{id} [get]
-func (h *userHandlersHTTP) FindById() echo.HandlerFunc {
-	return func(c echo.Context) error {
-		ctx := c.Request().Context()
+		user, err := h.userUC.FindById(ctx, userUUID)
+		if err != nil {
+			h.logger.Errorf("userUC.FindById: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
 
-		userUUID, err := uuid.Parse(c.Param("id"))
+		if user.Version != version {
+			h.logger.Warnf("user.Version: %v, If-Match: %v", user.Version, version)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrVersionMismatch, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		user, err = h.updateReqToUserModel(user, updateDto)
 		if err != nil {
-			h.logger.WarnMsg("uuid.FromString", err)
+			h.logger.Errorf("updateReqToUserModel: %v", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		user, err := h.userUC.CachedFindById(ctx, userUUID)
+		user, err = h.userUC.UpdateById(ctx, user)
 		if err != nil {
-			h.logger.Errorf("userUC.CachedFindById: %v", err)
+			h.logger.Errorf("userUC.UpdateById: %v", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
+		if updateDto.Password != nil {
+			if err := h.userUC.RevokeTrustedDevices(ctx, userUUID); err != nil {
+				h.logger.Errorf("userUC.RevokeTrustedDevices: %v", err)
+			}
+		}
+
+		c.Response().Header().Set(constants.HeaderETag, utils.FormatETag(user.Version))
 		return c.JSON(http.StatusOK, dto.UserResponseFromModel(user))
 	}
 }
 
-// UpdateById
+// PatchById
 // @Tags Users
-// @Summary Update user
-// @Description Update existing user
-// @Accept json
+// @Summary Patch user
+// @Description Apply a JSON merge patch (RFC 7396) or a JSON patch (RFC 6902) to the mutable fields of an existing user, only admins may patch email and role
+// @Accept application/merge-patch+json,application/json-patch+json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "User ID"
-// @Param payload body dto.UserUpdateRequestDto true "Payload"
+// @Param If-Match header string true "ETag of the user being patched"
+// @Param payload body object true "Merge patch document or json patch operations"
 // @Success 200 {object} dto.UserResponseDto
-// @Router /user/{id} [put]
-func (h *userHandlersHTTP) UpdateById() echo.HandlerFunc {
+// @Router /user/{id} [patch]
+func (h *userHandlersHTTP) PatchById() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 
 		userUUID, err := uuid.Parse(c.Param("id"))
 		if err != nil {
 			h.logger.WarnMsg("uuid.FromString", err)
-			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
 		}
 
-		_, userID, role, err := h.getSessionIDFromCtx(c)
+		sessionID, userID, role, err := h.getSessionIDFromCtx(c)
 		if err != nil {
 			h.logger.Errorf("getSessionIDFromCtx: %v", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
@@ -236,17 +1264,24 @@ func (h *userHandlersHTTP) UpdateById() echo.HandlerFunc {
 
 		if role != models.UserRoleAdmin && userID != userUUID.String() {
 			h.logger.Warnf("models.UserRoleAdmin: %v", role)
-			return httpErrors.NewForbiddenError(c, nil, h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrForbidden, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		updateDto := &dto.UserUpdateRequestDto{}
-		if err := c.Bind(updateDto); err != nil {
-			h.logger.WarnMsg("bind", err)
+		version, err := h.ifMatchVersion(c)
+		if err != nil {
+			h.logger.WarnMsg("ifMatchVersion", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		if err := h.v.StructCtx(ctx, updateDto); err != nil {
-			h.logger.WarnMsg("validate", err)
+		mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+		if err != nil || (mediaType != constants.MIMEApplicationMergePatchJSON && mediaType != constants.MIMEApplicationJSONPatchJSON) {
+			h.logger.Warnf("mime.ParseMediaType: %v", c.Request().Header.Get(echo.HeaderContentType))
+			return httpErrors.ErrorCtxResponse(c, echo.ErrUnsupportedMediaType, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		patch, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			h.logger.WarnMsg("io.ReadAll", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
@@ -256,9 +1291,36 @@ func (h *userHandlersHTTP) UpdateById() echo.HandlerFunc {
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		user, err = h.updateReqToUserModel(user, updateDto)
+		if user.Version != version {
+			h.logger.Warnf("user.Version: %v, If-Match: %v", user.Version, version)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrVersionMismatch, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		patchDoc, err := h.applyUserPatch(dto.UserPatchDocumentFromModel(user), mediaType, patch, role)
 		if err != nil {
-			h.logger.Errorf("updateReqToUserModel: %v", err)
+			h.logger.WarnMsg("applyUserPatch", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if patchDoc.Password != nil {
+			if err := h.requireStepUp(c, sessionID); err != nil {
+				h.logger.WarnMsg("requireStepUp", err)
+				return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+			}
+		} else if !strings.EqualFold(strings.TrimSpace(patchDoc.Email), user.Email) {
+			if err := h.requireRecentAuth(c, sessionID); err != nil {
+				h.logger.WarnMsg("requireRecentAuth", err)
+				return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+			}
+		}
+
+		if err := h.v.StructCtx(ctx, patchDoc); err != nil {
+			h.logger.WarnMsg("validate", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		if err := patchDoc.ApplyTo(user); err != nil {
+			h.logger.Errorf("patchDoc.ApplyTo: %v", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
@@ -268,6 +1330,13 @@ func (h *userHandlersHTTP) UpdateById() echo.HandlerFunc {
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
+		if patchDoc.Password != nil {
+			if err := h.userUC.RevokeTrustedDevices(ctx, userUUID); err != nil {
+				h.logger.Errorf("userUC.RevokeTrustedDevices: %v", err)
+			}
+		}
+
+		c.Response().Header().Set(constants.HeaderETag, utils.FormatETag(user.Version))
 		return c.JSON(http.StatusOK, dto.UserResponseFromModel(user))
 	}
 }
@@ -275,7 +1344,7 @@ func (h *userHandlersHTTP) UpdateById() echo.HandlerFunc {
 // DeleteById
 // @Tags Users
 // @Summary Delete user
-// @Description Delete existing user
+// @Description Soft delete existing user and revoke its sessions
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
@@ -289,7 +1358,7 @@ func (h *userHandlersHTTP) DeleteById() echo.HandlerFunc {
 		userUUID, err := uuid.Parse(c.Param("id"))
 		if err != nil {
 			h.logger.WarnMsg("uuid.FromString", err)
-			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
 		}
 
 		if err := h.userUC.DeleteById(ctx, userUUID); err != nil {
@@ -301,6 +1370,110 @@ func (h *userHandlersHTTP) DeleteById() echo.HandlerFunc {
 	}
 }
 
+// RestoreById
+// @Tags Users
+// @Summary Restore user
+// @Description Restore soft deleted user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.UserResponseDto
+// @Router /user/{id}/restore [post]
+func (h *userHandlersHTTP) RestoreById() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		restoredUser, err := h.userUC.RestoreById(ctx, userUUID)
+		if err != nil {
+			h.logger.Errorf("userUC.RestoreById: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return c.JSON(http.StatusOK, dto.UserResponseFromModel(restoredUser))
+	}
+}
+
+// SuspendById
+// @Tags Users
+// @Summary Suspend user
+// @Description Admin suspend user and revoke its sessions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param payload body dto.UserStatusRequestDto false "Payload"
+// @Success 200 {object} dto.UserResponseDto
+// @Router /user/{id}/suspend [post]
+func (h *userHandlersHTTP) SuspendById() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return h.changeStatus(c, userUUID, models.UserStatusSuspended)
+	}
+}
+
+// ReactivateById
+// @Tags Users
+// @Summary Reactivate user
+// @Description Admin reactivate suspended, locked or deactivated user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param payload body dto.UserStatusRequestDto false "Payload"
+// @Success 200 {object} dto.UserResponseDto
+// @Router /user/{id}/reactivate [post]
+func (h *userHandlersHTTP) ReactivateById() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField("id", err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return h.changeStatus(c, userUUID, models.UserStatusActive)
+	}
+}
+
+// DeactivateMe
+// @Tags Users
+// @Summary Deactivate me
+// @Description Deactivate current user and revoke its sessions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param payload body dto.UserStatusRequestDto false "Payload"
+// @Success 200 {object} dto.UserResponseDto
+// @Router /user/me/deactivate [post]
+func (h *userHandlersHTTP) DeactivateMe() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		_, userID, _, err := h.getSessionIDFromCtx(c)
+		if err != nil {
+			h.logger.Errorf("getSessionIDFromCtx: %v", err)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			h.logger.WarnMsg("uuid.FromString", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		return h.changeStatus(c, userUUID, models.UserStatusDeactivated)
+	}
+}
+
 // GetMe
 // @Tags Users
 // @Summary Find me
@@ -308,6 +1481,7 @@ func (h *userHandlersHTTP) DeleteById() echo.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
+// @Param fields query string false "comma separated user fields to return"
 // @Success 200 {object} dto.UserResponseDto
 // @Router /user/me [get]
 func (h *userHandlersHTTP) GetMe() echo.HandlerFunc {
@@ -322,10 +1496,7 @@ func (h *userHandlersHTTP) GetMe() echo.HandlerFunc {
 		session, err := h.sessUC.GetSessionById(ctx, sessID)
 		if err != nil {
 			h.logger.Errorf("sessUC.GetSessionById: %v", err)
-			if errors.Is(err, redis.Nil) {
-				return httpErrors.NewUnauthorizedError(c, nil, h.cfg.Http.DebugErrorsResponse)
-			}
-			return httpErrors.NewUnauthorizedError(c, err, h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
 		user, err := h.userUC.CachedFindById(ctx, session.UserID)
@@ -334,7 +1505,19 @@ func (h *userHandlersHTTP) GetMe() echo.HandlerFunc {
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
-		return c.JSON(http.StatusOK, dto.UserResponseFromModel(user))
+		if !user.IsActive() {
+			h.logger.Warnf("user.IsActive: %v", user.Status)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrUserInactive, h.cfg.Http.DebugErrorsResponse)
+		}
+
+		data, err := h.selectFields(c, dto.UserResponseFromModel(user))
+		if err != nil {
+			h.logger.WarnMsg("selectFields", err)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.InvalidField(constants.Fields, err), h.cfg.Http.DebugErrorsResponse)
+		}
+
+		c.Response().Header().Set(constants.HeaderETag, utils.FormatETag(user.Version))
+		return c.JSON(http.StatusOK, data)
 	}
 }
 
@@ -395,41 +1578,49 @@ func (h *userHandlersHTTP) RefreshToken() echo.HandlerFunc {
 
 		if err != nil {
 			h.logger.Warnf("jwt.Parse")
-			return httpErrors.ErrorCtxResponse(c, errors.New("invalid refresh token"), h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
 		}
 
 		if !token.Valid {
 			h.logger.Warnf("token.Valid")
-			return httpErrors.ErrorCtxResponse(c, errors.New("invalid refresh token"), h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
 			h.logger.Warnf("jwt.MapClaims: %+v", token.Claims)
-			return httpErrors.ErrorCtxResponse(c, errors.New("invalid refresh token"), h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
 		}
 
 		sessID, ok := claims["session_id"].(string)
 		if !ok {
 			h.logger.Warnf("session_id: %+v", claims)
-			return httpErrors.ErrorCtxResponse(c, errors.New("invalid refresh token"), h.cfg.Http.DebugErrorsResponse)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
 		}
 
 		session, err := h.sessUC.GetSessionById(ctx, sessID)
 		if err != nil {
 			h.logger.Errorf("sessUC.GetSessionById: %v", err)
-			if errors.Is(err, redis.Nil) {
-				return httpErrors.NewUnauthorizedError(c, nil, h.cfg.Http.DebugErrorsResponse)
-			}
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
+		// OAuth clients refresh their sessions at the token endpoint, which authenticates them
+		if session.ClientID != "" {
+			h.logger.Warnf("session.ClientID: %v", session.ClientID)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+		}
+
 		user, err := h.userUC.FindById(ctx, session.UserID)
 		if err != nil {
 			h.logger.Errorf("userUC.FindById: %v", err)
 			return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
 		}
 
+		if !user.IsActive() {
+			h.logger.Warnf("user.IsActive: %v", user.Status)
+			return httpErrors.ErrorCtxResponse(c, domain_errors.ErrUserInactive, h.cfg.Http.DebugErrorsResponse)
+		}
+
 		accessToken, refreshToken, err := h.userUC.GenerateTokenPair(user, sessID)
 		if err != nil {
 			return err
@@ -443,39 +1634,249 @@ func (h *userHandlersHTTP) RefreshToken() echo.HandlerFunc {
 }
 
 func (h *userHandlersHTTP) getSessionIDFromCtx(c echo.Context) (sessionID string, userID string, role string, err error) {
+	if middlewares.OAuthClientDenied(c) {
+		return "", "", "", domain_errors.ErrForbidden
+	}
+
 	user, ok := c.Get("user").(*jwt.Token)
 	if !ok {
 		h.logger.Warnf("jwt.Token: %+v", c.Get("user"))
-		return "", "", "", errors.New("invalid token header")
+		return "", "", "", domain_errors.ErrInvalidToken
 	}
 
 	claims, ok := user.Claims.(jwt.MapClaims)
 	if !ok {
 		h.logger.Warnf("jwt.MapClaims: %+v", c.Get("user"))
-		return "", "", "", errors.New("invalid token header")
+		return "", "", "", domain_errors.ErrInvalidToken
 	}
 
 	sessionID, ok = claims["session_id"].(string)
 	if !ok {
 		h.logger.Warnf("session_id: %+v", claims)
-		return "", "", "", errors.New("invalid token header")
+		return "", "", "", domain_errors.ErrInvalidToken
 	}
 
 	userID, ok = claims["user_id"].(string)
 	if !ok {
 		h.logger.Warnf("user_id: %+v", claims)
-		return "", "", "", errors.New("invalid token header")
+		return "", "", "", domain_errors.ErrInvalidToken
 	}
 
 	role, ok = claims["role"].(string)
 	if !ok {
 		h.logger.Warnf("role: %+v", claims)
-		return "", "", "", errors.New("invalid token header")
+		return "", "", "", domain_errors.ErrInvalidToken
 	}
 
 	return sessionID, userID, role, nil
 }
 
+// loginResponse create a session for the user authenticated with methods and respond with its token pair
+func (h *userHandlersHTTP) loginResponse(c echo.Context, user *models.User, methods ...string) error {
+	// a trusted device only vouches for the login, sensitive operations still need a fresh step-up
+	if h.trustedDevice(c, user.UserID) {
+		methods = append(methods, models.AuthMethodTrustedDevice)
+	}
+
+	session, err := h.sessUC.CreateSession(c.Request().Context(), &models.Session{
+		UserID:   user.UserID,
+		AuthTime: time.Now(),
+		ACR:      models.SessionACRBasic,
+		AMR:      methods,
+	}, h.cfg.Session.Expire)
+	if err != nil {
+		h.logger.Errorf("sessUC.CreateSession: %v", err)
+		return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+	}
+
+	accessToken, refreshToken, err := h.userUC.GenerateTokenPair(user, session)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, dto.UserLoginResponseDto{UserID: user.UserID, Tokens: &dto.UserRefreshTokenResponseDto{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}})
+}
+
+// magicLinkCookie nonce cookie binding magic links to the browser that requested them, a negative maxAge removes it
+func (h *userHandlersHTTP) magicLinkCookie(nonce string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     h.cfg.MagicLink.CookieName,
+		Value:    nonce,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   h.cfg.Cookie.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// trustedDevice reports whether the request carries the cookie of a device the user trusts
+func (h *userHandlersHTTP) trustedDevice(c echo.Context, userID uuid.UUID) bool {
+	cookie, err := c.Cookie(h.cfg.TrustedDevice.CookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	if _, err := h.userUC.TouchTrustedDevice(c.Request().Context(), userID, cookie.Value, c.RealIP()); err != nil {
+		if !errors.Is(err, domain_errors.ErrTrustedDeviceNotFound) {
+			h.logger.Errorf("userUC.TouchTrustedDevice: %v", err)
+		}
+		return false
+	}
+	return true
+}
+
+// trustedDeviceCookie signed token of a trusted device, a negative maxAge removes it
+func (h *userHandlersHTTP) trustedDeviceCookie(token string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     h.cfg.TrustedDevice.CookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   h.cfg.Cookie.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// requireStepUp reject the request unless its session verified a step-up code recently
+func (h *userHandlersHTTP) requireStepUp(c echo.Context, sessionID string) error {
+	session, err := h.sessUC.GetSessionById(c.Request().Context(), sessionID)
+	if err != nil {
+		return err
+	}
+
+	if !session.SteppedUp(time.Duration(h.cfg.OTP.StepUpMaxAge) * time.Second) {
+		return domain_errors.ErrStepUpRequired
+	}
+	return nil
+}
+
+// requireRecentAuth reject the request unless the user of its session authenticated recently
+func (h *userHandlersHTTP) requireRecentAuth(c echo.Context, sessionID string) error {
+	session, err := h.sessUC.GetSessionById(c.Request().Context(), sessionID)
+	if err != nil {
+		return err
+	}
+
+	maxAge := time.Duration(h.cfg.Session.ReauthMaxAge) * time.Second
+	if !session.AuthenticatedWithin(maxAge) {
+		return domain_errors.ReauthRequired(maxAge)
+	}
+	return nil
+}
+
+// changeStatus move the user to status on behalf of the session user, the optional body carries the reason
+func (h *userHandlersHTTP) changeStatus(c echo.Context, userUUID uuid.UUID, status string) error {
+	ctx := c.Request().Context()
+
+	_, actorID, _, err := h.getSessionIDFromCtx(c)
+	if err != nil {
+		h.logger.Errorf("getSessionIDFromCtx: %v", err)
+		return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+	}
+	actorUUID, err := uuid.Parse(actorID)
+	if err != nil {
+		h.logger.WarnMsg("uuid.FromString", err)
+		return httpErrors.ErrorCtxResponse(c, domain_errors.ErrInvalidToken, h.cfg.Http.DebugErrorsResponse)
+	}
+
+	statusDto := &dto.UserStatusRequestDto{}
+	if err := c.Bind(statusDto); err != nil {
+		h.logger.WarnMsg("bind", err)
+		return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+	}
+
+	if err := h.v.StructCtx(ctx, statusDto); err != nil {
+		h.logger.WarnMsg("validate", err)
+		return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+	}
+
+	user, err := h.userUC.ChangeStatus(ctx, &models.UserStatusChange{
+		UserID:  userUUID,
+		Status:  status,
+		Reason:  strings.TrimSpace(statusDto.Reason),
+		ActorID: &actorUUID,
+	})
+	if err != nil {
+		h.logger.Errorf("userUC.ChangeStatus: %v", err)
+		return httpErrors.ErrorCtxResponse(c, err, h.cfg.Http.DebugErrorsResponse)
+	}
+
+	return c.JSON(http.StatusOK, dto.UserResponseFromModel(user))
+}
+
+func (h *userHandlersHTTP) userFilterFromQuery(c echo.Context) (*models.UserFilter, error) {
+	filter := &models.UserFilter{
+		Role:   strings.ToLower(strings.TrimSpace(c.QueryParam(constants.Role))),
+		Search: strings.TrimSpace(c.QueryParam(constants.Search)),
+	}
+	if filter.Role != "" && filter.Role != models.UserRoleAdmin && filter.Role != models.UserRoleUser {
+		return nil, domain_errors.InvalidField(constants.Role, fmt.Errorf("role invalid: %v", filter.Role))
+	}
+
+	for param, dst := range map[string]**time.Time{
+		constants.CreatedFrom: &filter.CreatedFrom,
+		constants.CreatedTo:   &filter.CreatedTo,
+	} {
+		if value := c.QueryParam(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, domain_errors.InvalidField(param, err)
+			}
+			*dst = &t
+		}
+	}
+
+	if value := c.QueryParam(constants.Verified); value != "" {
+		verified, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, domain_errors.InvalidField(constants.Verified, err)
+		}
+		filter.Verified = &verified
+	}
+
+	if value := c.QueryParam(constants.IncludeDeleted); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, domain_errors.InvalidField(constants.IncludeDeleted, err)
+		}
+		if includeDeleted {
+			role, err := h.callerRole(c)
+			if err != nil {
+				return nil, err
+			}
+			if role != models.UserRoleAdmin {
+				return nil, domain_errors.ErrForbidden
+			}
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	return filter, nil
+}
+
+// callerRole role the request acts with, service account keys already passed the scope check of the route and act as admins
+func (h *userHandlersHTTP) callerRole(c echo.Context) (string, error) {
+	ctx := c.Request().Context()
+	if _, ok := serviceaccount.FromContext(ctx); ok {
+		return models.UserRoleAdmin, nil
+	}
+	if _, tokenUser, ok := accesstoken.FromContext(ctx); ok {
+		return tokenUser.Role, nil
+	}
+
+	_, _, role, err := h.getSessionIDFromCtx(c)
+	return role, err
+}
+
+func (h *userHandlersHTTP) selectFields(c echo.Context, v interface{}) (interface{}, error) {
+	return utils.SelectFields(v, utils.ParseFields(c.QueryParam(constants.Fields)))
+}
+
 func (h *userHandlersHTTP) registerReqToUserModel(r *dto.UserRegisterRequestDto) (*models.User, error) {
 	userCandidate := &models.User{
 		Email:     r.Email,
@@ -493,6 +1894,79 @@ func (h *userHandlersHTTP) registerReqToUserModel(r *dto.UserRegisterRequestDto)
 	return userCandidate, nil
 }
 
+// ifMatchVersion user version required by the If-Match header of the request
+func (h *userHandlersHTTP) ifMatchVersion(c echo.Context) (int64, error) {
+	ifMatch := c.Request().Header.Get(constants.HeaderIfMatch)
+	if ifMatch == "" {
+		return 0, domain_errors.Validation(domain_errors.FieldViolation{
+			Field:       constants.HeaderIfMatch,
+			Code:        "required",
+			Description: "If-Match header with the ETag of the user is required",
+		})
+	}
+
+	version, err := utils.ParseETag(ifMatch)
+	if err != nil {
+		return 0, domain_errors.InvalidField(constants.HeaderIfMatch, err)
+	}
+
+	return version, nil
+}
+
+// applyUserPatch apply a merge patch or json patch to doc, changing only fields the role may patch
+func (h *userHandlersHTTP) applyUserPatch(doc *dto.UserPatchDocument, mediaType string, patch []byte, role string) (*dto.UserPatchDocument, error) {
+	original, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch mediaType {
+	case constants.MIMEApplicationMergePatchJSON:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case constants.MIMEApplicationJSONPatchJSON:
+		var operations jsonpatch.Patch
+		if operations, err = jsonpatch.DecodePatch(patch); err == nil {
+			patched, err = operations.Apply(original)
+		}
+	}
+	if err != nil {
+		return nil, domain_errors.InvalidField("patch", err)
+	}
+
+	var originalFields, patchedFields map[string]interface{}
+	if err := json.Unmarshal(original, &originalFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, &patchedFields); err != nil {
+		return nil, domain_errors.InvalidField("patch", err)
+	}
+
+	for field := range patchedFields {
+		if _, ok := originalFields[field]; !ok && field != "password" {
+			return nil, domain_errors.Validation(domain_errors.FieldViolation{
+				Field:       field,
+				Code:        "readonly",
+				Description: fmt.Sprintf("Field '%s' can not be patched", field),
+			})
+		}
+	}
+	for _, fields := range []map[string]interface{}{originalFields, patchedFields} {
+		for field := range fields {
+			if !reflect.DeepEqual(originalFields[field], patchedFields[field]) && !dto.CanPatchUserField(role, field) {
+				return nil, domain_errors.ErrForbidden.Wrap(fmt.Errorf("field %q can not be patched by %s", field, role))
+			}
+		}
+	}
+
+	patchedDoc := &dto.UserPatchDocument{}
+	if err := json.Unmarshal(patched, patchedDoc); err != nil {
+		return nil, domain_errors.InvalidField("patch", err)
+	}
+
+	return patchedDoc, nil
+}
+
 func (h *userHandlersHTTP) updateReqToUserModel(updateCandidate *models.User, r *dto.UserUpdateRequestDto) (*models.User, error) {
 
 	if r.FirstName != nil {
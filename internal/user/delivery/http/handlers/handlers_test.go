@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,13 +20,19 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dinorain/useraja/config"
+	mockIdempotency "github.com/dinorain/useraja/internal/idempotency/mock"
 	"github.com/dinorain/useraja/internal/middlewares"
 	"github.com/dinorain/useraja/internal/models"
+	mockRateLimit "github.com/dinorain/useraja/internal/ratelimit/mock"
 	mockSessUC "github.com/dinorain/useraja/internal/session/mock"
 	"github.com/dinorain/useraja/internal/user/delivery/http/dto"
 	"github.com/dinorain/useraja/internal/user/mock"
+	"github.com/dinorain/useraja/pkg/constants"
 	"github.com/dinorain/useraja/pkg/converter"
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
 	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/utils"
 )
 
 func TestUsersHandler_Register(t *testing.T) {
@@ -37,7 +45,7 @@ func TestUsersHandler_Register(t *testing.T) {
 	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
 
 	appLogger := logger.NewAppLogger(nil)
-	mw := middlewares.NewMiddlewareManager(appLogger, nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
 
 	e := echo.New()
 	v := validator.New()
@@ -70,6 +78,97 @@ func TestUsersHandler_Register(t *testing.T) {
 	require.NoError(t, handlers.Register()(ctx))
 	require.Equal(t, http.StatusCreated, res.Code)
 	require.Equal(t, buf.String(), res.Body.String())
+
+	t.Run("Problem details", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		_ = json.NewEncoder(body).Encode(&dto.UserRegisterRequestDto{FirstName: "FirstName", LastName: "LastName", Role: "user"})
+
+		req := httptest.NewRequest(http.MethodPost, "/user", body)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.8")
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.Set(httpErrors.ErrorFormatKey, httpErrors.ErrorFormatProblem)
+		res.Header().Set(echo.HeaderXRequestID, "request-id")
+
+		require.NoError(t, handlers.Register()(ctx))
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, httpErrors.MIMEApplicationProblemJSON, res.Header().Get(echo.HeaderContentType))
+
+		problem := httpErrors.Problem{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &problem))
+		require.Equal(t, domain_errors.ReasonValidationFailed, problem.Code)
+		require.Equal(t, "Validasi gagal", problem.Title)
+		require.Equal(t, "request-id", problem.Instance)
+		require.Len(t, problem.Errors, 2)
+		require.Equal(t, "required", problem.Errors[0].Code)
+	})
+}
+
+func TestUsersHandler_Signup(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	rateLimitRepo := mockRateLimit.NewMockRateLimitRepository(ctrl)
+
+	cfg := &config.Config{Signup: config.Signup{RateLimit: 5, RateLimitWindow: 3600}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, rateLimitRepo, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/user/signup", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+
+		h := mw.RateLimit("signup", cfg.Signup.RateLimit, time.Hour)(handlers.Signup())
+		require.NoError(t, h(ctx))
+		return res
+	}
+
+	body := `{"email": "Email@gmail.com", "first_name": "FirstName", "last_name": "LastName", "password": "123456", "role": "admin"}`
+
+	t.Run("Role is not taken from the request", func(t *testing.T) {
+		rateLimitRepo.EXPECT().Allow(gomock.Any(), gomock.Any(), 5, time.Hour).Return(true, time.Duration(0), nil)
+		userUC.EXPECT().Signup(gomock.Any(), gomock.Any(), "").DoAndReturn(func(ctx context.Context, user *models.User, inviteCode string) (*models.User, error) {
+			require.Empty(t, user.Role)
+			require.Equal(t, "email@gmail.com", user.Email)
+			require.NoError(t, user.ComparePasswords("123456"))
+			return &models.User{UserID: uuid.New(), Email: user.Email, Role: models.UserRoleUser, Status: models.UserStatusPending}, nil
+		})
+
+		res := serve(body)
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		userDto := &dto.UserResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), userDto))
+		require.Equal(t, models.UserRoleUser, userDto.Role)
+		require.Equal(t, models.UserStatusPending, userDto.Status)
+	})
+
+	t.Run("Signup policy", func(t *testing.T) {
+		rateLimitRepo.EXPECT().Allow(gomock.Any(), gomock.Any(), 5, time.Hour).Return(true, time.Duration(0), nil)
+		userUC.EXPECT().Signup(gomock.Any(), gomock.Any(), "").Return(nil, domain_errors.ErrEmailDomainNotAllowed)
+
+		res := serve(body)
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("Rate limited", func(t *testing.T) {
+		rateLimitRepo.EXPECT().Allow(gomock.Any(), gomock.Any(), 5, time.Hour).Return(false, time.Minute, nil)
+
+		res := serve(body)
+		require.Equal(t, http.StatusTooManyRequests, res.Code)
+		require.Equal(t, "60", res.Header().Get("Retry-After"))
+	})
 }
 
 func TestUsersHandler_Login(t *testing.T) {
@@ -82,7 +181,7 @@ func TestUsersHandler_Login(t *testing.T) {
 	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
 
 	appLogger := logger.NewAppLogger(nil)
-	mw := middlewares.NewMiddlewareManager(appLogger, nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
 
 	e := echo.New()
 	v := validator.New()
@@ -112,12 +211,197 @@ func TestUsersHandler_Login(t *testing.T) {
 	}
 
 	userUC.EXPECT().Login(gomock.Any(), reqDto.Email, reqDto.Password).AnyTimes().Return(mockUser, nil)
-	sessUC.EXPECT().CreateSession(gomock.Any(), &models.Session{UserID: mockUser.UserID}, cfg.Session.Expire).AnyTimes().Return("s", nil)
+	sessUC.EXPECT().CreateSession(gomock.Any(), loginSession(mockUser.UserID), cfg.Session.Expire).AnyTimes().Return("s", nil)
 	userUC.EXPECT().GenerateTokenPair(gomock.Any(), gomock.Any()).AnyTimes().Return("rt", "at", nil)
 	require.NoError(t, handlers.Login()(ctx))
 	require.Equal(t, http.StatusCreated, res.Code)
 }
 
+func TestUsersHandler_IdempotentRoutes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+	idempotencyRepo := mockIdempotency.NewMockIdempotencyRepository(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234}, Idempotency: config.Idempotency{Enabled: true, Expire: 60, LockExpire: 10}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, idempotencyRepo, nil, sessUC, nil, userUC)
+
+	e := echo.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, validator.New(), userUC, sessUC)
+	handlers.UserMapRoutes()
+
+	serve := func(target string, body interface{}) *httptest.ResponseRecorder {
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(body)
+
+		req := httptest.NewRequest(http.MethodPost, target, buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(constants.HeaderIdempotencyKey, "key")
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("Login is never replayed", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", Role: models.UserRoleUser}
+		userUC.EXPECT().Login(gomock.Any(), user.Email, "123456").Times(2).Return(user, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), loginSession(user.UserID), cfg.Session.Expire).Times(2).Return("s", nil)
+		userUC.EXPECT().GenerateTokenPair(gomock.Any(), gomock.Any()).Times(2).Return("at", "rt", nil)
+
+		for i := 0; i < 2; i++ {
+			res := serve("/user/login", &dto.UserLoginRequestDto{Email: user.Email, Password: "123456"})
+			require.Equal(t, http.StatusCreated, res.Code)
+			require.Empty(t, res.Header().Get(constants.HeaderIdempotentReplayed))
+		}
+	})
+
+	t.Run("Signup is replayed", func(t *testing.T) {
+		idempotencyRepo.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, key string, fingerprint string, expire time.Duration) (*models.IdempotencyRecord, bool, error) {
+				return &models.IdempotencyRecord{Fingerprint: fingerprint, Completed: true, Status: http.StatusCreated, Body: []byte(`{}`)}, false, nil
+			},
+		)
+
+		res := serve("/user/signup", &dto.UserSignupRequestDto{Email: "email@gmail.com"})
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.Equal(t, "true", res.Header().Get(constants.HeaderIdempotentReplayed))
+	})
+}
+
+func TestUsersHandler_Invite(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	appLogger := logger.NewAppLogger(nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	cfg := &config.Config{Session: config.Session{Expire: 1234}}
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	serve := func(h echo.HandlerFunc, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		require.NoError(t, h(e.NewContext(req, res)))
+		return res
+	}
+
+	userUUID := uuid.New()
+
+	t.Run("Invite", func(t *testing.T) {
+		userUC.EXPECT().Invite(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			require.Equal(t, "email@gmail.com", user.Email)
+			require.Empty(t, user.Password)
+			return &models.User{UserID: userUUID, Email: user.Email, Role: user.Role, Status: models.UserStatusPending}, nil
+		})
+
+		res := serve(handlers.Invite(), "/user/invitations", `{"email": "Email@gmail.com", "first_name": "FirstName", "last_name": "LastName", "role": "user"}`)
+		require.Equal(t, http.StatusCreated, res.Code)
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		res := serve(handlers.Invite(), "/user/invitations", `{"email": "email@gmail.com", "first_name": "FirstName", "last_name": "LastName", "role": "root"}`)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Accept logs in", func(t *testing.T) {
+		acceptedUser := &models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive}
+		userUC.EXPECT().AcceptInvitation(gomock.Any(), "token", "secret").Return(acceptedUser, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), loginSession(userUUID), cfg.Session.Expire).Return("s", nil)
+		userUC.EXPECT().GenerateTokenPair(acceptedUser, "s").Return("at", "rt", nil)
+
+		res := serve(handlers.AcceptInvitation(), "/user/invitations/accept", `{"token": "token", "password": "secret"}`)
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		loginDto := &dto.UserLoginResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), loginDto))
+		require.Equal(t, "at", loginDto.Tokens.AccessToken)
+	})
+
+	t.Run("Accept expired invitation", func(t *testing.T) {
+		userUC.EXPECT().AcceptInvitation(gomock.Any(), "token", "secret").Return(nil, domain_errors.ErrInvalidInvitation)
+
+		res := serve(handlers.AcceptInvitation(), "/user/invitations/accept", `{"token": "token", "password": "secret"}`)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+func TestUsersHandler_MagicLink(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	appLogger := logger.NewAppLogger(nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	cfg := &config.Config{Session: config.Session{Expire: 1234}, MagicLink: config.MagicLink{Expire: 900, CookieName: "magic-link-nonce"}}
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	userUUID := uuid.New()
+	var nonce string
+
+	t.Run("Request sets the nonce cookie", func(t *testing.T) {
+		userUC.EXPECT().RequestMagicLink(gomock.Any(), "email@gmail.com", gomock.Any()).DoAndReturn(func(ctx context.Context, email string, n string) error {
+			nonce = n
+			return nil
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/user/magic-link", strings.NewReader(`{"email": "email@gmail.com"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		require.NoError(t, handlers.RequestMagicLink()(e.NewContext(req, res)))
+		require.Equal(t, http.StatusAccepted, res.Code)
+
+		cookies := res.Result().Cookies()
+		require.Len(t, cookies, 1)
+		require.Equal(t, "magic-link-nonce", cookies[0].Name)
+		require.Equal(t, nonce, cookies[0].Value)
+		require.True(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("Verify logs in with the cookie nonce", func(t *testing.T) {
+		user := &models.User{UserID: userUUID, Status: models.UserStatusActive}
+		userUC.EXPECT().LoginWithMagicLink(gomock.Any(), "token", nonce).Return(user, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), loginSession(userUUID), cfg.Session.Expire).Return("s", nil)
+		userUC.EXPECT().GenerateTokenPair(user, "s").Return("at", "rt", nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/user/magic-link/verify", strings.NewReader(`{"token": "token"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.AddCookie(&http.Cookie{Name: "magic-link-nonce", Value: nonce})
+		res := httptest.NewRecorder()
+		require.NoError(t, handlers.VerifyMagicLink()(e.NewContext(req, res)))
+		require.Equal(t, http.StatusCreated, res.Code)
+	})
+
+	t.Run("Verify without cookie", func(t *testing.T) {
+		userUC.EXPECT().LoginWithMagicLink(gomock.Any(), "token", "").Return(nil, domain_errors.ErrInvalidMagicLink)
+
+		req := httptest.NewRequest(http.MethodPost, "/user/magic-link/verify", strings.NewReader(`{"token": "token"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		require.NoError(t, handlers.VerifyMagicLink()(e.NewContext(req, res)))
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
+
 func TestUsersHandler_FindAll(t *testing.T) {
 	t.Parallel()
 
@@ -128,7 +412,7 @@ func TestUsersHandler_FindAll(t *testing.T) {
 	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
 
 	appLogger := logger.NewAppLogger(nil)
-	mw := middlewares.NewMiddlewareManager(appLogger, nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
 
 	e := echo.New()
 	v := validator.New()
@@ -150,9 +434,96 @@ func TestUsersHandler_FindAll(t *testing.T) {
 		Role:      "user",
 	})
 
-	userUC.EXPECT().FindAll(gomock.Any(), gomock.Any()).AnyTimes().Return(users, nil)
+	userUC.EXPECT().FindAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(&models.UsersList{
+		TotalCount: 1,
+		TotalPages: 1,
+		Page:       1,
+		Size:       10,
+		Users:      users,
+	}, nil)
 	require.NoError(t, handlers.FindAll()(ctx))
 	require.Equal(t, http.StatusOK, res.Code)
+
+	t.Run("Filters", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/user?role=user&search=first&verified=true&created_from=2022-01-01T00:00:00Z&orderBy=-email&page=2&size=5", nil)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+
+		createdFrom := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		verified := true
+		pagination := utils.NewPaginationQuery(5, 2)
+		pagination.SetOrderBy("-email")
+		userUC.EXPECT().FindAll(gomock.Any(), &models.UserFilter{
+			Role:        models.UserRoleUser,
+			Search:      "first",
+			CreatedFrom: &createdFrom,
+			Verified:    &verified,
+		}, pagination).Return(&models.UsersList{TotalCount: 12, TotalPages: 3, Page: 2, Size: 5, HasMore: true, Users: users}, nil)
+
+		require.NoError(t, handlers.FindAll()(ctx))
+		require.Equal(t, http.StatusOK, res.Code)
+
+		body := dto.UserFindResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+		require.Equal(t, 12, body.Meta.TotalCount)
+		require.Equal(t, 3, body.Meta.TotalPages)
+		require.True(t, body.Meta.HasMore)
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/user?cursor=next&size=5", nil)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+
+		pagination := utils.NewPaginationQuery(5, 1)
+		pagination.SetCursor("next")
+		userUC.EXPECT().FindAll(gomock.Any(), &models.UserFilter{}, pagination).
+			Return(&models.UsersList{Size: 5, HasMore: true, NextCursor: "after", PrevCursor: "before", Users: users}, nil)
+
+		require.NoError(t, handlers.FindAll()(ctx))
+		require.Equal(t, http.StatusOK, res.Code)
+
+		body := dto.UserFindResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+		require.Equal(t, "after", body.Meta.NextCursor)
+		require.Equal(t, "before", body.Meta.PrevCursor)
+	})
+
+	t.Run("Include deleted", func(t *testing.T) {
+		for _, role := range []string{models.UserRoleUser, models.UserRoleAdmin} {
+			req := httptest.NewRequest(http.MethodGet, "/user?include_deleted=true", nil)
+			res := httptest.NewRecorder()
+			ctx := e.NewContext(req, res)
+
+			token := jwt.New(jwt.SigningMethodHS256)
+			claims := token.Claims.(jwt.MapClaims)
+			claims["session_id"] = uuid.New().String()
+			claims["user_id"] = uuid.New().String()
+			claims["role"] = role
+			ctx.Set("user", token)
+
+			if role == models.UserRoleAdmin {
+				userUC.EXPECT().FindAll(gomock.Any(), &models.UserFilter{IncludeDeleted: true}, gomock.Any()).
+					Return(&models.UsersList{Size: 10, Users: users}, nil)
+			}
+
+			require.NoError(t, handlers.FindAll()(ctx))
+			if role == models.UserRoleAdmin {
+				require.Equal(t, http.StatusOK, res.Code)
+			} else {
+				require.Equal(t, http.StatusForbidden, res.Code)
+			}
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/user?created_to=yesterday", nil)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+
+		require.NoError(t, handlers.FindAll()(ctx))
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
 }
 
 func TestUsersHandler_FindById(t *testing.T) {
@@ -166,7 +537,7 @@ func TestUsersHandler_FindById(t *testing.T) {
 
 	cfg := &config.Config{Session: config.Session{Expire: 1234}}
 	appLogger := logger.NewAppLogger(cfg)
-	mw := middlewares.NewMiddlewareManager(appLogger, nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
 
 	e := echo.New()
 	v := validator.New()
@@ -183,6 +554,34 @@ func TestUsersHandler_FindById(t *testing.T) {
 	userUC.EXPECT().CachedFindById(gomock.Any(), gomock.Any()).AnyTimes().Return(&models.User{}, nil)
 	require.NoError(t, handlers.FindById()(ctx))
 	require.Equal(t, http.StatusOK, res.Code)
+
+	t.Run("Fields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/user/:id?fields=user_id,email", nil)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("2ceba62a-35f4-444b-a358-4b14834837e1")
+
+		require.NoError(t, handlers.FindById()(ctx))
+		require.Equal(t, http.StatusOK, res.Code)
+
+		body := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+		require.Len(t, body, 2)
+		require.Contains(t, body, "user_id")
+		require.Contains(t, body, "email")
+	})
+
+	t.Run("Invalid fields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/user/:id?fields=password", nil)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("2ceba62a-35f4-444b-a358-4b14834837e1")
+
+		require.NoError(t, handlers.FindById()(ctx))
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
 }
 
 func TestUsersHandler_UpdateById(t *testing.T) {
@@ -194,9 +593,9 @@ func TestUsersHandler_UpdateById(t *testing.T) {
 	userUC := mock.NewMockUserUseCase(ctrl)
 	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
 
-	cfg := &config.Config{Session: config.Session{Expire: 1234}}
+	cfg := &config.Config{Session: config.Session{Expire: 1234}, OTP: config.OTP{StepUpMaxAge: 600}}
 	appLogger := logger.NewAppLogger(cfg)
-	mw := middlewares.NewMiddlewareManager(appLogger, cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
 
 	e := echo.New()
 	e.Use(middleware.JWT([]byte("secret")))
@@ -211,9 +610,6 @@ func TestUsersHandler_UpdateById(t *testing.T) {
 		Avatar:    &change,
 	}
 
-	buf := &bytes.Buffer{}
-	_ = json.NewEncoder(buf).Encode(reqDto)
-
 	userUUID := uuid.New()
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
@@ -223,13 +619,20 @@ func TestUsersHandler_UpdateById(t *testing.T) {
 	claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
 	validToken, _ := token.SignedString([]byte("secret"))
 
-	req := httptest.NewRequest(http.MethodPost, "/user/:id", buf)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("bearer %v", validToken))
-
-	t.Run("Forbidden update by other user", func(t *testing.T) {
-		t.Parallel()
+	newRequest := func(ifMatch string) *http.Request {
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(reqDto)
+
+		req := httptest.NewRequest(http.MethodPut, "/user/:id", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("bearer %v", validToken))
+		if ifMatch != "" {
+			req.Header.Set(constants.HeaderIfMatch, ifMatch)
+		}
+		return req
+	}
 
+	serve := func(req *http.Request, id string) *httptest.ResponseRecorder {
 		res := httptest.NewRecorder()
 		ctx := e.NewContext(req, res)
 
@@ -240,35 +643,186 @@ func TestUsersHandler_UpdateById(t *testing.T) {
 		})(handler)
 
 		ctx.SetParamNames("id")
-		ctx.SetParamValues("2ceba62a-35f4-444b-a358-4b14834837e1")
-
-		userUC.EXPECT().UpdateById(gomock.Any(), gomock.Any()).AnyTimes().Return(&models.User{UserID: userUUID}, nil)
+		ctx.SetParamValues(id)
 
 		require.NoError(t, h(ctx))
+		return res
+	}
+
+	userUC.EXPECT().FindById(gomock.Any(), userUUID).AnyTimes().Return(&models.User{UserID: userUUID, Version: 3}, nil)
+	sessUC.EXPECT().GetSessionById(gomock.Any(), claims["session_id"].(string)).AnyTimes().Return(&models.Session{
+		AuthTime: time.Now(),
+		ACR:      models.SessionACRStepUp,
+	}, nil)
+
+	t.Run("Forbidden update by other user", func(t *testing.T) {
+		t.Parallel()
+
+		res := serve(newRequest(`"3"`), "2ceba62a-35f4-444b-a358-4b14834837e1")
 		require.Equal(t, http.StatusForbidden, res.Code)
 	})
 
+	t.Run("Missing If-Match", func(t *testing.T) {
+		t.Parallel()
+
+		res := serve(newRequest(""), userUUID.String())
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Stale ETag", func(t *testing.T) {
+		t.Parallel()
+
+		res := serve(newRequest(`"2"`), userUUID.String())
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+	})
+
 	t.Run("Success", func(t *testing.T) {
 		t.Parallel()
 
+		userUC.EXPECT().UpdateById(gomock.Any(), gomock.Any()).Return(&models.User{UserID: userUUID, Version: 4}, nil)
+		userUC.EXPECT().RevokeTrustedDevices(gomock.Any(), userUUID).Return(nil)
+
+		res := serve(newRequest(`W/"3"`), userUUID.String())
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, `"4"`, res.Header().Get(constants.HeaderETag))
+	})
+}
+
+func TestUsersHandler_PatchById(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234, ReauthMaxAge: 300}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	userUUID := uuid.New()
+	avatar := "avatar.png"
+
+	serve := func(role string, contentType string, patch string) *httptest.ResponseRecorder {
+		token := jwt.New(jwt.SigningMethodHS256)
+		claims := token.Claims.(jwt.MapClaims)
+		claims["session_id"] = uuid.New().String()
+		claims["user_id"] = userUUID.String()
+		claims["role"] = role
+		claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+		validToken, _ := token.SignedString([]byte("secret"))
+
+		req := httptest.NewRequest(http.MethodPatch, "/user/:id", strings.NewReader(patch))
+		req.Header.Set(echo.HeaderContentType, contentType)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("bearer %v", validToken))
+		req.Header.Set(constants.HeaderIfMatch, `"3"`)
 		res := httptest.NewRecorder()
 		ctx := e.NewContext(req, res)
 
-		handler := handlers.UpdateById()
 		h := middleware.JWTWithConfig(middleware.JWTConfig{
 			Claims:     claims,
 			SigningKey: []byte("secret"),
-		})(handler)
+		})(handlers.PatchById())
 
 		ctx.SetParamNames("id")
 		ctx.SetParamValues(userUUID.String())
 
-		userUC.EXPECT().UpdateById(gomock.Any(), gomock.Any()).AnyTimes().Return(&models.User{UserID: userUUID}, nil)
-		userUC.EXPECT().FindById(gomock.Any(), userUUID).AnyTimes().Return(&models.User{UserID: userUUID}, nil)
-
 		require.NoError(t, h(ctx))
+		return res
+	}
+
+	userUC.EXPECT().FindById(gomock.Any(), userUUID).AnyTimes().DoAndReturn(func(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+		return &models.User{
+			UserID:    userUUID,
+			Email:     "email@gmail.com",
+			FirstName: "FirstName",
+			LastName:  "LastName",
+			Role:      models.UserRoleUser,
+			Avatar:    &avatar,
+			Version:   3,
+		}, nil
+	})
+
+	sessUC.EXPECT().GetSessionById(gomock.Any(), gomock.Any()).AnyTimes().Return(&models.Session{
+		AuthTime: time.Now(),
+		AMR:      []string{models.AuthMethodPassword},
+	}, nil)
+
+	t.Run("Merge patch clears avatar", func(t *testing.T) {
+		t.Parallel()
+
+		userUC.EXPECT().UpdateById(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			require.Equal(t, "Changed", user.FirstName)
+			require.Nil(t, user.Avatar)
+			require.Equal(t, int64(3), user.Version)
+			user.Version++
+			return user, nil
+		})
+
+		res := serve(models.UserRoleUser, constants.MIMEApplicationMergePatchJSON, `{"first_name": "Changed", "avatar": null}`)
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, `"4"`, res.Header().Get(constants.HeaderETag))
+	})
+
+	t.Run("Json patch", func(t *testing.T) {
+		t.Parallel()
+
+		userUC.EXPECT().UpdateById(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			require.Equal(t, "other@gmail.com", user.Email)
+			require.Equal(t, models.UserRoleAdmin, user.Role)
+			return user, nil
+		})
+
+		res := serve(models.UserRoleAdmin, constants.MIMEApplicationJSONPatchJSON, `[
+			{"op": "test", "path": "/email", "value": "email@gmail.com"},
+			{"op": "replace", "path": "/email", "value": "other@gmail.com"},
+			{"op": "replace", "path": "/role", "value": "admin"}
+		]`)
 		require.Equal(t, http.StatusOK, res.Code)
 	})
+
+	t.Run("Forbidden field for role", func(t *testing.T) {
+		t.Parallel()
+
+		res := serve(models.UserRoleUser, constants.MIMEApplicationMergePatchJSON, `{"role": "admin"}`)
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("Read only field", func(t *testing.T) {
+		t.Parallel()
+
+		res := serve(models.UserRoleAdmin, constants.MIMEApplicationJSONPatchJSON, `[{"op": "add", "path": "/status", "value": "active"}]`)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Failed json patch test is not applied", func(t *testing.T) {
+		t.Parallel()
+
+		res := serve(models.UserRoleUser, constants.MIMEApplicationJSONPatchJSON, `[
+			{"op": "replace", "path": "/first_name", "value": "Changed"},
+			{"op": "test", "path": "/last_name", "value": "Other"}
+		]`)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Invalid patched document", func(t *testing.T) {
+		t.Parallel()
+
+		res := serve(models.UserRoleUser, constants.MIMEApplicationMergePatchJSON, `{"first_name": null}`)
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Unsupported media type", func(t *testing.T) {
+		t.Parallel()
+
+		res := serve(models.UserRoleUser, echo.MIMEApplicationJSON, `{"first_name": "Changed"}`)
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+	})
 }
 
 func TestUsersHandler_DeleteById(t *testing.T) {
@@ -282,7 +836,7 @@ func TestUsersHandler_DeleteById(t *testing.T) {
 
 	cfg := &config.Config{Session: config.Session{Expire: 1234}}
 	appLogger := logger.NewAppLogger(cfg)
-	mw := middlewares.NewMiddlewareManager(appLogger, nil)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
 
 	e := echo.New()
 	v := validator.New()
@@ -302,7 +856,7 @@ func TestUsersHandler_DeleteById(t *testing.T) {
 	require.Equal(t, http.StatusOK, res.Code)
 }
 
-func TestUsersHandler_GetMe(t *testing.T) {
+func TestUsersHandler_RestoreById(t *testing.T) {
 	t.Parallel()
 
 	ctrl := gomock.NewController(t)
@@ -313,23 +867,68 @@ func TestUsersHandler_GetMe(t *testing.T) {
 
 	cfg := &config.Config{Session: config.Session{Expire: 1234}}
 	appLogger := logger.NewAppLogger(cfg)
-	mw := middlewares.NewMiddlewareManager(appLogger, cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, nil, nil, nil, nil, nil, nil)
 
 	e := echo.New()
-	e.Use(middleware.JWT([]byte("secret")))
 	v := validator.New()
 	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
 
-	userUUID := uuid.New()
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["session_id"] = uuid.New().String()
-	claims["user_id"] = userUUID.String()
-	claims["role"] = "user"
-	claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
-	validToken, _ := token.SignedString([]byte("secret"))
+	req := httptest.NewRequest(http.MethodPost, "/user/:id/restore", nil)
+	res := httptest.NewRecorder()
+	ctx := e.NewContext(req, res)
 
-	req := httptest.NewRequest(http.MethodPost, "/user/logout", nil)
+	userUUID := uuid.New()
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(userUUID.String())
+
+	userUC.EXPECT().RestoreById(gomock.Any(), userUUID).Return(&models.User{UserID: userUUID, Email: "email@gmail.com"}, nil)
+	require.NoError(t, handlers.RestoreById()(ctx))
+	require.Equal(t, http.StatusOK, res.Code)
+
+	body := dto.UserResponseDto{}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal(t, userUUID, body.UserID)
+
+	t.Run("Not deleted", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(userUUID.String())
+
+		userUC.EXPECT().RestoreById(gomock.Any(), userUUID).Return(nil, domain_errors.ErrUserNotFound)
+		require.NoError(t, handlers.RestoreById()(ctx))
+		require.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
+
+func TestUsersHandler_GetMe(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	e.Use(middleware.JWT([]byte("secret")))
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	userUUID := uuid.New()
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["session_id"] = uuid.New().String()
+	claims["user_id"] = userUUID.String()
+	claims["role"] = "user"
+	claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+	validToken, _ := token.SignedString([]byte("secret"))
+
+	req := httptest.NewRequest(http.MethodPost, "/user/logout", nil)
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("bearer %v", validToken))
 
@@ -343,7 +942,7 @@ func TestUsersHandler_GetMe(t *testing.T) {
 	})(handler)
 
 	sessUC.EXPECT().GetSessionById(gomock.Any(), claims["session_id"].(string)).AnyTimes().Return(&models.Session{}, nil)
-	userUC.EXPECT().CachedFindById(gomock.Any(), gomock.Any()).AnyTimes().Return(&models.User{}, nil)
+	userUC.EXPECT().CachedFindById(gomock.Any(), gomock.Any()).AnyTimes().Return(&models.User{Status: models.UserStatusActive}, nil)
 
 	require.NoError(t, h(ctx))
 	require.Equal(t, http.StatusOK, res.Code)
@@ -360,7 +959,7 @@ func TestUsersHandler_Logout(t *testing.T) {
 
 	cfg := &config.Config{Session: config.Session{Expire: 1234}}
 	appLogger := logger.NewAppLogger(cfg)
-	mw := middlewares.NewMiddlewareManager(appLogger, cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
 
 	e := echo.New()
 	e.Use(middleware.JWT([]byte("secret")))
@@ -406,7 +1005,7 @@ func TestUsersHandler_RefreshToken(t *testing.T) {
 
 	cfg := &config.Config{Session: config.Session{Expire: 1234}, Server: config.ServerConfig{JwtSecretKey: "secret"}}
 	appLogger := logger.NewAppLogger(cfg)
-	mw := middlewares.NewMiddlewareManager(appLogger, cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
 
 	e := echo.New()
 	v := validator.New()
@@ -432,9 +1031,688 @@ func TestUsersHandler_RefreshToken(t *testing.T) {
 	ctx := e.NewContext(req, res)
 
 	sessUC.EXPECT().GetSessionById(gomock.Any(), claims["session_id"].(string)).AnyTimes().Return(&models.Session{}, nil)
-	userUC.EXPECT().FindById(gomock.Any(), gomock.Any()).AnyTimes().Return(&models.User{}, nil)
+	userUC.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(&models.User{Status: models.UserStatusActive}, nil)
 	userUC.EXPECT().GenerateTokenPair(gomock.Any(), gomock.Any()).AnyTimes().Return("rt", "at", nil)
 
 	require.NoError(t, handlers.RefreshToken()(ctx))
 	require.Equal(t, http.StatusOK, res.Code)
+
+	t.Run("Suspended user", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(reqDto)
+		req := httptest.NewRequest(http.MethodPost, "/user/refresh", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+
+		userUC.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(&models.User{Status: models.UserStatusSuspended}, nil)
+
+		require.NoError(t, handlers.RefreshToken()(ctx))
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("OAuth client session", func(t *testing.T) {
+		clientToken := jwt.New(jwt.SigningMethodHS256)
+		clientClaims := clientToken.Claims.(jwt.MapClaims)
+		clientClaims["session_id"] = uuid.New().String()
+		clientClaims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+		signedClientToken, _ := clientToken.SignedString([]byte("secret"))
+
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(&dto.UserRefreshTokenDto{RefreshToken: signedClientToken})
+		req := httptest.NewRequest(http.MethodPost, "/user/refresh", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+
+		clientSession := &models.Session{SessionID: clientClaims["session_id"].(string), ClientID: uuid.New().String(), Scopes: []string{models.APIKeyScopeUsersRead}}
+		sessUC.EXPECT().GetSessionById(gomock.Any(), clientSession.SessionID).Return(clientSession, nil)
+
+		require.NoError(t, handlers.RefreshToken()(ctx))
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
+
+func TestUsersHandler_OAuthClientToken(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234, ReauthMaxAge: 600}, Server: config.ServerConfig{JwtSecretKey: "secret"}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, sessUC, nil, userUC)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+	handlers.UserMapRoutes()
+
+	userUUID := uuid.New()
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["session_id"] = uuid.New().String()
+	claims["user_id"] = userUUID.String()
+	claims["role"] = models.UserRoleUser
+	claims["client_id"] = uuid.New().String()
+	claims["scope"] = models.APIKeyScopeUsersRead
+	claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+	clientToken, _ := token.SignedString([]byte("secret"))
+
+	sessionUser := &models.User{UserID: userUUID, Role: models.UserRoleUser, Status: models.UserStatusActive, Version: 1}
+	userUC.EXPECT().CachedFindById(gomock.Any(), userUUID).AnyTimes().Return(sessionUser, nil)
+	clientSession := &models.Session{
+		SessionID: claims["session_id"].(string),
+		UserID:    userUUID,
+		ClientID:  claims["client_id"].(string),
+		Scopes:    []string{models.APIKeyScopeUsersRead},
+	}
+	loggedOut := false
+	sessUC.EXPECT().GetSessionById(gomock.Any(), clientSession.SessionID).AnyTimes().DoAndReturn(
+		func(ctx context.Context, sessionID string) (*models.Session, error) {
+			if loggedOut {
+				return nil, domain_errors.ErrSessionNotFound
+			}
+			return clientSession, nil
+		},
+	)
+
+	serve := func(method string, target string, body interface{}) *httptest.ResponseRecorder {
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(body)
+
+		req := httptest.NewRequest(method, target, buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("bearer %v", clientToken))
+		req.Header.Set(constants.HeaderIfMatch, `"1"`)
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("Route with scope", func(t *testing.T) {
+		res := serve(http.MethodGet, "/user/"+userUUID.String(), nil)
+		require.Equal(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("Update user", func(t *testing.T) {
+		change := "changed"
+		res := serve(http.MethodPut, "/user/"+userUUID.String(), &dto.UserUpdateRequestDto{FirstName: &change})
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("Create personal access token", func(t *testing.T) {
+		res := serve(http.MethodPost, "/user/me/tokens", &dto.AccessTokenCreateRequestDto{Name: "script", Scopes: []string{models.APIKeyScopeUsersWrite}})
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		sessUC.EXPECT().DeleteById(gomock.Any(), clientSession.SessionID).DoAndReturn(func(ctx context.Context, sessionID string) error {
+			loggedOut = true
+			return nil
+		})
+
+		res := serve(http.MethodPost, "/user/logout", nil)
+		require.Equal(t, http.StatusOK, res.Code)
+
+		res = serve(http.MethodGet, "/user/"+userUUID.String(), nil)
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
+
+func TestUsersHandler_ChangeStatus(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	adminUUID := uuid.New()
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["session_id"] = uuid.New().String()
+	claims["user_id"] = adminUUID.String()
+	claims["role"] = models.UserRoleAdmin
+
+	newCtx := func(body string, id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/user/:id/suspend", bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.Set("user", token)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+		return ctx, res
+	}
+
+	userUUID := uuid.New()
+	ctx, res := newCtx(`{"reason":" spam "}`, userUUID.String())
+	userUC.EXPECT().ChangeStatus(gomock.Any(), &models.UserStatusChange{
+		UserID:  userUUID,
+		Status:  models.UserStatusSuspended,
+		Reason:  "spam",
+		ActorID: &adminUUID,
+	}).Return(&models.User{UserID: userUUID, Status: models.UserStatusSuspended}, nil)
+
+	require.NoError(t, handlers.SuspendById()(ctx))
+	require.Equal(t, http.StatusOK, res.Code)
+
+	body := dto.UserResponseDto{}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal(t, models.UserStatusSuspended, body.Status)
+
+	t.Run("Invalid transition", func(t *testing.T) {
+		ctx, res := newCtx(`{}`, userUUID.String())
+		userUC.EXPECT().ChangeStatus(gomock.Any(), gomock.Any()).Return(nil, domain_errors.ErrInvalidStatusTransition)
+
+		require.NoError(t, handlers.ReactivateById()(ctx))
+		require.Equal(t, http.StatusConflict, res.Code)
+	})
+
+	t.Run("Deactivate me", func(t *testing.T) {
+		ctx, res := newCtx(`{"reason":"leaving"}`, "")
+		userUC.EXPECT().ChangeStatus(gomock.Any(), &models.UserStatusChange{
+			UserID:  adminUUID,
+			Status:  models.UserStatusDeactivated,
+			Reason:  "leaving",
+			ActorID: &adminUUID,
+		}).Return(&models.User{UserID: adminUUID, Status: models.UserStatusDeactivated}, nil)
+
+		require.NoError(t, handlers.DeactivateMe()(ctx))
+		require.Equal(t, http.StatusOK, res.Code)
+	})
+}
+
+func TestUsersHandler_StepUp(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{
+		Session:       config.Session{Expire: 1234},
+		OTP:           config.OTP{StepUpMaxAge: 600},
+		TrustedDevice: config.TrustedDevice{Expire: 3600, CookieName: "trusted_device"},
+	}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	userUUID := uuid.New()
+	sessionID := uuid.New().String()
+	serve := func(method string, body interface{}, handler echo.HandlerFunc, id string) *httptest.ResponseRecorder {
+		token := jwt.New(jwt.SigningMethodHS256)
+		claims := token.Claims.(jwt.MapClaims)
+		claims["session_id"] = sessionID
+		claims["user_id"] = userUUID.String()
+		claims["role"] = models.UserRoleUser
+		claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+		validToken, _ := token.SignedString([]byte("secret"))
+
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(body)
+
+		req := httptest.NewRequest(method, "/user/me/step-up", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("bearer %v", validToken))
+		req.Header.Set(constants.HeaderIfMatch, `"3"`)
+		res := httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		if id != "" {
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(id)
+		}
+
+		h := middleware.JWTWithConfig(middleware.JWTConfig{
+			Claims:     claims,
+			SigningKey: []byte("secret"),
+		})(handler)
+
+		require.NoError(t, h(ctx))
+		return res
+	}
+
+	t.Run("Password change requires step-up", func(t *testing.T) {
+		change := "changed"
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionID).Return(&models.Session{
+			SessionID: sessionID,
+			AuthTime:  time.Now(),
+			ACR:       models.SessionACRBasic,
+		}, nil)
+
+		res := serve(http.MethodPut, &dto.UserUpdateRequestDto{Password: &change}, handlers.UpdateById(), userUUID.String())
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("Step up", func(t *testing.T) {
+		session := &models.Session{SessionID: sessionID, UserID: userUUID, ACR: models.SessionACRBasic}
+		authTime := time.Now()
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionID).Return(session, nil)
+		userUC.EXPECT().StepUp(gomock.Any(), session, "123456").Return(&models.Session{
+			SessionID: sessionID,
+			UserID:    userUUID,
+			AuthTime:  authTime,
+			ACR:       models.SessionACRStepUp,
+		}, nil)
+
+		res := serve(http.MethodPost, &dto.UserStepUpRequestDto{Code: "123456"}, handlers.StepUp(), "")
+		require.Equal(t, http.StatusOK, res.Code)
+
+		resDto := &dto.UserAuthContextResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), resDto))
+		require.Equal(t, models.SessionACRStepUp, resDto.ACR)
+		require.True(t, authTime.Equal(resDto.AuthTime))
+	})
+
+	t.Run("Remember device", func(t *testing.T) {
+		session := &models.Session{SessionID: sessionID, UserID: userUUID, ACR: models.SessionACRBasic}
+		deviceID := uuid.New()
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionID).Return(session, nil)
+		userUC.EXPECT().StepUp(gomock.Any(), session, "123456").Return(&models.Session{
+			SessionID: sessionID,
+			UserID:    userUUID,
+			AuthTime:  time.Now(),
+			ACR:       models.SessionACRStepUp,
+		}, nil)
+		userUC.EXPECT().TrustDevice(gomock.Any(), userUUID, "Laptop", gomock.Any()).Return("signed", &models.TrustedDevice{DeviceID: deviceID}, nil)
+
+		res := serve(http.MethodPost, &dto.UserStepUpRequestDto{Code: "123456", RememberDevice: true, DeviceName: "Laptop"}, handlers.StepUp(), "")
+		require.Equal(t, http.StatusOK, res.Code)
+
+		resDto := &dto.UserAuthContextResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), resDto))
+		require.Equal(t, deviceID, *resDto.DeviceID)
+
+		cookies := res.Result().Cookies()
+		require.Len(t, cookies, 1)
+		require.Equal(t, "trusted_device", cookies[0].Name)
+		require.Equal(t, "signed", cookies[0].Value)
+		require.True(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("Invalid code format", func(t *testing.T) {
+		res := serve(http.MethodPost, &dto.UserStepUpRequestDto{Code: "12ab"}, handlers.StepUp(), "")
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+func TestUsersHandler_LoginFromTrustedDevice(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234}, TrustedDevice: config.TrustedDevice{Expire: 3600, CookieName: "trusted_device"}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	mockUser := &models.User{UserID: uuid.New(), Email: "email@gmail.com", Role: models.UserRoleUser}
+	serve := func(cookie string) *httptest.ResponseRecorder {
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(&dto.UserLoginRequestDto{Email: "email@gmail.com", Password: "123456"})
+
+		req := httptest.NewRequest(http.MethodPost, "/user/login", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.AddCookie(&http.Cookie{Name: "trusted_device", Value: cookie})
+		res := httptest.NewRecorder()
+		require.NoError(t, handlers.Login()(e.NewContext(req, res)))
+		return res
+	}
+
+	t.Run("Trusted", func(t *testing.T) {
+		userUC.EXPECT().Login(gomock.Any(), "email@gmail.com", "123456").Return(mockUser, nil)
+		userUC.EXPECT().TouchTrustedDevice(gomock.Any(), mockUser.UserID, "signed", gomock.Any()).Return(&models.TrustedDevice{}, nil)
+		var trustedSession *models.Session
+		sessUC.EXPECT().CreateSession(gomock.Any(), gomock.AssignableToTypeOf(&models.Session{}), cfg.Session.Expire).
+			DoAndReturn(func(ctx context.Context, session *models.Session, expire int) (string, error) {
+				require.Equal(t, models.SessionACRBasic, session.ACR)
+				require.Equal(t, []string{models.AuthMethodPassword, models.AuthMethodTrustedDevice}, session.AMR)
+				session.SessionID = "s"
+				trustedSession = session
+				return "s", nil
+			})
+		userUC.EXPECT().GenerateTokenPair(mockUser, "s").Return("rt", "at", nil)
+
+		res := serve("signed")
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		// deleting a user still needs a fresh step-up
+		sessUC.EXPECT().GetSessionById(gomock.Any(), "s").Return(trustedSession, nil)
+		stepUpMw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, sessUC, nil, nil)
+		deleteById := stepUpMw.RequireStepUp(10 * time.Minute)(handlers.DeleteById())
+
+		req := httptest.NewRequest(http.MethodDelete, "/user/"+uuid.New().String(), nil)
+		res = httptest.NewRecorder()
+		ctx := e.NewContext(req, res)
+		ctx.Set("user", &jwt.Token{Claims: jwt.MapClaims{"session_id": "s", "user_id": mockUser.UserID.String(), "role": models.UserRoleAdmin}})
+		require.NoError(t, deleteById(ctx))
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("Not trusted", func(t *testing.T) {
+		userUC.EXPECT().Login(gomock.Any(), "email@gmail.com", "123456").Return(mockUser, nil)
+		userUC.EXPECT().TouchTrustedDevice(gomock.Any(), mockUser.UserID, "revoked", gomock.Any()).Return(nil, domain_errors.ErrTrustedDeviceNotFound)
+		sessUC.EXPECT().CreateSession(gomock.Any(), loginSession(mockUser.UserID), cfg.Session.Expire).Return("s", nil)
+		userUC.EXPECT().GenerateTokenPair(mockUser, "s").Return("rt", "at", nil)
+
+		res := serve("revoked")
+		require.Equal(t, http.StatusCreated, res.Code)
+	})
+}
+
+func TestUsersHandler_LoginWithCode(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	serve := func(handler echo.HandlerFunc, body interface{}) *httptest.ResponseRecorder {
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(body)
+
+		req := httptest.NewRequest(http.MethodPost, "/user/login/code", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		require.NoError(t, handler(e.NewContext(req, res)))
+		return res
+	}
+
+	t.Run("Request", func(t *testing.T) {
+		userUC.EXPECT().RequestLoginCode(gomock.Any(), "email@gmail.com").Return(nil)
+
+		res := serve(handlers.RequestLoginCode(), &dto.UserLoginCodeRequestDto{Email: "email@gmail.com"})
+		require.Equal(t, http.StatusAccepted, res.Code)
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		userUUID := uuid.New()
+		mockUser := &models.User{UserID: userUUID, Email: "email@gmail.com", Role: models.UserRoleUser}
+
+		userUC.EXPECT().LoginWithCode(gomock.Any(), "email@gmail.com", "123456").Return(mockUser, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), loginSession(userUUID), cfg.Session.Expire).Return("s", nil)
+		userUC.EXPECT().GenerateTokenPair(mockUser, "s").Return("rt", "at", nil)
+
+		res := serve(handlers.LoginWithCode(), &dto.UserLoginCodeVerifyRequestDto{Email: "email@gmail.com", Code: "123456"})
+		require.Equal(t, http.StatusCreated, res.Code)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		userUC.EXPECT().LoginWithCode(gomock.Any(), "email@gmail.com", "654321").Return(nil, domain_errors.ErrInvalidCode)
+
+		res := serve(handlers.LoginWithCode(), &dto.UserLoginCodeVerifyRequestDto{Email: "email@gmail.com", Code: "654321"})
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
+
+func TestUsersHandler_LoginWithSMSCode(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	serve := func(handler echo.HandlerFunc, body interface{}) *httptest.ResponseRecorder {
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(body)
+
+		req := httptest.NewRequest(http.MethodPost, "/user/login/sms", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res := httptest.NewRecorder()
+		require.NoError(t, handler(e.NewContext(req, res)))
+		return res
+	}
+
+	t.Run("Request", func(t *testing.T) {
+		userUC.EXPECT().RequestSMSLoginCode(gomock.Any(), "+6281234567890").Return(nil)
+
+		res := serve(handlers.RequestSMSLoginCode(), &dto.UserSMSLoginCodeRequestDto{PhoneNumber: "+6281234567890"})
+		require.Equal(t, http.StatusAccepted, res.Code)
+	})
+
+	t.Run("Request local number", func(t *testing.T) {
+		res := serve(handlers.RequestSMSLoginCode(), &dto.UserSMSLoginCodeRequestDto{PhoneNumber: "081234567890"})
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		userUUID := uuid.New()
+		mockUser := &models.User{UserID: userUUID, Email: "email@gmail.com", Role: models.UserRoleUser}
+
+		userUC.EXPECT().LoginWithSMSCode(gomock.Any(), "+6281234567890", "123456").Return(mockUser, nil)
+		sessUC.EXPECT().CreateSession(gomock.Any(), loginSession(userUUID), cfg.Session.Expire).Return("s", nil)
+		userUC.EXPECT().GenerateTokenPair(mockUser, "s").Return("rt", "at", nil)
+
+		res := serve(handlers.LoginWithSMSCode(), &dto.UserSMSLoginRequestDto{PhoneNumber: "+6281234567890", Code: "123456"})
+		require.Equal(t, http.StatusCreated, res.Code)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		userUC.EXPECT().LoginWithSMSCode(gomock.Any(), "+6281234567890", "654321").Return(nil, domain_errors.ErrInvalidCode)
+
+		res := serve(handlers.LoginWithSMSCode(), &dto.UserSMSLoginRequestDto{PhoneNumber: "+6281234567890", Code: "654321"})
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
+
+func TestUsersHandler_Reauthenticate(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234, ReauthMaxAge: 300}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	userUUID := uuid.New()
+	sessionID := uuid.New().String()
+	serve := func(body interface{}) *httptest.ResponseRecorder {
+		token := jwt.New(jwt.SigningMethodHS256)
+		claims := token.Claims.(jwt.MapClaims)
+		claims["session_id"] = sessionID
+		claims["user_id"] = userUUID.String()
+		claims["role"] = models.UserRoleUser
+		claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+		validToken, _ := token.SignedString([]byte("secret"))
+
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(body)
+
+		req := httptest.NewRequest(http.MethodPost, "/user/me/reauthenticate", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("bearer %v", validToken))
+		res := httptest.NewRecorder()
+
+		h := middleware.JWTWithConfig(middleware.JWTConfig{
+			Claims:     claims,
+			SigningKey: []byte("secret"),
+		})(handlers.Reauthenticate())
+
+		require.NoError(t, h(e.NewContext(req, res)))
+		return res
+	}
+
+	t.Run("Reauthenticate", func(t *testing.T) {
+		session := &models.Session{SessionID: sessionID, UserID: userUUID, AMR: []string{models.AuthMethodMagicLink}}
+		authTime := time.Now()
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionID).Return(session, nil)
+		userUC.EXPECT().Reauthenticate(gomock.Any(), session, "123456").Return(&models.Session{
+			SessionID: sessionID,
+			UserID:    userUUID,
+			AuthTime:  authTime,
+			ACR:       models.SessionACRBasic,
+			AMR:       []string{models.AuthMethodPassword},
+		}, nil)
+
+		res := serve(&dto.UserReauthenticateRequestDto{Password: "123456"})
+		require.Equal(t, http.StatusOK, res.Code)
+
+		resDto := &dto.UserAuthContextResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), resDto))
+		require.Equal(t, []string{models.AuthMethodPassword}, resDto.AMR)
+		require.True(t, authTime.Equal(resDto.AuthTime))
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		session := &models.Session{SessionID: sessionID, UserID: userUUID}
+
+		sessUC.EXPECT().GetSessionById(gomock.Any(), sessionID).Return(session, nil)
+		userUC.EXPECT().Reauthenticate(gomock.Any(), session, "wrong").Return(nil, domain_errors.ErrInvalidCredentials)
+
+		res := serve(&dto.UserReauthenticateRequestDto{Password: "wrong"})
+		require.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
+
+// loginSession matches a freshly authenticated session of the user
+func loginSession(userID uuid.UUID) gomock.Matcher {
+	return loginSessionMatcher{userID: userID}
+}
+
+type loginSessionMatcher struct {
+	userID uuid.UUID
+}
+
+func (m loginSessionMatcher) Matches(x interface{}) bool {
+	session, ok := x.(*models.Session)
+	if !ok {
+		return false
+	}
+	return session.UserID == m.userID && session.ACR == models.SessionACRBasic && len(session.AMR) > 0 && time.Since(session.AuthTime) < time.Minute
+}
+
+func (m loginSessionMatcher) String() string {
+	return fmt.Sprintf("is a basic session of user %s", m.userID)
+}
+
+func TestUsersHandler_CreateAccessToken(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userUC := mock.NewMockUserUseCase(ctrl)
+	sessUC := mockSessUC.NewMockSessUseCase(ctrl)
+
+	cfg := &config.Config{Session: config.Session{Expire: 1234}}
+	appLogger := logger.NewAppLogger(cfg)
+	mw := middlewares.NewMiddlewareManager(appLogger, cfg, nil, nil, nil, nil, nil)
+
+	e := echo.New()
+	v := validator.New()
+	handlers := NewUserHandlersHTTP(e.Group("user"), appLogger, cfg, mw, v, userUC, sessUC)
+
+	userUUID := uuid.New()
+	serve := func(body interface{}) *httptest.ResponseRecorder {
+		token := jwt.New(jwt.SigningMethodHS256)
+		claims := token.Claims.(jwt.MapClaims)
+		claims["session_id"] = uuid.New().String()
+		claims["user_id"] = userUUID.String()
+		claims["role"] = models.UserRoleUser
+		claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+		validToken, _ := token.SignedString([]byte("secret"))
+
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(body)
+
+		req := httptest.NewRequest(http.MethodPost, "/user/me/tokens", buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("bearer %v", validToken))
+		res := httptest.NewRecorder()
+
+		h := middleware.JWTWithConfig(middleware.JWTConfig{
+			Claims:     claims,
+			SigningKey: []byte("secret"),
+		})(handlers.CreateAccessToken())
+
+		require.NoError(t, h(e.NewContext(req, res)))
+		return res
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		tokenID := uuid.New()
+		userUC.EXPECT().CreateAccessToken(gomock.Any(), userUUID, "deploy script", []string{models.APIKeyScopeUsersRead}, time.Hour).Return(
+			"ujp_secret", &models.PersonalAccessToken{TokenID: tokenID, UserID: userUUID, Name: "deploy script", TokenHash: "hash"}, nil,
+		)
+
+		res := serve(&dto.AccessTokenCreateRequestDto{Name: "deploy script", Scopes: []string{models.APIKeyScopeUsersRead}, ExpiresIn: 3600})
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.NotContains(t, res.Body.String(), "hash")
+
+		resDto := &dto.AccessTokenCreateResponseDto{}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), resDto))
+		require.Equal(t, "ujp_secret", resDto.Token)
+		require.Equal(t, tokenID, resDto.AccessToken.TokenID)
+	})
+
+	t.Run("Scope beyond the user", func(t *testing.T) {
+		userUC.EXPECT().CreateAccessToken(gomock.Any(), userUUID, "admin script", []string{models.APIKeyScopeUsersWrite}, time.Duration(0)).
+			Return("", nil, domain_errors.ErrScopeNotAllowed)
+
+		res := serve(&dto.AccessTokenCreateRequestDto{Name: "admin script", Scopes: []string{models.APIKeyScopeUsersWrite}})
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("Unknown scope", func(t *testing.T) {
+		res := serve(&dto.AccessTokenCreateRequestDto{Name: "script", Scopes: []string{"users:delete"}})
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
 }
@@ -1,16 +1,58 @@
 package handlers
 
+import (
+	"time"
+
+	"github.com/dinorain/useraja/internal/models"
+)
+
 func (h *userHandlersHTTP) UserMapRoutes() {
+	reauthMaxAge := time.Duration(h.cfg.Session.ReauthMaxAge) * time.Second
+	signupWindow := time.Duration(h.cfg.Signup.RateLimitWindow) * time.Second
+	magicLinkWindow := time.Duration(h.cfg.MagicLink.RateLimitWindow) * time.Second
+	otpWindow := time.Duration(h.cfg.OTP.RateLimitWindow) * time.Second
+
+	// routes issuing tokens or credential cookies are mapped before Idempotency so their responses are never stored
 	h.group.POST("/refresh", h.RefreshToken())
 	h.group.POST("/login", h.Login())
+	h.group.POST("/invitations/accept", h.AcceptInvitation())
+	h.group.POST("/magic-link", h.RequestMagicLink(), h.mw.RateLimit("magic-link", h.cfg.MagicLink.RateLimit, magicLinkWindow))
+	h.group.POST("/login/code", h.LoginWithCode(), h.mw.RateLimit("login-code-verify", h.cfg.OTP.RateLimit, otpWindow))
+	h.group.POST("/login/sms", h.LoginWithSMSCode(), h.mw.RateLimit("login-sms-verify", h.cfg.OTP.RateLimit, otpWindow))
+	h.group.POST("/magic-link/verify", h.VerifyMagicLink(), h.mw.RateLimit("magic-link-verify", h.cfg.MagicLink.RateLimit, magicLinkWindow))
+	h.group.POST("/me/step-up", h.StepUp(), h.mw.IsLoggedIn())
+	h.group.POST("/me/tokens", h.CreateAccessToken(), h.mw.IsLoggedIn(), h.mw.RequireRecentAuth(reauthMaxAge))
+
+	h.group.Use(h.mw.Idempotency)
+	h.group.POST("/signup", h.Signup(), h.mw.RateLimit("signup", h.cfg.Signup.RateLimit, signupWindow))
+	h.group.POST("/verify-email", h.VerifyEmail())
+	h.group.POST("/verify-email/resend", h.ResendEmailVerification(), h.mw.RateLimit("verify-email", h.cfg.Signup.RateLimit, signupWindow))
+	h.group.POST("/login/code/request", h.RequestLoginCode(), h.mw.RateLimit("login-code", h.cfg.OTP.RateLimit, otpWindow))
+	h.group.POST("/login/sms/request", h.RequestSMSLoginCode(), h.mw.RateLimit("login-sms", h.cfg.OTP.RateLimit, otpWindow))
 
 	h.group.Use(h.mw.IsLoggedIn())
-	h.group.POST("/logout", h.Logout())
-	h.group.GET("/:id", h.FindById())
+	h.group.POST("/logout", h.Logout(), h.mw.AllowOAuthClient)
+	h.group.GET("/:id", h.FindById(), h.mw.RequireScope(models.APIKeyScopeUsersRead))
 	h.group.PUT("/:id", h.UpdateById())
+	h.group.PATCH("/:id", h.PatchById())
 	h.group.GET("/me", h.GetMe())
+	h.group.POST("/me/deactivate", h.DeactivateMe(), h.mw.RequireRecentAuth(reauthMaxAge))
+	h.group.POST("/me/reauthenticate", h.Reauthenticate())
+	h.group.POST("/me/phone", h.RequestPhoneVerification(), h.mw.RequireRecentAuth(reauthMaxAge))
+	h.group.POST("/me/phone/verify", h.VerifyPhoneNumber(), h.mw.RateLimit("phone-verify", h.cfg.OTP.RateLimit, otpWindow))
+	h.group.POST("/me/step-up/request", h.RequestStepUpCode(), h.mw.RateLimit("step-up", h.cfg.OTP.RateLimit, otpWindow))
+	h.group.GET("/me/devices", h.FindTrustedDevices())
+	h.group.DELETE("/me/devices/:device_id", h.RevokeTrustedDevice())
+	h.group.GET("/me/tokens", h.FindAccessTokens())
+	h.group.DELETE("/me/tokens/:token_id", h.RevokeAccessToken())
 
-	h.group.GET("", h.FindAll())
-	h.group.POST("", h.Register(), h.mw.IsAdmin)
-	h.group.DELETE("/:id", h.DeleteById(), h.mw.IsAdmin)
+	h.group.GET("", h.FindAll(), h.mw.RequireScope(models.APIKeyScopeUsersRead))
+	h.group.POST("", h.Register(), h.mw.IsAdminOrScope(models.APIKeyScopeUsersWrite))
+	h.group.DELETE("/:id", h.DeleteById(), h.mw.IsAdmin, h.mw.RequireStepUp(time.Duration(h.cfg.OTP.StepUpMaxAge)*time.Second))
+	h.group.POST("/invitations", h.Invite(), h.mw.IsAdminOrScope(models.APIKeyScopeUsersWrite))
+	h.group.POST("/:id/invitation/resend", h.ResendInvitation(), h.mw.IsAdminOrScope(models.APIKeyScopeUsersWrite))
+	h.group.DELETE("/:id/invitation", h.RevokeInvitation(), h.mw.IsAdminOrScope(models.APIKeyScopeUsersWrite))
+	h.group.POST("/:id/restore", h.RestoreById(), h.mw.IsAdminOrScope(models.APIKeyScopeUsersWrite))
+	h.group.POST("/:id/suspend", h.SuspendById(), h.mw.IsAdminOrScope(models.APIKeyScopeUsersWrite))
+	h.group.POST("/:id/reactivate", h.ReactivateById(), h.mw.IsAdminOrScope(models.APIKeyScopeUsersWrite))
 }
@@ -5,12 +5,40 @@ import "github.com/labstack/echo/v4"
 // User HTTP Handlers interface
 type UserHandlers interface {
 	Register() echo.HandlerFunc
+	Signup() echo.HandlerFunc
+	VerifyEmail() echo.HandlerFunc
+	ResendEmailVerification() echo.HandlerFunc
 	Login() echo.HandlerFunc
+	RequestLoginCode() echo.HandlerFunc
+	LoginWithCode() echo.HandlerFunc
+	RequestSMSLoginCode() echo.HandlerFunc
+	LoginWithSMSCode() echo.HandlerFunc
+	RequestPhoneVerification() echo.HandlerFunc
+	VerifyPhoneNumber() echo.HandlerFunc
+	RequestStepUpCode() echo.HandlerFunc
+	StepUp() echo.HandlerFunc
+	Reauthenticate() echo.HandlerFunc
+	FindTrustedDevices() echo.HandlerFunc
+	RevokeTrustedDevice() echo.HandlerFunc
+	CreateAccessToken() echo.HandlerFunc
+	FindAccessTokens() echo.HandlerFunc
+	RevokeAccessToken() echo.HandlerFunc
+	RequestMagicLink() echo.HandlerFunc
+	VerifyMagicLink() echo.HandlerFunc
+	Invite() echo.HandlerFunc
+	ResendInvitation() echo.HandlerFunc
+	RevokeInvitation() echo.HandlerFunc
+	AcceptInvitation() echo.HandlerFunc
 	GetMe() echo.HandlerFunc
 	FindAll() echo.HandlerFunc
 	FindById() echo.HandlerFunc
 	UpdateById() echo.HandlerFunc
+	PatchById() echo.HandlerFunc
 	DeleteById() echo.HandlerFunc
+	RestoreById() echo.HandlerFunc
+	SuspendById() echo.HandlerFunc
+	ReactivateById() echo.HandlerFunc
+	DeactivateMe() echo.HandlerFunc
 	Logout() echo.HandlerFunc
 	RefreshToken() echo.HandlerFunc
 }
@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/dinorain/useraja/internal/models"
 	utils "github.com/dinorain/useraja/pkg/utils"
@@ -37,6 +38,36 @@ func (m *MockUserPGRepository) EXPECT() *MockUserPGRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AcceptInvitation mocks base method.
+func (m *MockUserPGRepository) AcceptInvitation(ctx context.Context, userID uuid.UUID, password string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, userID, password)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockUserPGRepositoryMockRecorder) AcceptInvitation(ctx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockUserPGRepository)(nil).AcceptInvitation), ctx, userID, password)
+}
+
+// CountAll mocks base method.
+func (m *MockUserPGRepository) CountAll(ctx context.Context, filter *models.UserFilter) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAll", ctx, filter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAll indicates an expected call of CountAll.
+func (mr *MockUserPGRepositoryMockRecorder) CountAll(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAll", reflect.TypeOf((*MockUserPGRepository)(nil).CountAll), ctx, filter)
+}
+
 // Create mocks base method.
 func (m *MockUserPGRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -66,19 +97,48 @@ func (mr *MockUserPGRepositoryMockRecorder) DeleteById(ctx, userID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockUserPGRepository)(nil).DeleteById), ctx, userID)
 }
 
+// DeleteInvited mocks base method.
+func (m *MockUserPGRepository) DeleteInvited(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInvited", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteInvited indicates an expected call of DeleteInvited.
+func (mr *MockUserPGRepositoryMockRecorder) DeleteInvited(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInvited", reflect.TypeOf((*MockUserPGRepository)(nil).DeleteInvited), ctx, userID)
+}
+
 // FindAll mocks base method.
-func (m *MockUserPGRepository) FindAll(ctx context.Context, pagination *utils.Pagination) ([]models.User, error) {
+func (m *MockUserPGRepository) FindAll(ctx context.Context, filter *models.UserFilter, pagination *utils.Pagination) ([]models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", ctx, pagination)
+	ret := m.ctrl.Call(m, "FindAll", ctx, filter, pagination)
 	ret0, _ := ret[0].([]models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindAll indicates an expected call of FindAll.
-func (mr *MockUserPGRepositoryMockRecorder) FindAll(ctx, pagination interface{}) *gomock.Call {
+func (mr *MockUserPGRepositoryMockRecorder) FindAll(ctx, filter, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockUserPGRepository)(nil).FindAll), ctx, filter, pagination)
+}
+
+// FindAllByCursor mocks base method.
+func (m *MockUserPGRepository) FindAllByCursor(ctx context.Context, filter *models.UserFilter, after *models.UserCursor, desc bool, limit int) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllByCursor", ctx, filter, after, desc, limit)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllByCursor indicates an expected call of FindAllByCursor.
+func (mr *MockUserPGRepositoryMockRecorder) FindAllByCursor(ctx, filter, after, desc, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockUserPGRepository)(nil).FindAll), ctx, pagination)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllByCursor", reflect.TypeOf((*MockUserPGRepository)(nil).FindAllByCursor), ctx, filter, after, desc, limit)
 }
 
 // FindByEmail mocks base method.
@@ -111,6 +171,66 @@ func (mr *MockUserPGRepositoryMockRecorder) FindById(ctx, userID interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockUserPGRepository)(nil).FindById), ctx, userID)
 }
 
+// FindByVerifiedPhone mocks base method.
+func (m *MockUserPGRepository) FindByVerifiedPhone(ctx context.Context, phoneNumber string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByVerifiedPhone", ctx, phoneNumber)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByVerifiedPhone indicates an expected call of FindByVerifiedPhone.
+func (mr *MockUserPGRepositoryMockRecorder) FindByVerifiedPhone(ctx, phoneNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByVerifiedPhone", reflect.TypeOf((*MockUserPGRepository)(nil).FindByVerifiedPhone), ctx, phoneNumber)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockUserPGRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int, anonymize bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, before, limit, anonymize)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockUserPGRepositoryMockRecorder) PurgeDeleted(ctx, before, limit, anonymize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockUserPGRepository)(nil).PurgeDeleted), ctx, before, limit, anonymize)
+}
+
+// RestoreById mocks base method.
+func (m *MockUserPGRepository) RestoreById(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreById", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreById indicates an expected call of RestoreById.
+func (mr *MockUserPGRepositoryMockRecorder) RestoreById(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreById", reflect.TypeOf((*MockUserPGRepository)(nil).RestoreById), ctx, userID)
+}
+
+// SetPhoneNumber mocks base method.
+func (m *MockUserPGRepository) SetPhoneNumber(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPhoneNumber", ctx, userID, phoneNumber)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPhoneNumber indicates an expected call of SetPhoneNumber.
+func (mr *MockUserPGRepositoryMockRecorder) SetPhoneNumber(ctx, userID, phoneNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPhoneNumber", reflect.TypeOf((*MockUserPGRepository)(nil).SetPhoneNumber), ctx, userID, phoneNumber)
+}
+
 // UpdateById mocks base method.
 func (m *MockUserPGRepository) UpdateById(ctx context.Context, user *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -125,3 +245,48 @@ func (mr *MockUserPGRepositoryMockRecorder) UpdateById(ctx, user interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockUserPGRepository)(nil).UpdateById), ctx, user)
 }
+
+// UpdateStatus mocks base method.
+func (m *MockUserPGRepository) UpdateStatus(ctx context.Context, change *models.UserStatusChange, from string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, change, from)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockUserPGRepositoryMockRecorder) UpdateStatus(ctx, change, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockUserPGRepository)(nil).UpdateStatus), ctx, change, from)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserPGRepository) VerifyEmail(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserPGRepositoryMockRecorder) VerifyEmail(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserPGRepository)(nil).VerifyEmail), ctx, userID)
+}
+
+// VerifyPhoneNumber mocks base method.
+func (m *MockUserPGRepository) VerifyPhoneNumber(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPhoneNumber", ctx, userID, phoneNumber)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyPhoneNumber indicates an expected call of VerifyPhoneNumber.
+func (mr *MockUserPGRepositoryMockRecorder) VerifyPhoneNumber(ctx, userID, phoneNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPhoneNumber", reflect.TypeOf((*MockUserPGRepository)(nil).VerifyPhoneNumber), ctx, userID, phoneNumber)
+}
@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/dinorain/useraja/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,20 @@ func (m *MockUserRedisRepository) EXPECT() *MockUserRedisRepositoryMockRecorder
 	return m.recorder
 }
 
+// DeleteTokenCtx mocks base method.
+func (m *MockUserRedisRepository) DeleteTokenCtx(ctx context.Context, purpose, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTokenCtx", ctx, purpose, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTokenCtx indicates an expected call of DeleteTokenCtx.
+func (mr *MockUserRedisRepositoryMockRecorder) DeleteTokenCtx(ctx, purpose, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTokenCtx", reflect.TypeOf((*MockUserRedisRepository)(nil).DeleteTokenCtx), ctx, purpose, userID)
+}
+
 // DeleteUserCtx mocks base method.
 func (m *MockUserRedisRepository) DeleteUserCtx(ctx context.Context, key string) error {
 	m.ctrl.T.Helper()
@@ -64,6 +79,35 @@ func (mr *MockUserRedisRepositoryMockRecorder) GetByIdCtx(ctx, key interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdCtx", reflect.TypeOf((*MockUserRedisRepository)(nil).GetByIdCtx), ctx, key)
 }
 
+// GetDelTokenCtx mocks base method.
+func (m *MockUserRedisRepository) GetDelTokenCtx(ctx context.Context, purpose, tokenHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelTokenCtx", ctx, purpose, tokenHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelTokenCtx indicates an expected call of GetDelTokenCtx.
+func (mr *MockUserRedisRepositoryMockRecorder) GetDelTokenCtx(ctx, purpose, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelTokenCtx", reflect.TypeOf((*MockUserRedisRepository)(nil).GetDelTokenCtx), ctx, purpose, tokenHash)
+}
+
+// SetTokenCtx mocks base method.
+func (m *MockUserRedisRepository) SetTokenCtx(ctx context.Context, purpose, tokenHash, userID string, expire time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTokenCtx", ctx, purpose, tokenHash, userID, expire)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTokenCtx indicates an expected call of SetTokenCtx.
+func (mr *MockUserRedisRepositoryMockRecorder) SetTokenCtx(ctx, purpose, tokenHash, userID, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTokenCtx", reflect.TypeOf((*MockUserRedisRepository)(nil).SetTokenCtx), ctx, purpose, tokenHash, userID, expire)
+}
+
 // SetUserCtx mocks base method.
 func (m *MockUserRedisRepository) SetUserCtx(ctx context.Context, key string, seconds int, user *models.User) error {
 	m.ctrl.T.Helper()
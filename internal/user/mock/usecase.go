@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/dinorain/useraja/internal/models"
 	utils "github.com/dinorain/useraja/pkg/utils"
@@ -37,6 +38,37 @@ func (m *MockUserUseCase) EXPECT() *MockUserUseCaseMockRecorder {
 	return m.recorder
 }
 
+// AcceptInvitation mocks base method.
+func (m *MockUserUseCase) AcceptInvitation(ctx context.Context, token, password string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, token, password)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockUserUseCaseMockRecorder) AcceptInvitation(ctx, token, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockUserUseCase)(nil).AcceptInvitation), ctx, token, password)
+}
+
+// AuthenticateAccessToken mocks base method.
+func (m *MockUserUseCase) AuthenticateAccessToken(ctx context.Context, token string) (*models.PersonalAccessToken, *models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAccessToken", ctx, token)
+	ret0, _ := ret[0].(*models.PersonalAccessToken)
+	ret1, _ := ret[1].(*models.User)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuthenticateAccessToken indicates an expected call of AuthenticateAccessToken.
+func (mr *MockUserUseCaseMockRecorder) AuthenticateAccessToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAccessToken", reflect.TypeOf((*MockUserUseCase)(nil).AuthenticateAccessToken), ctx, token)
+}
+
 // CachedFindById mocks base method.
 func (m *MockUserUseCase) CachedFindById(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +84,37 @@ func (mr *MockUserUseCaseMockRecorder) CachedFindById(ctx, userID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CachedFindById", reflect.TypeOf((*MockUserUseCase)(nil).CachedFindById), ctx, userID)
 }
 
+// ChangeStatus mocks base method.
+func (m *MockUserUseCase) ChangeStatus(ctx context.Context, change *models.UserStatusChange) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, change)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockUserUseCaseMockRecorder) ChangeStatus(ctx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockUserUseCase)(nil).ChangeStatus), ctx, change)
+}
+
+// CreateAccessToken mocks base method.
+func (m *MockUserUseCase) CreateAccessToken(ctx context.Context, userID uuid.UUID, name string, scopes []string, expire time.Duration) (string, *models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessToken", ctx, userID, name, scopes, expire)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*models.PersonalAccessToken)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockUserUseCaseMockRecorder) CreateAccessToken(ctx, userID, name, scopes, expire interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockUserUseCase)(nil).CreateAccessToken), ctx, userID, name, scopes, expire)
+}
+
 // DeleteById mocks base method.
 func (m *MockUserUseCase) DeleteById(ctx context.Context, userID uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -66,19 +129,34 @@ func (mr *MockUserUseCaseMockRecorder) DeleteById(ctx, userID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockUserUseCase)(nil).DeleteById), ctx, userID)
 }
 
+// FindAccessTokens mocks base method.
+func (m *MockUserUseCase) FindAccessTokens(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAccessTokens", ctx, userID)
+	ret0, _ := ret[0].([]models.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAccessTokens indicates an expected call of FindAccessTokens.
+func (mr *MockUserUseCaseMockRecorder) FindAccessTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAccessTokens", reflect.TypeOf((*MockUserUseCase)(nil).FindAccessTokens), ctx, userID)
+}
+
 // FindAll mocks base method.
-func (m *MockUserUseCase) FindAll(ctx context.Context, pagination *utils.Pagination) ([]models.User, error) {
+func (m *MockUserUseCase) FindAll(ctx context.Context, filter *models.UserFilter, pagination *utils.Pagination) (*models.UsersList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", ctx, pagination)
-	ret0, _ := ret[0].([]models.User)
+	ret := m.ctrl.Call(m, "FindAll", ctx, filter, pagination)
+	ret0, _ := ret[0].(*models.UsersList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindAll indicates an expected call of FindAll.
-func (mr *MockUserUseCaseMockRecorder) FindAll(ctx, pagination interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) FindAll(ctx, filter, pagination interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockUserUseCase)(nil).FindAll), ctx, pagination)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockUserUseCase)(nil).FindAll), ctx, filter, pagination)
 }
 
 // FindByEmail mocks base method.
@@ -111,6 +189,37 @@ func (mr *MockUserUseCaseMockRecorder) FindById(ctx, userID interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockUserUseCase)(nil).FindById), ctx, userID)
 }
 
+// FindTrustedDevices mocks base method.
+func (m *MockUserUseCase) FindTrustedDevices(ctx context.Context, userID uuid.UUID) ([]models.TrustedDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTrustedDevices", ctx, userID)
+	ret0, _ := ret[0].([]models.TrustedDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTrustedDevices indicates an expected call of FindTrustedDevices.
+func (mr *MockUserUseCaseMockRecorder) FindTrustedDevices(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTrustedDevices", reflect.TypeOf((*MockUserUseCase)(nil).FindTrustedDevices), ctx, userID)
+}
+
+// GenerateClientTokenPair mocks base method.
+func (m *MockUserUseCase) GenerateClientTokenPair(user *models.User, session *models.Session) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateClientTokenPair", user, session)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GenerateClientTokenPair indicates an expected call of GenerateClientTokenPair.
+func (mr *MockUserUseCaseMockRecorder) GenerateClientTokenPair(user, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateClientTokenPair", reflect.TypeOf((*MockUserUseCase)(nil).GenerateClientTokenPair), user, session)
+}
+
 // GenerateTokenPair mocks base method.
 func (m *MockUserUseCase) GenerateTokenPair(user *models.User, sessionID string) (string, string, error) {
 	m.ctrl.T.Helper()
@@ -127,6 +236,21 @@ func (mr *MockUserUseCaseMockRecorder) GenerateTokenPair(user, sessionID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateTokenPair", reflect.TypeOf((*MockUserUseCase)(nil).GenerateTokenPair), user, sessionID)
 }
 
+// Invite mocks base method.
+func (m *MockUserUseCase) Invite(ctx context.Context, user *models.User) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Invite", ctx, user)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Invite indicates an expected call of Invite.
+func (mr *MockUserUseCaseMockRecorder) Invite(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invite", reflect.TypeOf((*MockUserUseCase)(nil).Invite), ctx, user)
+}
+
 // Login mocks base method.
 func (m *MockUserUseCase) Login(ctx context.Context, email, password string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -142,6 +266,81 @@ func (mr *MockUserUseCaseMockRecorder) Login(ctx, email, password interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserUseCase)(nil).Login), ctx, email, password)
 }
 
+// LoginWithCode mocks base method.
+func (m *MockUserUseCase) LoginWithCode(ctx context.Context, email, code string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginWithCode", ctx, email, code)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginWithCode indicates an expected call of LoginWithCode.
+func (mr *MockUserUseCaseMockRecorder) LoginWithCode(ctx, email, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWithCode", reflect.TypeOf((*MockUserUseCase)(nil).LoginWithCode), ctx, email, code)
+}
+
+// LoginWithMagicLink mocks base method.
+func (m *MockUserUseCase) LoginWithMagicLink(ctx context.Context, token, nonce string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginWithMagicLink", ctx, token, nonce)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginWithMagicLink indicates an expected call of LoginWithMagicLink.
+func (mr *MockUserUseCaseMockRecorder) LoginWithMagicLink(ctx, token, nonce interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWithMagicLink", reflect.TypeOf((*MockUserUseCase)(nil).LoginWithMagicLink), ctx, token, nonce)
+}
+
+// LoginWithSMSCode mocks base method.
+func (m *MockUserUseCase) LoginWithSMSCode(ctx context.Context, phoneNumber, code string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginWithSMSCode", ctx, phoneNumber, code)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginWithSMSCode indicates an expected call of LoginWithSMSCode.
+func (mr *MockUserUseCaseMockRecorder) LoginWithSMSCode(ctx, phoneNumber, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWithSMSCode", reflect.TypeOf((*MockUserUseCase)(nil).LoginWithSMSCode), ctx, phoneNumber, code)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockUserUseCase) PurgeDeleted(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockUserUseCaseMockRecorder) PurgeDeleted(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockUserUseCase)(nil).PurgeDeleted), ctx)
+}
+
+// Reauthenticate mocks base method.
+func (m *MockUserUseCase) Reauthenticate(ctx context.Context, session *models.Session, password string) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reauthenticate", ctx, session, password)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reauthenticate indicates an expected call of Reauthenticate.
+func (mr *MockUserUseCaseMockRecorder) Reauthenticate(ctx, session, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reauthenticate", reflect.TypeOf((*MockUserUseCase)(nil).Reauthenticate), ctx, session, password)
+}
+
 // Register mocks base method.
 func (m *MockUserUseCase) Register(ctx context.Context, user *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -157,6 +356,251 @@ func (mr *MockUserUseCaseMockRecorder) Register(ctx, user interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserUseCase)(nil).Register), ctx, user)
 }
 
+// RequestLoginCode mocks base method.
+func (m *MockUserUseCase) RequestLoginCode(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestLoginCode", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestLoginCode indicates an expected call of RequestLoginCode.
+func (mr *MockUserUseCaseMockRecorder) RequestLoginCode(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestLoginCode", reflect.TypeOf((*MockUserUseCase)(nil).RequestLoginCode), ctx, email)
+}
+
+// RequestMagicLink mocks base method.
+func (m *MockUserUseCase) RequestMagicLink(ctx context.Context, email, nonce string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestMagicLink", ctx, email, nonce)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestMagicLink indicates an expected call of RequestMagicLink.
+func (mr *MockUserUseCaseMockRecorder) RequestMagicLink(ctx, email, nonce interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestMagicLink", reflect.TypeOf((*MockUserUseCase)(nil).RequestMagicLink), ctx, email, nonce)
+}
+
+// RequestPhoneVerification mocks base method.
+func (m *MockUserUseCase) RequestPhoneVerification(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPhoneVerification", ctx, userID, phoneNumber)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestPhoneVerification indicates an expected call of RequestPhoneVerification.
+func (mr *MockUserUseCaseMockRecorder) RequestPhoneVerification(ctx, userID, phoneNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPhoneVerification", reflect.TypeOf((*MockUserUseCase)(nil).RequestPhoneVerification), ctx, userID, phoneNumber)
+}
+
+// RequestSMSLoginCode mocks base method.
+func (m *MockUserUseCase) RequestSMSLoginCode(ctx context.Context, phoneNumber string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestSMSLoginCode", ctx, phoneNumber)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestSMSLoginCode indicates an expected call of RequestSMSLoginCode.
+func (mr *MockUserUseCaseMockRecorder) RequestSMSLoginCode(ctx, phoneNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestSMSLoginCode", reflect.TypeOf((*MockUserUseCase)(nil).RequestSMSLoginCode), ctx, phoneNumber)
+}
+
+// RequestStepUpCode mocks base method.
+func (m *MockUserUseCase) RequestStepUpCode(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestStepUpCode", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestStepUpCode indicates an expected call of RequestStepUpCode.
+func (mr *MockUserUseCaseMockRecorder) RequestStepUpCode(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestStepUpCode", reflect.TypeOf((*MockUserUseCase)(nil).RequestStepUpCode), ctx, userID)
+}
+
+// ResendEmailVerification mocks base method.
+func (m *MockUserUseCase) ResendEmailVerification(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendEmailVerification", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendEmailVerification indicates an expected call of ResendEmailVerification.
+func (mr *MockUserUseCaseMockRecorder) ResendEmailVerification(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendEmailVerification", reflect.TypeOf((*MockUserUseCase)(nil).ResendEmailVerification), ctx, email)
+}
+
+// ResendInvitation mocks base method.
+func (m *MockUserUseCase) ResendInvitation(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendInvitation", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendInvitation indicates an expected call of ResendInvitation.
+func (mr *MockUserUseCaseMockRecorder) ResendInvitation(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendInvitation", reflect.TypeOf((*MockUserUseCase)(nil).ResendInvitation), ctx, userID)
+}
+
+// RestoreById mocks base method.
+func (m *MockUserUseCase) RestoreById(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreById", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreById indicates an expected call of RestoreById.
+func (mr *MockUserUseCaseMockRecorder) RestoreById(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreById", reflect.TypeOf((*MockUserUseCase)(nil).RestoreById), ctx, userID)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockUserUseCase) RevokeAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", ctx, userID, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockUserUseCaseMockRecorder) RevokeAccessToken(ctx, userID, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockUserUseCase)(nil).RevokeAccessToken), ctx, userID, tokenID)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockUserUseCase) RevokeInvitation(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockUserUseCaseMockRecorder) RevokeInvitation(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockUserUseCase)(nil).RevokeInvitation), ctx, userID)
+}
+
+// RevokeTrustedDevice mocks base method.
+func (m *MockUserUseCase) RevokeTrustedDevice(ctx context.Context, userID, deviceID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTrustedDevice", ctx, userID, deviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTrustedDevice indicates an expected call of RevokeTrustedDevice.
+func (mr *MockUserUseCaseMockRecorder) RevokeTrustedDevice(ctx, userID, deviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTrustedDevice", reflect.TypeOf((*MockUserUseCase)(nil).RevokeTrustedDevice), ctx, userID, deviceID)
+}
+
+// RevokeTrustedDevices mocks base method.
+func (m *MockUserUseCase) RevokeTrustedDevices(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTrustedDevices", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTrustedDevices indicates an expected call of RevokeTrustedDevices.
+func (mr *MockUserUseCaseMockRecorder) RevokeTrustedDevices(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTrustedDevices", reflect.TypeOf((*MockUserUseCase)(nil).RevokeTrustedDevices), ctx, userID)
+}
+
+// Signup mocks base method.
+func (m *MockUserUseCase) Signup(ctx context.Context, user *models.User, inviteCode string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Signup", ctx, user, inviteCode)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Signup indicates an expected call of Signup.
+func (mr *MockUserUseCaseMockRecorder) Signup(ctx, user, inviteCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signup", reflect.TypeOf((*MockUserUseCase)(nil).Signup), ctx, user, inviteCode)
+}
+
+// StepUp mocks base method.
+func (m *MockUserUseCase) StepUp(ctx context.Context, session *models.Session, code string) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StepUp", ctx, session, code)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StepUp indicates an expected call of StepUp.
+func (mr *MockUserUseCaseMockRecorder) StepUp(ctx, session, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StepUp", reflect.TypeOf((*MockUserUseCase)(nil).StepUp), ctx, session, code)
+}
+
+// StreamAll mocks base method.
+func (m *MockUserUseCase) StreamAll(ctx context.Context, filter *models.UserFilter, batchSize int, send func([]models.User) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamAll", ctx, filter, batchSize, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamAll indicates an expected call of StreamAll.
+func (mr *MockUserUseCaseMockRecorder) StreamAll(ctx, filter, batchSize, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAll", reflect.TypeOf((*MockUserUseCase)(nil).StreamAll), ctx, filter, batchSize, send)
+}
+
+// TouchTrustedDevice mocks base method.
+func (m *MockUserUseCase) TouchTrustedDevice(ctx context.Context, userID uuid.UUID, signedToken, ip string) (*models.TrustedDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchTrustedDevice", ctx, userID, signedToken, ip)
+	ret0, _ := ret[0].(*models.TrustedDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TouchTrustedDevice indicates an expected call of TouchTrustedDevice.
+func (mr *MockUserUseCaseMockRecorder) TouchTrustedDevice(ctx, userID, signedToken, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchTrustedDevice", reflect.TypeOf((*MockUserUseCase)(nil).TouchTrustedDevice), ctx, userID, signedToken, ip)
+}
+
+// TrustDevice mocks base method.
+func (m *MockUserUseCase) TrustDevice(ctx context.Context, userID uuid.UUID, name, ip string) (string, *models.TrustedDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrustDevice", ctx, userID, name, ip)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*models.TrustedDevice)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TrustDevice indicates an expected call of TrustDevice.
+func (mr *MockUserUseCaseMockRecorder) TrustDevice(ctx, userID, name, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrustDevice", reflect.TypeOf((*MockUserUseCase)(nil).TrustDevice), ctx, userID, name, ip)
+}
+
 // UpdateById mocks base method.
 func (m *MockUserUseCase) UpdateById(ctx context.Context, user *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -171,3 +615,33 @@ func (mr *MockUserUseCaseMockRecorder) UpdateById(ctx, user interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockUserUseCase)(nil).UpdateById), ctx, user)
 }
+
+// VerifyEmail mocks base method.
+func (m *MockUserUseCase) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserUseCaseMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserUseCase)(nil).VerifyEmail), ctx, token)
+}
+
+// VerifyPhoneNumber mocks base method.
+func (m *MockUserUseCase) VerifyPhoneNumber(ctx context.Context, userID uuid.UUID, code string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPhoneNumber", ctx, userID, code)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyPhoneNumber indicates an expected call of VerifyPhoneNumber.
+func (mr *MockUserUseCaseMockRecorder) VerifyPhoneNumber(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPhoneNumber", reflect.TypeOf((*MockUserUseCase)(nil).VerifyPhoneNumber), ctx, userID, code)
+}
@@ -3,6 +3,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -13,9 +14,20 @@ import (
 // User pg repository
 type UserPGRepository interface {
 	Create(ctx context.Context, user *models.User) (*models.User, error)
-	FindAll(ctx context.Context, pagination *utils.Pagination) ([]models.User, error)
+	FindAll(ctx context.Context, filter *models.UserFilter, pagination *utils.Pagination) ([]models.User, error)
+	FindAllByCursor(ctx context.Context, filter *models.UserFilter, after *models.UserCursor, desc bool, limit int) ([]models.User, error)
+	CountAll(ctx context.Context, filter *models.UserFilter) (int, error)
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	FindById(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	UpdateById(ctx context.Context, user *models.User) (*models.User, error)
 	DeleteById(ctx context.Context, userID uuid.UUID) error
+	RestoreById(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	PurgeDeleted(ctx context.Context, before time.Time, limit int, anonymize bool) (int, error)
+	DeleteInvited(ctx context.Context, userID uuid.UUID) error
+	AcceptInvitation(ctx context.Context, userID uuid.UUID, password string) (*models.User, error)
+	VerifyEmail(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	FindByVerifiedPhone(ctx context.Context, phoneNumber string) (*models.User, error)
+	SetPhoneNumber(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error)
+	VerifyPhoneNumber(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error)
+	UpdateStatus(ctx context.Context, change *models.UserStatusChange, from string) (*models.User, error)
 }
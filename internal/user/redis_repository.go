@@ -3,6 +3,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/dinorain/useraja/internal/models"
 )
@@ -12,4 +13,7 @@ type UserRedisRepository interface {
 	GetByIdCtx(ctx context.Context, key string) (*models.User, error)
 	SetUserCtx(ctx context.Context, key string, seconds int, user *models.User) error
 	DeleteUserCtx(ctx context.Context, key string) error
+	SetTokenCtx(ctx context.Context, purpose string, tokenHash string, userID string, expire time.Duration) error
+	GetDelTokenCtx(ctx context.Context, purpose string, tokenHash string) (string, error)
+	DeleteTokenCtx(ctx context.Context, purpose string, userID string) error
 }
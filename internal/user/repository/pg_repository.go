@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -37,6 +40,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models
 		user.Password,
 		user.Role,
 		user.Avatar,
+		user.Status,
 	).StructScan(createdUser); err != nil {
 		return nil, errors.Wrap(err, "UserRepository.Create.QueryRowxContext")
 	}
@@ -44,9 +48,10 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models
 	return createdUser, nil
 }
 
-// UpdateById update existing user
+// UpdateById update existing user when its stored version still matches user.Version, sql.ErrNoRows otherwise
 func (r *UserRepository) UpdateById(ctx context.Context, user *models.User) (*models.User, error) {
-	if res, err := r.db.ExecContext(
+	updatedUser := &models.User{}
+	if err := r.db.QueryRowxContext(
 		ctx,
 		updateByIdQuery,
 		user.UserID,
@@ -56,28 +61,58 @@ func (r *UserRepository) UpdateById(ctx context.Context, user *models.User) (*mo
 		user.Password,
 		user.Role,
 		user.Avatar,
-	); err != nil {
-		return nil, errors.Wrap(err, "UserRepository.Update.ExecContext")
-	} else {
-		_, err := res.RowsAffected()
-		if err != nil {
-			return nil, errors.Wrap(err, "UserRepository.Update.RowsAffected")
-		}
+		user.Version,
+	).StructScan(updatedUser); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.Update.QueryRowxContext")
 	}
 
-	return user, nil
+	return updatedUser, nil
+}
+
+// FindAll Find users matching filter ordered by the whitelisted pagination order
+func (r *UserRepository) FindAll(ctx context.Context, filter *models.UserFilter, pagination *utils.Pagination) ([]models.User, error) {
+	args := append(usersFilterArgs(filter), pagination.GetLimit(), pagination.GetOffset())
+
+	var users []models.User
+	if err := r.db.SelectContext(ctx, &users, fmt.Sprintf(findAllQuery, usersOrderBy(pagination.GetOrderBy())), args...); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.FindAll.SelectContext")
+	}
+
+	return users, nil
 }
 
-// FindAll Find users
-func (r *UserRepository) FindAll(ctx context.Context, pagination *utils.Pagination) ([]models.User, error) {
+// FindAllByCursor Find up to limit users matching filter after the cursor, walking (created_at, user_id) descending when desc is set
+func (r *UserRepository) FindAllByCursor(ctx context.Context, filter *models.UserFilter, after *models.UserCursor, desc bool, limit int) ([]models.User, error) {
+	query := findAllAfterCursorQuery
+	if desc {
+		query = findAllBeforeCursorQuery
+	}
+
+	args := usersFilterArgs(filter)
+	if after != nil {
+		args = append(args, after.CreatedAt, after.UserID, limit)
+	} else {
+		args = append(args, nil, nil, limit)
+	}
+
 	var users []models.User
-	if err := r.db.SelectContext(ctx, &users, findAllQuery, pagination.GetLimit(), pagination.GetOffset()); err != nil {
-		return nil, errors.Wrap(err, "UserRepository.FindById.SelectContext")
+	if err := r.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.FindAllByCursor.SelectContext")
 	}
 
 	return users, nil
 }
 
+// CountAll Count users matching filter
+func (r *UserRepository) CountAll(ctx context.Context, filter *models.UserFilter) (int, error) {
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, countAllQuery, usersFilterArgs(filter)...); err != nil {
+		return 0, errors.Wrap(err, "UserRepository.CountAll.GetContext")
+	}
+
+	return totalCount, nil
+}
+
 // FindByEmail Find by user email address
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
@@ -98,7 +133,7 @@ func (r *UserRepository) FindById(ctx context.Context, userID uuid.UUID) (*model
 	return user, nil
 }
 
-// DeleteById Find user by uuid
+// DeleteById Soft delete user by uuid
 func (r *UserRepository) DeleteById(ctx context.Context, userID uuid.UUID) error {
 	if res, err := r.db.ExecContext(ctx, deleteByIdQuery, userID); err != nil {
 		return errors.Wrap(err, "UserRepository.DeleteById.ExecContext")
@@ -113,3 +148,140 @@ func (r *UserRepository) DeleteById(ctx context.Context, userID uuid.UUID) error
 
 	return nil
 }
+
+// DeleteInvited Hard delete an invited user who did not accept the invitation yet
+func (r *UserRepository) DeleteInvited(ctx context.Context, userID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, deleteInvitedQuery, userID)
+	if err != nil {
+		return errors.Wrap(err, "UserRepository.DeleteInvited.ExecContext")
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "UserRepository.DeleteInvited.RowsAffected")
+	} else if cnt == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// AcceptInvitation Set the hashed password of an invited user and activate it, sql.ErrNoRows when the user is not invited
+func (r *UserRepository) AcceptInvitation(ctx context.Context, userID uuid.UUID, password string) (*models.User, error) {
+	user := &models.User{}
+	if err := r.db.QueryRowxContext(ctx, acceptInvitationQuery, userID, password).StructScan(user); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.AcceptInvitation.QueryRowxContext")
+	}
+
+	return user, nil
+}
+
+// FindByVerifiedPhone Find the user who verified the phone number
+func (r *UserRepository) FindByVerifiedPhone(ctx context.Context, phoneNumber string) (*models.User, error) {
+	user := &models.User{}
+	if err := r.db.GetContext(ctx, user, findByVerifiedPhoneQuery, phoneNumber); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.FindByVerifiedPhone.GetContext")
+	}
+
+	return user, nil
+}
+
+// SetPhoneNumber Set the phone number of user, it is unverified unless it did not change
+func (r *UserRepository) SetPhoneNumber(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error) {
+	user := &models.User{}
+	if err := r.db.QueryRowxContext(ctx, setPhoneNumberQuery, userID, phoneNumber).StructScan(user); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.SetPhoneNumber.QueryRowxContext")
+	}
+
+	return user, nil
+}
+
+// VerifyPhoneNumber Mark the phone number of user as verified, sql.ErrNoRows when the user has another number by now
+func (r *UserRepository) VerifyPhoneNumber(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error) {
+	user := &models.User{}
+	if err := r.db.QueryRowxContext(ctx, verifyPhoneNumberQuery, userID, phoneNumber).StructScan(user); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.VerifyPhoneNumber.QueryRowxContext")
+	}
+
+	return user, nil
+}
+
+// RestoreById Restore soft deleted user by uuid
+func (r *UserRepository) RestoreById(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user := &models.User{}
+	if err := r.db.QueryRowxContext(ctx, restoreByIdQuery, userID).StructScan(user); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.RestoreById.QueryRowxContext")
+	}
+
+	return user, nil
+}
+
+// PurgeDeleted Hard delete or anonymize up to limit users soft deleted before the given time
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int, anonymize bool) (int, error) {
+	query := purgeDeletedQuery
+	if anonymize {
+		query = anonymizeDeletedQuery
+	}
+
+	res, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, errors.Wrap(err, "UserRepository.PurgeDeleted.ExecContext")
+	}
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "UserRepository.PurgeDeleted.RowsAffected")
+	}
+
+	return int(cnt), nil
+}
+
+// VerifyEmail Mark the email of user as verified, the first verification time is kept
+func (r *UserRepository) VerifyEmail(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user := &models.User{}
+	if err := r.db.QueryRowxContext(ctx, verifyEmailQuery, userID).StructScan(user); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.VerifyEmail.QueryRowxContext")
+	}
+
+	return user, nil
+}
+
+// UpdateStatus Change user status from the expected status and record the change
+func (r *UserRepository) UpdateStatus(ctx context.Context, change *models.UserStatusChange, from string) (*models.User, error) {
+	user := &models.User{}
+	if err := r.db.QueryRowxContext(
+		ctx,
+		updateStatusQuery,
+		change.UserID,
+		from,
+		change.Status,
+		change.Reason,
+		change.ActorID,
+	).StructScan(user); err != nil {
+		return nil, errors.Wrap(err, "UserRepository.UpdateStatus.QueryRowxContext")
+	}
+
+	return user, nil
+}
+
+func usersFilterArgs(filter *models.UserFilter) []interface{} {
+	search := ""
+	if filter.Search != "" {
+		search = "%" + likeEscaper.Replace(filter.Search) + "%"
+	}
+	return []interface{}{filter.Role, search, filter.CreatedFrom, filter.CreatedTo, filter.Verified, filter.IncludeDeleted}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// usersOrderBy order by clause of a whitelisted sort, user_id breaks ties so pages are stable
+func usersOrderBy(orderBy string) string {
+	sort, err := models.ParseUserSort(orderBy)
+	if err != nil {
+		sort = &models.UserSort{Field: "created_at"}
+	}
+	direction := "ASC"
+	if sort.Desc {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s, user_id %s", sort.Field, direction, direction)
+}
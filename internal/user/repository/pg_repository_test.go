@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"testing"
 	"time"
 
@@ -57,6 +59,7 @@ func TestUserRepository_Create(t *testing.T) {
 		mockUser.Password,
 		mockUser.Role,
 		mockUser.Avatar,
+		mockUser.Status,
 	).WillReturnRows(rows)
 
 	createdUser, err := userPGRepository.Create(context.Background(), mockUser)
@@ -145,16 +148,83 @@ func TestUserRepository_FindAll(t *testing.T) {
 	)
 
 	size := 10
-	mock.ExpectQuery(findAllQuery).WithArgs(size, 0).WillReturnRows(rows)
-	foundUsers, err := userPGRepository.FindAll(context.Background(), utils.NewPaginationQuery(size, 1))
+	filter := &models.UserFilter{}
+	mock.ExpectQuery(fmt.Sprintf(findAllQuery, "created_at ASC, user_id ASC")).WithArgs("", "", nil, nil, nil, false, size, 0).WillReturnRows(rows)
+	foundUsers, err := userPGRepository.FindAll(context.Background(), filter, utils.NewPaginationQuery(size, 1))
 	require.NoError(t, err)
 	require.NotNil(t, foundUsers)
 	require.Equal(t, len(foundUsers), 1)
 
-	mock.ExpectQuery(findAllQuery).WithArgs(size, 10).WillReturnRows(rows)
-	foundUsers, err = userPGRepository.FindAll(context.Background(), utils.NewPaginationQuery(size, 2))
+	mock.ExpectQuery(fmt.Sprintf(findAllQuery, "created_at ASC, user_id ASC")).WithArgs("", "", nil, nil, nil, false, size, 10).WillReturnRows(rows)
+	foundUsers, err = userPGRepository.FindAll(context.Background(), filter, utils.NewPaginationQuery(size, 2))
 	require.NoError(t, err)
 	require.Nil(t, foundUsers)
+
+	t.Run("Filtered and sorted", func(t *testing.T) {
+		verified := true
+		pagination := utils.NewPaginationQuery(size, 1)
+		pagination.SetOrderBy("-email")
+
+		mock.ExpectQuery(fmt.Sprintf(findAllQuery, "email DESC, user_id DESC")).
+			WithArgs(models.UserRoleAdmin, `%50\%\_off%`, nil, nil, &verified, true, size, 0).
+			WillReturnRows(sqlmock.NewRows(columns))
+		_, err := userPGRepository.FindAll(context.Background(), &models.UserFilter{
+			Role:           models.UserRoleAdmin,
+			Search:         "50%_off",
+			Verified:       &verified,
+			IncludeDeleted: true,
+		}, pagination)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepository_CountAll(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	mock.ExpectQuery(countAllQuery).WithArgs(models.UserRoleUser, "", nil, nil, nil, false).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+	totalCount, err := userPGRepository.CountAll(context.Background(), &models.UserFilter{Role: models.UserRoleUser})
+	require.NoError(t, err)
+	require.Equal(t, 42, totalCount)
+}
+
+func TestUserRepository_FindAllByCursor(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at"}
+	filter := &models.UserFilter{Search: "50%"}
+	cursor := &models.UserCursor{CreatedAt: time.Now().Add(-time.Minute), UserID: uuid.New()}
+
+	mock.ExpectQuery(findAllAfterCursorQuery).WithArgs("", `%50\%%`, nil, nil, nil, false, nil, nil, 11).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(uuid.New(), "FirstName", "LastName", "email@gmail.com", "123456", nil, "user", time.Now(), time.Now()),
+	)
+	foundUsers, err := userPGRepository.FindAllByCursor(context.Background(), filter, nil, false, 11)
+	require.NoError(t, err)
+	require.Len(t, foundUsers, 1)
+
+	mock.ExpectQuery(findAllBeforeCursorQuery).WithArgs("", `%50\%%`, nil, nil, nil, false, cursor.CreatedAt, cursor.UserID, 11).WillReturnRows(sqlmock.NewRows(columns))
+	foundUsers, err = userPGRepository.FindAllByCursor(context.Background(), filter, cursor, true, 11)
+	require.NoError(t, err)
+	require.Empty(t, foundUsers)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestUserRepository_FindById(t *testing.T) {
@@ -213,46 +283,67 @@ func TestUserRepository_UpdateById(t *testing.T) {
 
 	userPGRepository := NewUserPGRepository(sqlxDB)
 
-	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at"}
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at", "version"}
 	userUUID := uuid.New()
 	mockUser := &models.User{
 		UserID:    userUUID,
 		Email:     "email@gmail.com",
-		FirstName: "FirstName",
+		FirstName: "FirstNameChanged",
 		LastName:  "LastName",
 		Role:      "admin",
 		Avatar:    nil,
 		Password:  "123456",
+		Version:   3,
 	}
 
-	_ = sqlmock.NewRows(columns).AddRow(
-		userUUID,
-		mockUser.FirstName,
-		mockUser.LastName,
-		mockUser.Email,
-		mockUser.Password,
-		mockUser.Avatar,
-		mockUser.Role,
-		time.Now(),
-		time.Now(),
-	)
-
-	mockUser.FirstName = "FirstNameChanged"
-	mock.ExpectExec(updateByIdQuery).WithArgs(
-		mockUser.UserID,
-		mockUser.FirstName,
-		mockUser.LastName,
-		mockUser.Email,
-		mockUser.Password,
-		mockUser.Role,
-		mockUser.Avatar,
-	).WillReturnResult(sqlmock.NewResult(0, 1))
-
-	updatedUser, err := userPGRepository.UpdateById(context.Background(), mockUser)
-	require.NoError(t, err)
-	require.NotNil(t, mockUser)
-	require.Equal(t, updatedUser.FirstName, mockUser.FirstName)
-	require.Equal(t, updatedUser.UserID, mockUser.UserID)
+	t.Run("Update", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).AddRow(
+			userUUID,
+			mockUser.FirstName,
+			mockUser.LastName,
+			mockUser.Email,
+			mockUser.Password,
+			mockUser.Avatar,
+			mockUser.Role,
+			time.Now(),
+			time.Now(),
+			mockUser.Version+1,
+		)
+
+		mock.ExpectQuery(updateByIdQuery).WithArgs(
+			mockUser.UserID,
+			mockUser.FirstName,
+			mockUser.LastName,
+			mockUser.Email,
+			mockUser.Password,
+			mockUser.Role,
+			mockUser.Avatar,
+			mockUser.Version,
+		).WillReturnRows(rows)
+
+		updatedUser, err := userPGRepository.UpdateById(context.Background(), mockUser)
+		require.NoError(t, err)
+		require.Equal(t, mockUser.FirstName, updatedUser.FirstName)
+		require.Equal(t, mockUser.UserID, updatedUser.UserID)
+		require.Equal(t, mockUser.Version+1, updatedUser.Version)
+	})
+
+	t.Run("Stale version", func(t *testing.T) {
+		mock.ExpectQuery(updateByIdQuery).WithArgs(
+			mockUser.UserID,
+			mockUser.FirstName,
+			mockUser.LastName,
+			mockUser.Email,
+			mockUser.Password,
+			mockUser.Role,
+			mockUser.Avatar,
+			mockUser.Version,
+		).WillReturnRows(sqlmock.NewRows(columns))
+
+		updatedUser, err := userPGRepository.UpdateById(context.Background(), mockUser)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.Nil(t, updatedUser)
+	})
 }
 
 func TestUserRepository_DeleteById(t *testing.T) {
@@ -296,4 +387,199 @@ func TestUserRepository_DeleteById(t *testing.T) {
 	err = userPGRepository.DeleteById(context.Background(), mockUser.UserID)
 	require.NoError(t, err)
 	require.NotNil(t, mockUser)
+
+	mock.ExpectExec(deleteByIdQuery).WithArgs(mockUser.UserID).WillReturnResult(sqlmock.NewResult(0, 0))
+	err = userPGRepository.DeleteById(context.Background(), mockUser.UserID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUserRepository_RestoreById(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at", "deleted_at"}
+	userUUID := uuid.New()
+	rows := sqlmock.NewRows(columns).AddRow(userUUID, "FirstName", "LastName", "email@gmail.com", "123456", nil, "user", time.Now(), time.Now(), nil)
+
+	mock.ExpectQuery(restoreByIdQuery).WithArgs(userUUID).WillReturnRows(rows)
+	restoredUser, err := userPGRepository.RestoreById(context.Background(), userUUID)
+	require.NoError(t, err)
+	require.Equal(t, userUUID, restoredUser.UserID)
+	require.Nil(t, restoredUser.DeletedAt)
+
+	mock.ExpectQuery(restoreByIdQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows(columns))
+	_, err = userPGRepository.RestoreById(context.Background(), userUUID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUserRepository_PurgeDeleted(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+	before := time.Now().AddDate(0, 0, -30)
+
+	mock.ExpectExec(purgeDeletedQuery).WithArgs(before, 100).WillReturnResult(sqlmock.NewResult(0, 3))
+	purged, err := userPGRepository.PurgeDeleted(context.Background(), before, 100, false)
+	require.NoError(t, err)
+	require.Equal(t, 3, purged)
+
+	mock.ExpectExec(anonymizeDeletedQuery).WithArgs(before, 100).WillReturnResult(sqlmock.NewResult(0, 2))
+	purged, err = userPGRepository.PurgeDeleted(context.Background(), before, 100, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, purged)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_UpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at", "status"}
+	userUUID := uuid.New()
+	change := &models.UserStatusChange{UserID: userUUID, Status: models.UserStatusDeactivated, Reason: "leaving"}
+
+	mock.ExpectQuery(updateStatusQuery).WithArgs(userUUID, models.UserStatusActive, models.UserStatusDeactivated, "leaving", change.ActorID).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(userUUID, "FirstName", "LastName", "email@gmail.com", "123456", nil, "user", time.Now(), time.Now(), models.UserStatusDeactivated),
+	)
+	updatedUser, err := userPGRepository.UpdateStatus(context.Background(), change, models.UserStatusActive)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusDeactivated, updatedUser.Status)
+
+	mock.ExpectQuery(updateStatusQuery).WithArgs(userUUID, models.UserStatusActive, models.UserStatusDeactivated, "leaving", change.ActorID).WillReturnRows(sqlmock.NewRows(columns))
+	_, err = userPGRepository.UpdateStatus(context.Background(), change, models.UserStatusActive)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUserRepository_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at", "email_verified_at", "status"}
+	userUUID := uuid.New()
+
+	mock.ExpectQuery(verifyEmailQuery).WithArgs(userUUID).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(userUUID, "FirstName", "LastName", "email@gmail.com", "123456", nil, "user", time.Now(), time.Now(), time.Now(), models.UserStatusPending),
+	)
+	verifiedUser, err := userPGRepository.VerifyEmail(context.Background(), userUUID)
+	require.NoError(t, err)
+	require.NotNil(t, verifiedUser.EmailVerifiedAt)
+
+	mock.ExpectQuery(verifyEmailQuery).WithArgs(userUUID).WillReturnRows(sqlmock.NewRows(columns))
+	_, err = userPGRepository.VerifyEmail(context.Background(), userUUID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUserRepository_AcceptInvitation(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at", "email_verified_at", "status"}
+	userUUID := uuid.New()
+
+	mock.ExpectQuery(acceptInvitationQuery).WithArgs(userUUID, "hash").WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(userUUID, "FirstName", "LastName", "email@gmail.com", "hash", nil, "user", time.Now(), time.Now(), time.Now(), models.UserStatusActive),
+	)
+	acceptedUser, err := userPGRepository.AcceptInvitation(context.Background(), userUUID, "hash")
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusActive, acceptedUser.Status)
+
+	mock.ExpectQuery(acceptInvitationQuery).WithArgs(userUUID, "hash").WillReturnRows(sqlmock.NewRows(columns))
+	_, err = userPGRepository.AcceptInvitation(context.Background(), userUUID, "hash")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUserRepository_DeleteInvited(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	userUUID := uuid.New()
+
+	mock.ExpectExec(deleteInvitedQuery).WithArgs(userUUID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, userPGRepository.DeleteInvited(context.Background(), userUUID))
+
+	mock.ExpectExec(deleteInvitedQuery).WithArgs(userUUID).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.ErrorIs(t, userPGRepository.DeleteInvited(context.Background(), userUUID), sql.ErrNoRows)
+}
+
+func TestUserRepository_VerifyPhoneNumber(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	userPGRepository := NewUserPGRepository(sqlxDB)
+
+	columns := []string{"user_id", "first_name", "last_name", "email", "password", "avatar", "role", "created_at", "updated_at", "status", "phone_number", "phone_verified_at"}
+	userUUID := uuid.New()
+	phone := "+6281234567890"
+
+	mock.ExpectQuery(setPhoneNumberQuery).WithArgs(userUUID, phone).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(userUUID, "FirstName", "LastName", "email@gmail.com", "123456", nil, "user", time.Now(), time.Now(), models.UserStatusActive, phone, nil),
+	)
+	updatedUser, err := userPGRepository.SetPhoneNumber(context.Background(), userUUID, phone)
+	require.NoError(t, err)
+	require.Equal(t, phone, *updatedUser.PhoneNumber)
+	require.False(t, updatedUser.HasVerifiedPhone())
+
+	mock.ExpectQuery(verifyPhoneNumberQuery).WithArgs(userUUID, phone).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(userUUID, "FirstName", "LastName", "email@gmail.com", "123456", nil, "user", time.Now(), time.Now(), models.UserStatusActive, phone, time.Now()),
+	)
+	verifiedUser, err := userPGRepository.VerifyPhoneNumber(context.Background(), userUUID, phone)
+	require.NoError(t, err)
+	require.True(t, verifiedUser.HasVerifiedPhone())
+
+	mock.ExpectQuery(verifyPhoneNumberQuery).WithArgs(userUUID, phone).WillReturnRows(sqlmock.NewRows(columns))
+	_, err = userPGRepository.VerifyPhoneNumber(context.Background(), userUUID, phone)
+	require.ErrorIs(t, err, sql.ErrNoRows)
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
 
 	"github.com/dinorain/useraja/internal/models"
 	"github.com/dinorain/useraja/internal/user"
@@ -56,6 +57,63 @@ func (r *userRedisRepo) DeleteUserCtx(ctx context.Context, key string) error {
 	return r.redisClient.Del(ctx, r.createKey(key)).Err()
 }
 
+// Store the user id a one time token of purpose resolves to, the previous token of the user for purpose is revoked
+func (r *userRedisRepo) SetTokenCtx(ctx context.Context, purpose string, tokenHash string, userID string, expire time.Duration) error {
+	userKey := r.createUserTokenKey(purpose, userID)
+	previousHash, err := r.redisClient.Get(ctx, userKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	_, err = r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previousHash != "" {
+			pipe.Del(ctx, r.createTokenKey(purpose, previousHash))
+		}
+		pipe.Set(ctx, r.createTokenKey(purpose, tokenHash), userID, expire)
+		pipe.Set(ctx, userKey, tokenHash, expire)
+		return nil
+	})
+	return err
+}
+
+// Revoke the outstanding token of purpose of the user
+func (r *userRedisRepo) DeleteTokenCtx(ctx context.Context, purpose string, userID string) error {
+	userKey := r.createUserTokenKey(purpose, userID)
+	tokenHash, err := r.redisClient.Get(ctx, userKey).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}
+
+	return r.redisClient.Del(ctx, r.createTokenKey(purpose, tokenHash), userKey).Err()
+}
+
+// Get the user id of a one time token and consume it, redis.Nil when it is unknown or expired
+func (r *userRedisRepo) GetDelTokenCtx(ctx context.Context, purpose string, tokenHash string) (string, error) {
+	key := r.createTokenKey(purpose, tokenHash)
+
+	var get *redis.StringCmd
+	if _, err := r.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	return get.Val(), nil
+}
+
+func (r *userRedisRepo) createTokenKey(purpose string, tokenHash string) string {
+	return fmt.Sprintf("%s: token:%s:%s", r.basePrefix, purpose, tokenHash)
+}
+
+func (r *userRedisRepo) createUserTokenKey(purpose string, userID string) string {
+	return fmt.Sprintf("%s: token:%s:user:%s", r.basePrefix, purpose, userID)
+}
+
 func (r *userRedisRepo) createKey(value string) string {
 	return fmt.Sprintf("%s: %s", r.basePrefix, value)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis"
 	"github.com/go-redis/redis/v8"
@@ -74,3 +75,47 @@ func TestUserRedisRepo_DeleteUserCtx(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestUserRedisRepo_GetDelTokenCtx(t *testing.T) {
+	t.Parallel()
+
+	redisRepo := SetupRedis()
+
+	t.Run("GetDelTokenCtx", func(t *testing.T) {
+		userID := uuid.New().String()
+
+		err := redisRepo.SetTokenCtx(context.Background(), "verify_email", "hash", userID, time.Minute)
+		require.NoError(t, err)
+
+		foundID, err := redisRepo.GetDelTokenCtx(context.Background(), "verify_email", "hash")
+		require.NoError(t, err)
+		require.Equal(t, userID, foundID)
+
+		_, err = redisRepo.GetDelTokenCtx(context.Background(), "verify_email", "hash")
+		require.ErrorIs(t, err, redis.Nil)
+	})
+}
+
+func TestUserRedisRepo_DeleteTokenCtx(t *testing.T) {
+	t.Parallel()
+
+	redisRepo := SetupRedis()
+
+	t.Run("DeleteTokenCtx", func(t *testing.T) {
+		ctx := context.Background()
+		userID := uuid.New().String()
+
+		require.NoError(t, redisRepo.SetTokenCtx(ctx, "invitation", "first", userID, time.Minute))
+		require.NoError(t, redisRepo.SetTokenCtx(ctx, "invitation", "second", userID, time.Minute))
+
+		// a new token revokes the previous one
+		_, err := redisRepo.GetDelTokenCtx(ctx, "invitation", "first")
+		require.ErrorIs(t, err, redis.Nil)
+
+		require.NoError(t, redisRepo.DeleteTokenCtx(ctx, "invitation", userID))
+		_, err = redisRepo.GetDelTokenCtx(ctx, "invitation", "second")
+		require.ErrorIs(t, err, redis.Nil)
+
+		require.NoError(t, redisRepo.DeleteTokenCtx(ctx, "invitation", userID))
+	})
+}
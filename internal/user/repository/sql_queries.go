@@ -1,18 +1,96 @@
 package repository
 
 const (
-	createUserQuery = `INSERT INTO users (first_name, last_name, email, password, role, avatar) 
-		VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), null)) 
-		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role`
+	createUserQuery = `INSERT INTO users (first_name, last_name, email, password, role, avatar, status) 
+		VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), null), COALESCE(NULLIF($7, ''), 'active')::user_status) 
+		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version`
 
-	findByEmailQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at FROM users WHERE email = $1`
+	findByEmailQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version FROM users WHERE email = $1 AND deleted_at IS NULL`
 
-	findByIdQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at FROM users WHERE user_id = $1`
+	findByIdQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version FROM users WHERE user_id = $1 AND deleted_at IS NULL`
 
-	findAllQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at FROM users LIMIT $1 OFFSET $2`
+	usersFilterCondition = `($1 = '' OR role::text = $1)
+		AND ($2 = '' OR (first_name || ' ' || last_name || ' ' || email) ILIKE $2)
+		AND ($3::timestamptz IS NULL OR created_at >= $3)
+		AND ($4::timestamptz IS NULL OR created_at < $4)
+		AND ($5::boolean IS NULL OR (email_verified_at IS NOT NULL) = $5)
+		AND ($6::boolean OR deleted_at IS NULL)`
 
-	updateByIdQuery = `UPDATE users SET first_name = $2, last_name = $3, email = $4, password = $5, role = $6, avatar = $7 WHERE user_id = $1
-		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role`
+	// findAllQuery order by clause is filled from a whitelisted sort field
+	findAllQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version FROM users
+		WHERE ` + usersFilterCondition + `
+		ORDER BY %s LIMIT $7 OFFSET $8`
 
-	deleteByIdQuery = `DELETE FROM users WHERE user_id = $1`
+	// findAllAfterCursorQuery and findAllBeforeCursorQuery walk users by (created_at, user_id) from an optional cursor
+	findAllAfterCursorQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version FROM users
+		WHERE ` + usersFilterCondition + `
+		AND ($7::timestamptz IS NULL OR (created_at, user_id) > ($7, $8::uuid))
+		ORDER BY created_at ASC, user_id ASC LIMIT $9`
+
+	findAllBeforeCursorQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version FROM users
+		WHERE ` + usersFilterCondition + `
+		AND ($7::timestamptz IS NULL OR (created_at, user_id) < ($7, $8::uuid))
+		ORDER BY created_at DESC, user_id DESC LIMIT $9`
+
+	countAllQuery = `SELECT COUNT(*) FROM users WHERE ` + usersFilterCondition
+
+	// updateByIdQuery writes only when the stored version still is $8, bumping it
+	updateByIdQuery = `UPDATE users SET first_name = $2, last_name = $3, email = $4, password = $5, role = $6, avatar = $7,
+		version = version + 1, updated_at = NOW()
+		WHERE user_id = $1 AND version = $8 AND deleted_at IS NULL
+		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version`
+
+	verifyEmailQuery = `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), version = version + 1, updated_at = NOW()
+		WHERE user_id = $1 AND deleted_at IS NULL
+		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version`
+
+	// acceptInvitationQuery sets the first password of an invited user, activating it and recording the change
+	acceptInvitationQuery = `WITH updated AS (
+			UPDATE users SET password = $2, status = 'active', email_verified_at = COALESCE(email_verified_at, NOW()), version = version + 1, updated_at = NOW()
+			WHERE user_id = $1 AND status = 'pending' AND password = '!' AND deleted_at IS NULL
+			RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version
+		), event AS (
+			INSERT INTO user_status_events (user_id, from_status, to_status, reason)
+			SELECT user_id, 'pending', 'active', 'invitation accepted' FROM updated
+		)
+		SELECT user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version FROM updated`
+
+	findByVerifiedPhoneQuery = `SELECT user_id, email, first_name, last_name, role, avatar, password, created_at, updated_at, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version FROM users
+		WHERE phone_number = $1 AND phone_verified_at IS NOT NULL AND deleted_at IS NULL`
+
+	// setPhoneNumberQuery keeps the verification only when the number does not change
+	setPhoneNumberQuery = `UPDATE users SET phone_number = $2,
+		phone_verified_at = CASE WHEN phone_number = $2 THEN phone_verified_at END, version = version + 1, updated_at = NOW()
+		WHERE user_id = $1 AND deleted_at IS NULL
+		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version`
+
+	// verifyPhoneNumberQuery verifies the number only while it still is $2
+	verifyPhoneNumberQuery = `UPDATE users SET phone_verified_at = COALESCE(phone_verified_at, NOW()), version = version + 1, updated_at = NOW()
+		WHERE user_id = $1 AND phone_number = $2 AND deleted_at IS NULL
+		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version`
+
+	deleteInvitedQuery = `DELETE FROM users WHERE user_id = $1 AND status = 'pending' AND password = '!'`
+
+	deleteByIdQuery = `UPDATE users SET deleted_at = NOW(), version = version + 1, updated_at = NOW() WHERE user_id = $1 AND deleted_at IS NULL`
+
+	restoreByIdQuery = `UPDATE users SET deleted_at = NULL, version = version + 1, updated_at = NOW() WHERE user_id = $1 AND deleted_at IS NOT NULL AND anonymized_at IS NULL
+		RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version`
+
+	// purgeDeletedQuery and anonymizeDeletedQuery process up to $2 users soft deleted before $1
+	purgeDeletedQuery = `DELETE FROM users WHERE user_id IN (
+		SELECT user_id FROM users WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2)`
+
+	anonymizeDeletedQuery = `UPDATE users SET first_name = 'Deleted', last_name = 'User', email = 'deleted-' || user_id || '@invalid',
+		password = '!', avatar = NULL, email_verified_at = NULL, phone_number = NULL, phone_verified_at = NULL, anonymized_at = NOW()
+		WHERE user_id IN (SELECT user_id FROM users WHERE deleted_at < $1 AND anonymized_at IS NULL ORDER BY deleted_at LIMIT $2)`
+
+	// updateStatusQuery changes the status only from the expected one and records the change in the same statement
+	updateStatusQuery = `WITH updated AS (
+			UPDATE users SET status = $3, version = version + 1, updated_at = NOW() WHERE user_id = $1 AND status = $2 AND deleted_at IS NULL
+			RETURNING user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version
+		), event AS (
+			INSERT INTO user_status_events (user_id, from_status, to_status, reason, actor_id)
+			SELECT user_id, $2, $3, $4, $5 FROM updated
+		)
+		SELECT user_id, first_name, last_name, email, password, avatar, created_at, updated_at, role, email_verified_at, phone_number, phone_verified_at, deleted_at, status, version FROM updated`
 )
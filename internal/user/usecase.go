@@ -3,6 +3,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -13,12 +14,44 @@ import (
 //  User UseCase interface
 type UserUseCase interface {
 	Register(ctx context.Context, user *models.User) (*models.User, error)
+	Signup(ctx context.Context, user *models.User, inviteCode string) (*models.User, error)
+	VerifyEmail(ctx context.Context, token string) (*models.User, error)
+	ResendEmailVerification(ctx context.Context, email string) error
+	Invite(ctx context.Context, user *models.User) (*models.User, error)
+	ResendInvitation(ctx context.Context, userID uuid.UUID) error
+	RevokeInvitation(ctx context.Context, userID uuid.UUID) error
+	AcceptInvitation(ctx context.Context, token string, password string) (*models.User, error)
+	RequestMagicLink(ctx context.Context, email string, nonce string) error
+	LoginWithMagicLink(ctx context.Context, token string, nonce string) (*models.User, error)
+	RequestLoginCode(ctx context.Context, email string) error
+	LoginWithCode(ctx context.Context, email string, code string) (*models.User, error)
+	RequestPhoneVerification(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error)
+	VerifyPhoneNumber(ctx context.Context, userID uuid.UUID, code string) (*models.User, error)
+	RequestSMSLoginCode(ctx context.Context, phoneNumber string) error
+	LoginWithSMSCode(ctx context.Context, phoneNumber string, code string) (*models.User, error)
+	RequestStepUpCode(ctx context.Context, userID uuid.UUID) error
+	StepUp(ctx context.Context, session *models.Session, code string) (*models.Session, error)
+	Reauthenticate(ctx context.Context, session *models.Session, password string) (*models.Session, error)
+	TrustDevice(ctx context.Context, userID uuid.UUID, name string, ip string) (string, *models.TrustedDevice, error)
+	TouchTrustedDevice(ctx context.Context, userID uuid.UUID, signedToken string, ip string) (*models.TrustedDevice, error)
+	FindTrustedDevices(ctx context.Context, userID uuid.UUID) ([]models.TrustedDevice, error)
+	RevokeTrustedDevice(ctx context.Context, userID uuid.UUID, deviceID uuid.UUID) error
+	RevokeTrustedDevices(ctx context.Context, userID uuid.UUID) error
+	CreateAccessToken(ctx context.Context, userID uuid.UUID, name string, scopes []string, expire time.Duration) (string, *models.PersonalAccessToken, error)
+	FindAccessTokens(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error
+	AuthenticateAccessToken(ctx context.Context, token string) (*models.PersonalAccessToken, *models.User, error)
 	Login(ctx context.Context, email string, password string) (*models.User, error)
-	FindAll(ctx context.Context, pagination *utils.Pagination) ([]models.User, error)
+	FindAll(ctx context.Context, filter *models.UserFilter, pagination *utils.Pagination) (*models.UsersList, error)
+	StreamAll(ctx context.Context, filter *models.UserFilter, batchSize int, send func(users []models.User) error) error
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	FindById(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	CachedFindById(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	UpdateById(ctx context.Context, user *models.User) (*models.User, error)
 	DeleteById(ctx context.Context, userID uuid.UUID) error
+	RestoreById(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	PurgeDeleted(ctx context.Context) (int, error)
+	ChangeStatus(ctx context.Context, change *models.UserStatusChange) (*models.User, error)
 	GenerateTokenPair(user *models.User, sessionID string) (access string, refresh string, err error)
+	GenerateClientTokenPair(user *models.User, session *models.Session) (access string, refresh string, err error)
 }
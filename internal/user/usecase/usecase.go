@@ -2,65 +2,1178 @@ package usecase
 
 import (
 	"context"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
 	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/internal/accesstoken"
+	"github.com/dinorain/useraja/internal/device"
 	"github.com/dinorain/useraja/internal/models"
+	"github.com/dinorain/useraja/internal/otp"
+	"github.com/dinorain/useraja/internal/ratelimit"
+	"github.com/dinorain/useraja/internal/session"
 	"github.com/dinorain/useraja/internal/user"
-	"github.com/dinorain/useraja/pkg/grpc_errors"
+	"github.com/dinorain/useraja/pkg/constants"
+	"github.com/dinorain/useraja/pkg/domain_errors"
 	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/mailer"
+	"github.com/dinorain/useraja/pkg/sms"
 	"github.com/dinorain/useraja/pkg/utils"
 )
 
 const (
 	userByIdCacheDuration = 3600
+
+	defaultStreamBatchSize = 100
+	maxStreamBatchSize     = 1000
+
+	userCursorSortField = "created_at"
+
+	uniqueViolation = "23505"
+
+	tokenPurposeVerifyEmail = "verify_email"
+	tokenPurposeInvitation  = "invitation"
+	tokenPurposeMagicLink   = "magic_link"
 )
 
+// userPageToken payload of user listing cursor tokens
+type userPageToken struct {
+	CreatedAt time.Time `json:"c"`
+	UserID    uuid.UUID `json:"u"`
+	Backward  bool      `json:"b,omitempty"`
+	Desc      bool      `json:"d,omitempty"`
+}
+
 // User UseCase
 type userUseCase struct {
 	cfg        *config.Config
 	logger     logger.Logger
 	userPgRepo user.UserPGRepository
 	redisRepo  user.UserRedisRepository
+	sessRepo   session.SessRepository
+	mailer     mailer.Mailer
+	otpRepo    otp.OTPRepository
+	smsSender  sms.SMSSender
+	// rateLimitRepo limits text messages per phone number, nil disables the limit
+	rateLimitRepo ratelimit.RateLimitRepository
+	deviceRepo    device.DevicePGRepository
+	tokenRepo     accesstoken.AccessTokenPGRepository
 }
 
 var _ user.UserUseCase = (*userUseCase)(nil)
 
 // New User UseCase
-func NewUserUseCase(cfg *config.Config, logger logger.Logger, userRepo user.UserPGRepository, redisRepo user.UserRedisRepository) *userUseCase {
-	return &userUseCase{cfg: cfg, logger: logger, userPgRepo: userRepo, redisRepo: redisRepo}
+func NewUserUseCase(
+	cfg *config.Config,
+	logger logger.Logger,
+	userRepo user.UserPGRepository,
+	redisRepo user.UserRedisRepository,
+	sessRepo session.SessRepository,
+	mailer mailer.Mailer,
+	otpRepo otp.OTPRepository,
+	smsSender sms.SMSSender,
+	rateLimitRepo ratelimit.RateLimitRepository,
+	deviceRepo device.DevicePGRepository,
+	tokenRepo accesstoken.AccessTokenPGRepository,
+) *userUseCase {
+	return &userUseCase{
+		cfg:           cfg,
+		logger:        logger,
+		userPgRepo:    userRepo,
+		redisRepo:     redisRepo,
+		sessRepo:      sessRepo,
+		mailer:        mailer,
+		otpRepo:       otpRepo,
+		smsSender:     smsSender,
+		rateLimitRepo: rateLimitRepo,
+		deviceRepo:    deviceRepo,
+		tokenRepo:     tokenRepo,
+	}
 }
 
 // Register new user
 func (u *userUseCase) Register(ctx context.Context, user *models.User) (*models.User, error) {
 	existsUser, err := u.userPgRepo.FindByEmail(ctx, user.Email)
 	if existsUser != nil || err == nil {
-		return nil, grpc_errors.ErrEmailExists
+		return nil, domain_errors.ErrEmailExists
+	}
+
+	// soft deleted users keep their email until they are purged
+	createdUser, err := u.userPgRepo.Create(ctx, user)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return nil, domain_errors.ErrEmailExists.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "userPgRepo.Create")
+	}
+
+	return createdUser, nil
+}
+
+// Signup self-service registration allowed by the signup policy, the role is always the default one.
+// The user stays pending until the emailed verification link is followed when verification is required
+func (u *userUseCase) Signup(ctx context.Context, user *models.User, inviteCode string) (*models.User, error) {
+	if err := u.checkSignupPolicy(user.Email, inviteCode); err != nil {
+		return nil, err
+	}
+
+	user.Role = models.UserRoleUser
+	user.Status = models.UserStatusActive
+	if u.cfg.Signup.RequireEmailVerification {
+		user.Status = models.UserStatusPending
+	}
+
+	createdUser, err := u.Register(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// the user can ask for another link, so a failed email does not fail the signup
+	if err := u.sendEmailVerification(ctx, createdUser); err != nil {
+		u.logger.Errorf("sendEmailVerification: %v", err)
+	}
+
+	createdUser.SanitizePassword()
+
+	return createdUser, nil
+}
+
+// checkSignupPolicy reject emails and invite codes the configured signup mode does not accept
+func (u *userUseCase) checkSignupPolicy(email string, inviteCode string) error {
+	mode := u.cfg.Signup.Mode
+	if mode != config.SignupModeOpen && mode != config.SignupModeAllowlist && mode != config.SignupModeInvite {
+		return domain_errors.ErrSignupClosed
+	}
+
+	domain := email[strings.LastIndex(email, "@")+1:]
+	if containsFold(u.cfg.Signup.DeniedDomains, domain) {
+		return domain_errors.ErrEmailDomainNotAllowed
+	}
+
+	switch mode {
+	case config.SignupModeAllowlist:
+		if !containsFold(u.cfg.Signup.AllowedDomains, domain) {
+			return domain_errors.ErrEmailDomainNotAllowed
+		}
+	case config.SignupModeInvite:
+		for _, code := range u.cfg.Signup.InviteCodes {
+			if code != "" && subtle.ConstantTimeCompare([]byte(code), []byte(inviteCode)) == 1 {
+				return nil
+			}
+		}
+		return domain_errors.ErrInvalidInviteCode
+	}
+
+	return nil
+}
+
+// VerifyEmail consume an emailed verification token, a pending user is activated
+func (u *userUseCase) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	userID, err := u.redisRepo.GetDelTokenCtx(ctx, tokenPurposeVerifyEmail, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain_errors.ErrInvalidVerification.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "redisRepo.GetDelTokenCtx")
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain_errors.ErrInvalidVerification.Wrap(err)
+	}
+
+	verifiedUser, err := u.userPgRepo.VerifyEmail(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidVerification.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "userPgRepo.VerifyEmail")
+	}
+
+	// a user suspended or activated meanwhile keeps its status, invited users are activated by accepting
+	if verifiedUser.Status == models.UserStatusPending && verifiedUser.HasPassword() {
+		activatedUser, err := u.userPgRepo.UpdateStatus(ctx, &models.UserStatusChange{
+			UserID: userUUID,
+			Status: models.UserStatusActive,
+			Reason: "email verified",
+		}, models.UserStatusPending)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(err, "userPgRepo.UpdateStatus")
+		}
+		if err == nil {
+			verifiedUser = activatedUser
+		}
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, userUUID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx", err)
 	}
 
-	return u.userPgRepo.Create(ctx, user)
+	verifiedUser.SanitizePassword()
+
+	return verifiedUser, nil
 }
 
-// FindAll find users
-func (u *userUseCase) FindAll(ctx context.Context, pagination *utils.Pagination) ([]models.User, error) {
-	users, err := u.userPgRepo.FindAll(ctx, pagination)
+// ResendEmailVerification email a new verification link to an unverified user, invited users verify by accepting.
+// Unknown and already verified emails are ignored so the response does not tell whether an account exists
+func (u *userUseCase) ResendEmailVerification(ctx context.Context, email string) error {
+	foundUser, err := u.userPgRepo.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
 	if err != nil {
-		return nil, errors.Wrap(err, "userPgRepo.FindAll")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrap(err, "userPgRepo.FindByEmail")
+	}
+
+	if foundUser.EmailVerifiedAt != nil || !foundUser.HasPassword() {
+		return nil
+	}
+
+	if err := u.sendEmailVerification(ctx, foundUser); err != nil {
+		u.logger.Errorf("sendEmailVerification: %v", err)
 	}
 
-	return users, nil
+	return nil
+}
+
+// Invite create a pending user without a password and email it a link to choose one
+func (u *userUseCase) Invite(ctx context.Context, user *models.User) (*models.User, error) {
+	user.Password = models.UserPasswordUnset
+	user.Status = models.UserStatusPending
+
+	createdUser, err := u.Register(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// the invitation can be resent, so a failed email does not fail the invite
+	if err := u.sendInvitation(ctx, createdUser); err != nil {
+		u.logger.Errorf("sendInvitation: %v", err)
+	}
+
+	createdUser.SanitizePassword()
+
+	return createdUser, nil
+}
+
+// ResendInvitation email a new invitation link to an invited user, the previous link stops working
+func (u *userUseCase) ResendInvitation(ctx context.Context, userID uuid.UUID) error {
+	invitedUser, err := u.userPgRepo.FindById(ctx, userID)
+	if err != nil {
+		return errors.Wrap(notFound(err), "userPgRepo.FindById")
+	}
+
+	if invitedUser.Status != models.UserStatusPending || invitedUser.HasPassword() {
+		return domain_errors.ErrInvitationNotFound
+	}
+
+	if err := u.sendInvitation(ctx, invitedUser); err != nil {
+		return errors.Wrap(err, "sendInvitation")
+	}
+
+	return nil
+}
+
+// RevokeInvitation delete an invited user who did not accept yet, its email can be invited again
+func (u *userUseCase) RevokeInvitation(ctx context.Context, userID uuid.UUID) error {
+	if err := u.userPgRepo.DeleteInvited(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain_errors.ErrInvitationNotFound.Wrap(err)
+		}
+		return errors.Wrap(err, "userPgRepo.DeleteInvited")
+	}
+
+	// a leftover link can not be accepted once the user is gone, so a failed revocation is only logged
+	if err := u.redisRepo.DeleteTokenCtx(ctx, tokenPurposeInvitation, userID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteTokenCtx", err)
+	}
+
+	return nil
+}
+
+// AcceptInvitation consume an invitation token and set the first password of the invited user, activating it
+func (u *userUseCase) AcceptInvitation(ctx context.Context, token string, password string) (*models.User, error) {
+	// hashed before the token is consumed, so a failure does not burn the link
+	candidate := &models.User{Password: strings.TrimSpace(password)}
+	if err := candidate.HashPassword(); err != nil {
+		return nil, errors.Wrap(err, "user.HashPassword")
+	}
+
+	userID, err := u.redisRepo.GetDelTokenCtx(ctx, tokenPurposeInvitation, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain_errors.ErrInvalidInvitation.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "redisRepo.GetDelTokenCtx")
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain_errors.ErrInvalidInvitation.Wrap(err)
+	}
+
+	acceptedUser, err := u.userPgRepo.AcceptInvitation(ctx, userUUID, candidate.Password)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidInvitation.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "userPgRepo.AcceptInvitation")
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, userUUID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx", err)
+	}
+
+	acceptedUser.SanitizePassword()
+
+	return acceptedUser, nil
+}
+
+// sendInvitation email an invited user a one time link to choose its password
+func (u *userUseCase) sendInvitation(ctx context.Context, user *models.User) error {
+	expire := time.Duration(u.cfg.Invitation.Expire) * time.Second
+	link, err := u.issueTokenLink(ctx, tokenPurposeInvitation, user, expire, "/accept-invitation", "")
+	if err != nil {
+		return err
+	}
+
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "You have been invited",
+		Body:    fmt.Sprintf("Hi %s,\n\nAn account was created for you, choose your password by opening this link:\n%s\n\nThe link expires in %v.\n", user.FirstName, link, expire),
+	}); err != nil {
+		return errors.Wrap(err, "mailer.Send")
+	}
+
+	return nil
+}
+
+// RequestMagicLink email an active user a one time login link usable only together with nonce.
+// Unknown and inactive accounts are ignored so the response does not tell whether an account exists
+func (u *userUseCase) RequestMagicLink(ctx context.Context, email string, nonce string) error {
+	foundUser, err := u.userPgRepo.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrap(err, "userPgRepo.FindByEmail")
+	}
+
+	if !foundUser.IsActive() {
+		return nil
+	}
+
+	expire := time.Duration(u.cfg.MagicLink.Expire) * time.Second
+	link, err := u.issueTokenLink(ctx, tokenPurposeMagicLink, foundUser, expire, "/magic-link", nonce)
+	if err != nil {
+		u.logger.Errorf("issueTokenLink: %v", err)
+		return nil
+	}
+
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      foundUser.Email,
+		Subject: "Your login link",
+		Body:    fmt.Sprintf("Hi %s,\n\nLog in by opening this link in the browser you requested it from:\n%s\n\nThe link expires in %v, ignore this email if you did not ask for it.\n", foundUser.FirstName, link, expire),
+	}); err != nil {
+		u.logger.Errorf("mailer.Send: %v", err)
+	}
+
+	return nil
+}
+
+// LoginWithMagicLink consume a magic link token presented together with the nonce it was requested with
+func (u *userUseCase) LoginWithMagicLink(ctx context.Context, token string, nonce string) (*models.User, error) {
+	if nonce == "" {
+		return nil, domain_errors.ErrInvalidMagicLink
+	}
+
+	userID, err := u.redisRepo.GetDelTokenCtx(ctx, tokenPurposeMagicLink, tokenHash(token, nonce))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain_errors.ErrInvalidMagicLink.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "redisRepo.GetDelTokenCtx")
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain_errors.ErrInvalidMagicLink.Wrap(err)
+	}
+
+	foundUser, err := u.userPgRepo.FindById(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidMagicLink.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "userPgRepo.FindById")
+	}
+
+	if !foundUser.IsActive() {
+		return nil, domain_errors.ErrUserInactive
+	}
+
+	foundUser.SanitizePassword()
+
+	return foundUser, nil
+}
+
+// RequestLoginCode email an active user a one time code to log in with.
+// Unknown and inactive accounts are ignored so the response does not tell whether an account exists
+func (u *userUseCase) RequestLoginCode(ctx context.Context, email string) error {
+	foundUser, err := u.userPgRepo.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrap(err, "userPgRepo.FindByEmail")
+	}
+
+	if !foundUser.IsActive() {
+		return nil
+	}
+
+	if err := u.sendCode(ctx, models.OTPPurposeLogin, foundUser); err != nil {
+		u.logger.Errorf("sendCode: %v", err)
+	}
+
+	return nil
+}
+
+// LoginWithCode log in with an emailed one time login code
+func (u *userUseCase) LoginWithCode(ctx context.Context, email string, code string) (*models.User, error) {
+	foundUser, err := u.userPgRepo.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidCode.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "userPgRepo.FindByEmail")
+	}
+
+	if err := u.verifyCode(ctx, otpKey(models.OTPPurposeLogin, foundUser.UserID), code); err != nil {
+		return nil, err
+	}
+
+	if !foundUser.IsActive() {
+		return nil, domain_errors.ErrUserInactive
+	}
+
+	foundUser.SanitizePassword()
+
+	return foundUser, nil
+}
+
+// RequestPhoneVerification set the phone number of the user and text it a verification code,
+// a number the user already verified stays verified and is not texted again
+func (u *userUseCase) RequestPhoneVerification(ctx context.Context, userID uuid.UUID, phoneNumber string) (*models.User, error) {
+	foundUser, err := u.userPgRepo.FindById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(notFound(err), "userPgRepo.FindById")
+	}
+
+	if foundUser.HasVerifiedPhone() && *foundUser.PhoneNumber == phoneNumber {
+		foundUser.SanitizePassword()
+		return foundUser, nil
+	}
+
+	if err := u.allowSMS(ctx, phoneNumber); err != nil {
+		return nil, err
+	}
+
+	updatedUser, err := u.userPgRepo.SetPhoneNumber(ctx, userID, phoneNumber)
+	if err != nil {
+		return nil, errors.Wrap(notFound(err), "userPgRepo.SetPhoneNumber")
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, userID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx: %v", err)
+	}
+
+	if err := u.sendSMSCode(ctx, models.OTPPurposePhoneVerify, updatedUser, phoneNumber); err != nil {
+		return nil, errors.Wrap(err, "sendSMSCode")
+	}
+
+	updatedUser.SanitizePassword()
+
+	return updatedUser, nil
+}
+
+// VerifyPhoneNumber verify the phone number of the user with the code texted to it
+func (u *userUseCase) VerifyPhoneNumber(ctx context.Context, userID uuid.UUID, code string) (*models.User, error) {
+	foundUser, err := u.userPgRepo.FindById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(notFound(err), "userPgRepo.FindById")
+	}
+
+	if foundUser.PhoneNumber == nil {
+		return nil, domain_errors.ErrInvalidCode
+	}
+	phoneNumber := *foundUser.PhoneNumber
+
+	if err := u.verifyCode(ctx, smsOTPKey(models.OTPPurposePhoneVerify, userID, phoneNumber), code); err != nil {
+		return nil, err
+	}
+
+	verifiedUser, err := u.userPgRepo.VerifyPhoneNumber(ctx, userID, phoneNumber)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return nil, domain_errors.ErrPhoneNumberExists.Wrap(err)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidCode.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "userPgRepo.VerifyPhoneNumber")
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, userID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx: %v", err)
+	}
+
+	verifiedUser.SanitizePassword()
+
+	return verifiedUser, nil
+}
+
+// RequestSMSLoginCode text a one time code to log in with to the verified phone number of an active user.
+// Unknown numbers and inactive accounts are ignored so the response does not tell whether an account exists
+func (u *userUseCase) RequestSMSLoginCode(ctx context.Context, phoneNumber string) error {
+	if err := u.allowSMS(ctx, phoneNumber); err != nil {
+		return err
+	}
+
+	foundUser, err := u.userPgRepo.FindByVerifiedPhone(ctx, phoneNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrap(err, "userPgRepo.FindByVerifiedPhone")
+	}
+
+	if !foundUser.IsActive() {
+		return nil
+	}
+
+	if err := u.sendSMSCode(ctx, models.OTPPurposeSMSLogin, foundUser, phoneNumber); err != nil {
+		u.logger.Errorf("sendSMSCode: %v", err)
+	}
+
+	return nil
+}
+
+// LoginWithSMSCode log in with a one time login code texted to a verified phone number
+func (u *userUseCase) LoginWithSMSCode(ctx context.Context, phoneNumber string, code string) (*models.User, error) {
+	foundUser, err := u.userPgRepo.FindByVerifiedPhone(ctx, phoneNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidCode.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "userPgRepo.FindByVerifiedPhone")
+	}
+
+	if err := u.verifyCode(ctx, smsOTPKey(models.OTPPurposeSMSLogin, foundUser.UserID, phoneNumber), code); err != nil {
+		return nil, err
+	}
+
+	if !foundUser.IsActive() {
+		return nil, domain_errors.ErrUserInactive
+	}
+
+	foundUser.SanitizePassword()
+
+	return foundUser, nil
+}
+
+// RequestStepUpCode email the user a one time code confirming a sensitive operation
+func (u *userUseCase) RequestStepUpCode(ctx context.Context, userID uuid.UUID) error {
+	foundUser, err := u.userPgRepo.FindById(ctx, userID)
+	if err != nil {
+		return errors.Wrap(notFound(err), "userPgRepo.FindById")
+	}
+
+	if err := u.sendCode(ctx, models.OTPPurposeStepUp, foundUser); err != nil {
+		return errors.Wrap(err, "sendCode")
+	}
+
+	return nil
+}
+
+// StepUp verify an emailed step-up code of the session user and mark the session as stepped up now
+func (u *userUseCase) StepUp(ctx context.Context, session *models.Session, code string) (*models.Session, error) {
+	if err := u.verifyCode(ctx, otpKey(models.OTPPurposeStepUp, session.UserID), code); err != nil {
+		return nil, err
+	}
+
+	session.AuthTime = time.Now()
+	session.ACR = models.SessionACRStepUp
+	if !session.HasMethod(models.AuthMethodOTP) {
+		session.AMR = append(session.AMR, models.AuthMethodOTP)
+	}
+	if err := u.updateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Reauthenticate check the password of the session user again and record it as the authentication of the session,
+// a previous step-up is dropped
+func (u *userUseCase) Reauthenticate(ctx context.Context, session *models.Session, password string) (*models.Session, error) {
+	foundUser, err := u.userPgRepo.FindById(ctx, session.UserID)
+	if err != nil {
+		return nil, errors.Wrap(notFound(err), "userPgRepo.FindById")
+	}
+
+	if err := foundUser.ComparePasswords(password); err != nil {
+		return nil, domain_errors.ErrInvalidCredentials.Wrap(errors.Wrap(err, "user.ComparePasswords"))
+	}
+
+	if !foundUser.IsActive() {
+		return nil, domain_errors.ErrUserInactive
+	}
+
+	session.AuthTime = time.Now()
+	session.ACR = models.SessionACRBasic
+	session.AMR = []string{models.AuthMethodPassword}
+	if err := u.updateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// TrustDevice remember a device of the user after a second factor, returning the signed token its cookie keeps
+func (u *userUseCase) TrustDevice(ctx context.Context, userID uuid.UUID, name string, ip string) (string, *models.TrustedDevice, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "utils.GenerateToken")
+	}
+
+	if runes := []rune(strings.TrimSpace(name)); len(runes) > models.TrustedDeviceNameMaxLength {
+		name = string(runes[:models.TrustedDeviceNameMaxLength])
+	} else {
+		name = string(runes)
+	}
+
+	createdDevice, err := u.deviceRepo.Create(ctx, &models.TrustedDevice{
+		UserID:    userID,
+		TokenHash: utils.HashToken(token),
+		Name:      name,
+		IP:        ip,
+		ExpiresAt: time.Now().Add(time.Duration(u.cfg.TrustedDevice.Expire) * time.Second),
+	})
+	if err != nil {
+		return "", nil, errors.Wrap(err, "deviceRepo.Create")
+	}
+
+	return utils.SignToken(u.cfg.Server.JwtSecretKey, token), createdDevice, nil
+}
+
+// TouchTrustedDevice find the device of the user the signed token was issued to and record it was seen now from ip,
+// domain_errors.ErrTrustedDeviceNotFound when the token is not trusted for the user
+func (u *userUseCase) TouchTrustedDevice(ctx context.Context, userID uuid.UUID, signedToken string, ip string) (*models.TrustedDevice, error) {
+	token, ok := utils.VerifySignedToken(u.cfg.Server.JwtSecretKey, signedToken)
+	if !ok {
+		return nil, domain_errors.ErrTrustedDeviceNotFound
+	}
+
+	touchedDevice, err := u.deviceRepo.Touch(ctx, userID, utils.HashToken(token), ip)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrTrustedDeviceNotFound.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "deviceRepo.Touch")
+	}
+
+	return touchedDevice, nil
+}
+
+// FindTrustedDevices unexpired trusted devices of the user
+func (u *userUseCase) FindTrustedDevices(ctx context.Context, userID uuid.UUID) ([]models.TrustedDevice, error) {
+	devices, err := u.deviceRepo.FindAllByUserId(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "deviceRepo.FindAllByUserId")
+	}
+
+	return devices, nil
+}
+
+// RevokeTrustedDevice forget a trusted device of the user, its next login asks for the second factor again
+func (u *userUseCase) RevokeTrustedDevice(ctx context.Context, userID uuid.UUID, deviceID uuid.UUID) error {
+	if err := u.deviceRepo.DeleteById(ctx, userID, deviceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain_errors.ErrTrustedDeviceNotFound.Wrap(err)
+		}
+		return errors.Wrap(err, "deviceRepo.DeleteById")
+	}
+
+	return nil
+}
+
+// RevokeTrustedDevices forget every trusted device of the user
+func (u *userUseCase) RevokeTrustedDevices(ctx context.Context, userID uuid.UUID) error {
+	if _, err := u.deviceRepo.DeleteAllByUserId(ctx, userID); err != nil {
+		return errors.Wrap(err, "deviceRepo.DeleteAllByUserId")
+	}
+
+	return nil
+}
+
+// CreateAccessToken issue the user a personal access token with scopes, expiring after expire or the configured default
+// when 0. The plaintext token is only ever returned here
+func (u *userUseCase) CreateAccessToken(ctx context.Context, userID uuid.UUID, name string, scopes []string, expire time.Duration) (string, *models.PersonalAccessToken, error) {
+	foundUser, err := u.userPgRepo.FindById(ctx, userID)
+	if err != nil {
+		return "", nil, errors.Wrap(notFound(err), "userPgRepo.FindById")
+	}
+
+	for _, scope := range scopes {
+		if !models.RoleAllowsScope(foundUser.Role, scope) {
+			return "", nil, domain_errors.ErrScopeNotAllowed.Wrap(errors.Errorf("%s for role %s", scope, foundUser.Role))
+		}
+	}
+
+	maxExpire := time.Duration(u.cfg.PersonalAccessToken.MaxExpire) * time.Second
+	if expire == 0 {
+		expire = time.Duration(u.cfg.PersonalAccessToken.Expire) * time.Second
+	}
+	if expire > maxExpire {
+		return "", nil, domain_errors.InvalidField("expires_in", errors.Errorf("expires_in is longer than %d seconds", u.cfg.PersonalAccessToken.MaxExpire))
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "utils.GenerateToken")
+	}
+	token = models.PersonalAccessTokenPrefix + token
+
+	if runes := []rune(strings.TrimSpace(name)); len(runes) > models.PersonalAccessTokenNameMaxLength {
+		name = string(runes[:models.PersonalAccessTokenNameMaxLength])
+	} else {
+		name = string(runes)
+	}
+
+	createdToken, err := u.tokenRepo.Create(ctx, &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:models.APIKeyDisplayLength],
+		TokenHash: utils.HashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(expire),
+	})
+	if err != nil {
+		return "", nil, errors.Wrap(err, "tokenRepo.Create")
+	}
+
+	return token, createdToken, nil
+}
+
+// FindAccessTokens find every personal access token of the user, including expired ones
+func (u *userUseCase) FindAccessTokens(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	tokens, err := u.tokenRepo.FindAllByUserId(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "tokenRepo.FindAllByUserId")
+	}
+
+	return tokens, nil
+}
+
+// RevokeAccessToken delete a personal access token of the user, it is rejected immediately
+func (u *userUseCase) RevokeAccessToken(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error {
+	if err := u.tokenRepo.DeleteById(ctx, userID, tokenID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain_errors.ErrAccessTokenNotFound.Wrap(err)
+		}
+		return errors.Wrap(err, "tokenRepo.DeleteById")
+	}
+
+	return nil
+}
+
+// AuthenticateAccessToken find the unexpired token and its active user and record the token was used now.
+// Scopes the user lost since the token was created are dropped from the returned token
+func (u *userUseCase) AuthenticateAccessToken(ctx context.Context, token string) (*models.PersonalAccessToken, *models.User, error) {
+	if !strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+		return nil, nil, domain_errors.ErrInvalidAccessToken
+	}
+
+	foundToken, err := u.tokenRepo.Authenticate(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, domain_errors.ErrInvalidAccessToken.Wrap(err)
+		}
+		return nil, nil, errors.Wrap(err, "tokenRepo.Authenticate")
+	}
+
+	foundUser, err := u.CachedFindById(ctx, foundToken.UserID)
+	if err != nil {
+		if errors.Is(err, domain_errors.ErrUserNotFound) {
+			return nil, nil, domain_errors.ErrInvalidAccessToken.Wrap(err)
+		}
+		return nil, nil, errors.Wrap(err, "CachedFindById")
+	}
+	if !foundUser.IsActive() {
+		return nil, nil, domain_errors.ErrUserInactive
+	}
+
+	scopes := foundToken.Scopes[:0]
+	for _, scope := range foundToken.Scopes {
+		if models.RoleAllowsScope(foundUser.Role, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	foundToken.Scopes = scopes
+
+	return foundToken, foundUser, nil
+}
+
+// revokeAccessTokens delete every personal access token of a user who lost access, a failure is only logged
+func (u *userUseCase) revokeAccessTokens(ctx context.Context, userID uuid.UUID) {
+	if _, err := u.tokenRepo.DeleteAllByUserId(ctx, userID); err != nil {
+		u.logger.Errorf("tokenRepo.DeleteAllByUserId: %v", err)
+	}
+}
+
+func (u *userUseCase) updateSession(ctx context.Context, session *models.Session) error {
+	if err := u.sessRepo.UpdateSession(ctx, session); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain_errors.ErrSessionNotFound.Wrap(err)
+		}
+		return errors.Wrap(err, "sessRepo.UpdateSession")
+	}
+	return nil
+}
+
+// sendCode email user a new one time code of purpose, replacing the previous one
+func (u *userUseCase) sendCode(ctx context.Context, purpose string, user *models.User) error {
+	code, expire, err := u.createCode(ctx, otpKey(purpose, user.UserID))
+	if err != nil {
+		return err
+	}
+
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Your verification code",
+		Body:    fmt.Sprintf("Hi %s,\n\nYour verification code is %s, it expires in %v.\nIgnore this email if you did not ask for it.\n", user.FirstName, code, expire),
+	}); err != nil {
+		return errors.Wrap(err, "mailer.Send")
+	}
+
+	return nil
+}
+
+// sendSMSCode text phoneNumber of user a new one time code of purpose, replacing the previous one
+func (u *userUseCase) sendSMSCode(ctx context.Context, purpose string, user *models.User, phoneNumber string) error {
+	code, expire, err := u.createCode(ctx, smsOTPKey(purpose, user.UserID, phoneNumber))
+	if err != nil {
+		return err
+	}
+
+	if err := u.smsSender.Send(ctx, &sms.Message{
+		To:   phoneNumber,
+		Body: fmt.Sprintf("Your verification code is %s, it expires in %v.", code, expire),
+	}); err != nil {
+		return errors.Wrap(err, "smsSender.Send")
+	}
+
+	return nil
+}
+
+// allowSMS count a text message to phoneNumber against its rate limit, domain_errors.RateLimited once it is exceeded.
+// The limit is not enforced when it cannot be checked
+func (u *userUseCase) allowSMS(ctx context.Context, phoneNumber string) error {
+	if u.rateLimitRepo == nil || u.cfg.SMS.RateLimit <= 0 {
+		return nil
+	}
+
+	allowed, retryAfter, err := u.rateLimitRepo.Allow(ctx, "sms:"+phoneNumber, u.cfg.SMS.RateLimit, time.Duration(u.cfg.SMS.RateLimitWindow)*time.Second)
+	if err != nil {
+		u.logger.Errorf("rateLimitRepo.Allow: %v", err)
+		return nil
+	}
+	if !allowed {
+		return domain_errors.RateLimited(retryAfter)
+	}
+	return nil
+}
+
+// createCode store a new one time code under key, replacing the previous one
+func (u *userUseCase) createCode(ctx context.Context, key string) (string, time.Duration, error) {
+	code, err := utils.GenerateCode(models.OTPDigits)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "utils.GenerateCode")
+	}
+
+	expire := time.Duration(u.cfg.OTP.Expire) * time.Second
+	if err := u.otpRepo.Create(ctx, key, utils.HashCode(u.cfg.Server.JwtSecretKey, key+":"+code), expire); err != nil {
+		return "", 0, errors.Wrap(err, "otpRepo.Create")
+	}
+
+	return code, expire, nil
+}
+
+// verifyCode consume the one time code stored under key, domain_errors.ErrInvalidCode when it does not match
+func (u *userUseCase) verifyCode(ctx context.Context, key string, code string) error {
+	matched, err := u.otpRepo.Verify(ctx, key, utils.HashCode(u.cfg.Server.JwtSecretKey, key+":"+code), u.cfg.OTP.MaxAttempts)
+	if err != nil {
+		return errors.Wrap(err, "otpRepo.Verify")
+	}
+	if !matched {
+		return domain_errors.ErrInvalidCode
+	}
+	return nil
+}
+
+func otpKey(purpose string, userID uuid.UUID) string {
+	return purpose + ":" + userID.String()
+}
+
+// smsOTPKey codes texted to a phone number only verify for that number
+func smsOTPKey(purpose string, userID uuid.UUID, phoneNumber string) string {
+	return otpKey(purpose, userID) + ":" + phoneNumber
+}
+
+// issueTokenLink store a new one time token of purpose for user and return the frontend link at path carrying it,
+// a token issued with a nonce is only accepted together with it
+func (u *userUseCase) issueTokenLink(ctx context.Context, purpose string, user *models.User, expire time.Duration, path string, nonce string) (string, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", errors.Wrap(err, "utils.GenerateToken")
+	}
+
+	if err := u.redisRepo.SetTokenCtx(ctx, purpose, tokenHash(token, nonce), user.UserID.String(), expire); err != nil {
+		return "", errors.Wrap(err, "redisRepo.SetTokenCtx")
+	}
+
+	return fmt.Sprintf("%s%s?token=%s", strings.TrimRight(u.cfg.Mailer.LinkBaseURL, "/"), path, url.QueryEscape(token)), nil
+}
+
+// sendEmailVerification email user a one time link proving it owns its email address
+func (u *userUseCase) sendEmailVerification(ctx context.Context, user *models.User) error {
+	expire := time.Duration(u.cfg.Signup.VerificationExpire) * time.Second
+	link, err := u.issueTokenLink(ctx, tokenPurposeVerifyEmail, user, expire, "/verify-email", "")
+	if err != nil {
+		return err
+	}
+
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening this link:\n%s\n\nThe link expires in %v.\n", user.FirstName, link, expire),
+	}); err != nil {
+		return errors.Wrap(err, "mailer.Send")
+	}
+
+	return nil
+}
+
+// tokenHash stored hash of a one time token, bound tokens hash together with their nonce
+func tokenHash(token string, nonce string) string {
+	if nonce == "" {
+		return utils.HashToken(token)
+	}
+	return utils.HashToken(nonce + "." + token)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindAll find a page of users matching filter, by cursor token when the pagination has one or by offset otherwise
+func (u *userUseCase) FindAll(ctx context.Context, filter *models.UserFilter, pagination *utils.Pagination) (*models.UsersList, error) {
+	if filter == nil {
+		filter = &models.UserFilter{}
+	}
+	sort, err := models.ParseUserSort(pagination.GetOrderBy())
+	if err != nil {
+		return nil, domain_errors.InvalidField(constants.OrderBy, err)
+	}
+
+	if pagination.GetCursor() != "" {
+		return u.findAllByCursor(ctx, filter, sort, pagination)
+	}
+
+	totalCount, err := u.userPgRepo.CountAll(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.CountAll")
+	}
+
+	users := make([]models.User, 0)
+	if totalCount > pagination.GetOffset() {
+		users, err = u.userPgRepo.FindAll(ctx, filter, pagination)
+		if err != nil {
+			return nil, errors.Wrap(err, "userPgRepo.FindAll")
+		}
+	}
+
+	for i := range users {
+		users[i].SanitizePassword()
+	}
+
+	usersList := &models.UsersList{
+		TotalCount: totalCount,
+		TotalPages: pagination.GetTotalPages(totalCount),
+		Page:       pagination.GetPage(),
+		Size:       pagination.GetSize(),
+		HasMore:    pagination.GetHasMore(totalCount),
+		Users:      users,
+	}
+
+	// cursors continue an offset listing when it is keyset compatible
+	if sort.Field == userCursorSortField && len(users) > 0 {
+		if usersList.HasMore {
+			if usersList.NextCursor, err = u.encodeUserPageToken(&users[len(users)-1], false, sort.Desc); err != nil {
+				return nil, err
+			}
+		}
+		if pagination.GetPage() > 1 {
+			if usersList.PrevCursor, err = u.encodeUserPageToken(&users[0], true, sort.Desc); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return usersList, nil
+}
+
+// findAllByCursor keyset page of users, the page token sets the position and the walk direction
+func (u *userUseCase) findAllByCursor(ctx context.Context, filter *models.UserFilter, sort *models.UserSort, pagination *utils.Pagination) (*models.UsersList, error) {
+	if sort.Field != userCursorSortField {
+		return nil, domain_errors.InvalidField(constants.OrderBy, errors.Errorf("cursor pagination is only sorted by %s", userCursorSortField))
+	}
+
+	token := &userPageToken{}
+	if err := utils.DecodeCursor([]byte(u.cfg.Server.CursorSecretKey), pagination.GetCursor(), token); err != nil {
+		return nil, domain_errors.InvalidField(constants.Cursor, err)
+	}
+	if token.Desc != sort.Desc {
+		return nil, domain_errors.InvalidField(constants.Cursor, errors.New("cursor does not match the sort order"))
+	}
+
+	// walking backward scans in the opposite order, one extra row tells whether there are more
+	users, err := u.userPgRepo.FindAllByCursor(
+		ctx,
+		filter,
+		&models.UserCursor{CreatedAt: token.CreatedAt, UserID: token.UserID},
+		sort.Desc != token.Backward,
+		pagination.GetSize()+1,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "userPgRepo.FindAllByCursor")
+	}
+
+	more := len(users) > pagination.GetSize()
+	if more {
+		users = users[:pagination.GetSize()]
+	}
+	if token.Backward {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+
+	for i := range users {
+		users[i].SanitizePassword()
+	}
+
+	usersList := &models.UsersList{Size: pagination.GetSize(), Users: users}
+	if len(users) == 0 {
+		return usersList, nil
+	}
+
+	// the side the token came from always has users, the other side only when the probe row was found
+	hasNext := more || token.Backward
+	hasPrev := more || !token.Backward
+	if hasNext {
+		if usersList.NextCursor, err = u.encodeUserPageToken(&users[len(users)-1], false, sort.Desc); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if usersList.PrevCursor, err = u.encodeUserPageToken(&users[0], true, sort.Desc); err != nil {
+			return nil, err
+		}
+	}
+	usersList.HasMore = hasNext
+
+	return usersList, nil
+}
+
+func (u *userUseCase) encodeUserPageToken(user *models.User, backward bool, desc bool) (string, error) {
+	token, err := utils.EncodeCursor([]byte(u.cfg.Server.CursorSecretKey), &userPageToken{
+		CreatedAt: user.CreatedAt,
+		UserID:    user.UserID,
+		Backward:  backward,
+		Desc:      desc,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "utils.EncodeCursor")
+	}
+	return token, nil
+}
+
+// StreamAll walk users matching filter with a keyset cursor and pass them to send in batches.
+// send is called sequentially so a blocking send throttles the table walk, the walk stops once ctx is done.
+func (u *userUseCase) StreamAll(ctx context.Context, filter *models.UserFilter, batchSize int, send func(users []models.User) error) error {
+	if filter == nil {
+		filter = &models.UserFilter{}
+	}
+	if batchSize <= 0 {
+		batchSize = defaultStreamBatchSize
+	}
+	if batchSize > maxStreamBatchSize {
+		batchSize = maxStreamBatchSize
+	}
+
+	var cursor *models.UserCursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		users, err := u.userPgRepo.FindAllByCursor(ctx, filter, cursor, false, batchSize)
+		if err != nil {
+			return errors.Wrap(err, "userPgRepo.FindAllByCursor")
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		for i := range users {
+			users[i].SanitizePassword()
+		}
+		if err := send(users); err != nil {
+			return errors.Wrap(err, "send")
+		}
+
+		if len(users) < batchSize {
+			return nil
+		}
+		last := users[len(users)-1]
+		cursor = &models.UserCursor{CreatedAt: last.CreatedAt, UserID: last.UserID}
+	}
 }
 
 // FindByEmail find user by email address
 func (u *userUseCase) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	findByEmail, err := u.userPgRepo.FindByEmail(ctx, email)
 	if err != nil {
-		return nil, errors.Wrap(err, "userPgRepo.FindByEmail")
+		return nil, errors.Wrap(notFound(err), "userPgRepo.FindByEmail")
 	}
 
 	findByEmail.SanitizePassword()
@@ -72,7 +1185,7 @@ func (u *userUseCase) FindByEmail(ctx context.Context, email string) (*models.Us
 func (u *userUseCase) FindById(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	foundUser, err := u.userPgRepo.FindById(ctx, userID)
 	if err != nil {
-		return nil, errors.Wrap(err, "userPgRepo.FindById")
+		return nil, errors.Wrap(notFound(err), "userPgRepo.FindById")
 	}
 
 	return foundUser, nil
@@ -84,13 +1197,14 @@ func (u *userUseCase) CachedFindById(ctx context.Context, userID uuid.UUID) (*mo
 	if err != nil && !errors.Is(err, redis.Nil) {
 		u.logger.Errorf("redisRepo.GetByIdCtx", err)
 	}
-	if cachedUser != nil {
+	// records cached before statuses and versions existed are reloaded
+	if cachedUser != nil && cachedUser.Status != "" && cachedUser.Version != 0 {
 		return cachedUser, nil
 	}
 
 	foundUser, err := u.userPgRepo.FindById(ctx, userID)
 	if err != nil {
-		return nil, errors.Wrap(err, "userPgRepo.FindById")
+		return nil, errors.Wrap(notFound(err), "userPgRepo.FindById")
 	}
 
 	if err := u.redisRepo.SetUserCtx(ctx, foundUser.UserID.String(), userByIdCacheDuration, foundUser); err != nil {
@@ -100,11 +1214,21 @@ func (u *userUseCase) CachedFindById(ctx context.Context, userID uuid.UUID) (*mo
 	return foundUser, nil
 }
 
-// UpdateById update user by uuid
+// UpdateById update user by uuid if it was not modified since user.Version was read
 func (u *userUseCase) UpdateById(ctx context.Context, user *models.User) (*models.User, error) {
 	updatedUser, err := u.userPgRepo.UpdateById(ctx, user)
 	if err != nil {
-		return nil, errors.Wrap(err, "userPgRepo.UpdateById")
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return nil, domain_errors.ErrEmailExists.Wrap(err)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(err, "userPgRepo.UpdateById")
+		}
+		if _, findErr := u.userPgRepo.FindById(ctx, user.UserID); findErr != nil {
+			return nil, errors.Wrap(notFound(findErr), "userPgRepo.FindById")
+		}
+		return nil, errors.Wrap(domain_errors.ErrVersionMismatch.Wrap(err), "userPgRepo.UpdateById")
 	}
 
 	if err := u.redisRepo.SetUserCtx(ctx, updatedUser.UserID.String(), userByIdCacheDuration, updatedUser); err != nil {
@@ -116,43 +1240,148 @@ func (u *userUseCase) UpdateById(ctx context.Context, user *models.User) (*model
 	return updatedUser, nil
 }
 
-// DeleteById delete user by uuid
+// DeleteById soft delete user by uuid and revoke its sessions
 func (u *userUseCase) DeleteById(ctx context.Context, userID uuid.UUID) error {
 	err := u.userPgRepo.DeleteById(ctx, userID)
 	if err != nil {
-		return errors.Wrap(err, "userPgRepo.DeleteById")
+		return errors.Wrap(notFound(err), "userPgRepo.DeleteById")
 	}
 
 	if err := u.redisRepo.DeleteUserCtx(ctx, userID.String()); err != nil {
 		u.logger.Errorf("redisRepo.DeleteUserCtx", err)
 	}
 
+	// a leftover session can not resolve its deleted user, so a failed revocation is only logged
+	if err := u.sessRepo.DeleteByUserId(ctx, userID); err != nil {
+		u.logger.Errorf("sessRepo.DeleteByUserId", err)
+	}
+	u.revokeAccessTokens(ctx, userID)
+
 	return nil
 }
 
+// RestoreById restore soft deleted user by uuid
+func (u *userUseCase) RestoreById(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	restoredUser, err := u.userPgRepo.RestoreById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(notFound(err), "userPgRepo.RestoreById")
+	}
+
+	restoredUser.SanitizePassword()
+
+	return restoredUser, nil
+}
+
+// PurgeDeleted hard delete or anonymize users soft deleted longer than the retention period, returns how many were purged
+func (u *userUseCase) PurgeDeleted(ctx context.Context) (int, error) {
+	batchSize := u.cfg.Purge.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultStreamBatchSize
+	}
+	before := time.Now().AddDate(0, 0, -u.cfg.Purge.RetentionDays)
+
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		purged, err := u.userPgRepo.PurgeDeleted(ctx, before, batchSize, u.cfg.Purge.Anonymize)
+		if err != nil {
+			return total, errors.Wrap(err, "userPgRepo.PurgeDeleted")
+		}
+		total += purged
+		if purged < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// ChangeStatus move user to a new status when the transition is allowed, its sessions and cached record are invalidated
+func (u *userUseCase) ChangeStatus(ctx context.Context, change *models.UserStatusChange) (*models.User, error) {
+	if len(change.Reason) > models.UserStatusReasonMaxLength {
+		return nil, domain_errors.InvalidField("reason", errors.Errorf("reason is longer than %d characters", models.UserStatusReasonMaxLength))
+	}
+
+	foundUser, err := u.userPgRepo.FindById(ctx, change.UserID)
+	if err != nil {
+		return nil, errors.Wrap(notFound(err), "userPgRepo.FindById")
+	}
+
+	if !models.CanTransitionStatus(foundUser.Status, change.Status) {
+		return nil, domain_errors.ErrInvalidStatusTransition.Wrap(errors.Errorf("%s to %s", foundUser.Status, change.Status))
+	}
+
+	// no row means the status changed since it was read
+	updatedUser, err := u.userPgRepo.UpdateStatus(ctx, change, foundUser.Status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidStatusTransition.Wrap(err)
+		}
+		return nil, errors.Wrap(err, "userPgRepo.UpdateStatus")
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, change.UserID.String()); err != nil {
+		u.logger.Errorf("redisRepo.DeleteUserCtx", err)
+	}
+	if err := u.sessRepo.DeleteByUserId(ctx, change.UserID); err != nil {
+		u.logger.Errorf("sessRepo.DeleteByUserId", err)
+	}
+	if change.Status != models.UserStatusActive {
+		u.revokeAccessTokens(ctx, change.UserID)
+	}
+
+	updatedUser.SanitizePassword()
+
+	return updatedUser, nil
+}
+
 // Login user with email and password
 func (u *userUseCase) Login(ctx context.Context, email string, password string) (*models.User, error) {
 	foundUser, err := u.userPgRepo.FindByEmail(ctx, email)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_errors.ErrInvalidCredentials.Wrap(err)
+		}
 		return nil, errors.Wrap(err, "userPgRepo.FindByEmail")
 	}
 
 	if err := foundUser.ComparePasswords(password); err != nil {
-		return nil, errors.Wrap(err, "user.ComparePasswords")
+		return nil, domain_errors.ErrInvalidCredentials.Wrap(errors.Wrap(err, "user.ComparePasswords"))
+	}
+
+	if !foundUser.IsActive() {
+		return nil, domain_errors.ErrUserInactive
 	}
 
 	return foundUser, err
 }
 
 func (u *userUseCase) GenerateTokenPair(user *models.User, sessionID string) (access string, refresh string, err error) {
+	return u.generateTokenPair(user, sessionID, nil)
+}
+
+// GenerateClientTokenPair token pair of a session started by an OAuth client, the access token names the client and
+// the space separated scopes of the session the user is still allowed
+func (u *userUseCase) GenerateClientTokenPair(user *models.User, session *models.Session) (access string, refresh string, err error) {
+	return u.generateTokenPair(user, session.SessionID, jwt.MapClaims{
+		"client_id": session.ClientID,
+		"scope":     strings.Join(models.ScopesForRole(user.Role, session.Scopes), " "),
+	})
+}
+
+func (u *userUseCase) generateTokenPair(user *models.User, sessionID string, extraClaims jwt.MapClaims) (access string, refresh string, err error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 
 	claims := token.Claims.(jwt.MapClaims)
+	for name, value := range extraClaims {
+		claims[name] = value
+	}
 	claims["session_id"] = sessionID
 	claims["user_id"] = user.UserID
 	claims["email"] = user.Email
 	claims["role"] = user.Role
-	claims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+	claims["exp"] = time.Now().Add(models.AccessTokenExpire).Unix()
 
 	access, err = token.SignedString([]byte(u.cfg.Server.JwtSecretKey))
 	if err != nil {
@@ -162,7 +1391,7 @@ func (u *userUseCase) GenerateTokenPair(user *models.User, sessionID string) (ac
 	refreshToken := jwt.New(jwt.SigningMethodHS256)
 	rtClaims := refreshToken.Claims.(jwt.MapClaims)
 	rtClaims["session_id"] = sessionID
-	rtClaims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+	rtClaims["exp"] = time.Now().Add(models.RefreshTokenExpire).Unix()
 
 	refresh, err = refreshToken.SignedString([]byte(u.cfg.Server.JwtSecretKey))
 	if err != nil {
@@ -171,3 +1400,11 @@ func (u *userUseCase) GenerateTokenPair(user *models.User, sessionID string) (ac
 
 	return access, refresh, nil
 }
+
+// notFound translate a missing row into domain_errors.ErrUserNotFound
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain_errors.ErrUserNotFound.Wrap(err)
+	}
+	return err
+}
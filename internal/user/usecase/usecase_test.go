@@ -4,16 +4,31 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dinorain/useraja/config"
+	mockAccessTokenRepo "github.com/dinorain/useraja/internal/accesstoken/mock"
+	mockDeviceRepo "github.com/dinorain/useraja/internal/device/mock"
 	"github.com/dinorain/useraja/internal/models"
+	mockOTPRepo "github.com/dinorain/useraja/internal/otp/mock"
+	mockRateLimitRepo "github.com/dinorain/useraja/internal/ratelimit/mock"
+	mockSessRepo "github.com/dinorain/useraja/internal/session/mock"
 	"github.com/dinorain/useraja/internal/user/mock"
+	"github.com/dinorain/useraja/pkg/domain_errors"
 	"github.com/dinorain/useraja/pkg/logger"
+	"github.com/dinorain/useraja/pkg/mailer"
+	mockMailer "github.com/dinorain/useraja/pkg/mailer/mock"
+	"github.com/dinorain/useraja/pkg/sms"
+	mockSMS "github.com/dinorain/useraja/pkg/sms/mock"
+	"github.com/dinorain/useraja/pkg/utils"
 )
 
 func TestUserUseCase_Register(t *testing.T) {
@@ -24,10 +39,11 @@ func TestUserUseCase_Register(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{Server: config.ServerConfig{JwtSecretKey: "secret123"}}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -57,6 +73,14 @@ func TestUserUseCase_Register(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, createdUser)
 	require.Equal(t, createdUser.UserID, userID)
+
+	t.Run("Soft deleted email", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), mockUser.Email).Return(nil, sql.ErrNoRows)
+		userPGRepository.EXPECT().Create(gomock.Any(), mockUser).Return(nil, errors.Wrap(&pq.Error{Code: "23505"}, "UserRepository.Create.QueryRowxContext"))
+
+		_, err := userUC.Register(ctx, mockUser)
+		require.ErrorIs(t, err, domain_errors.ErrEmailExists)
+	})
 }
 
 func TestUserUseCase_FindByEmail(t *testing.T) {
@@ -67,10 +91,11 @@ func TestUserUseCase_FindByEmail(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{Server: config.ServerConfig{JwtSecretKey: "secret123"}}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -101,10 +126,11 @@ func TestUserUseCase_Login(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{Server: config.ServerConfig{JwtSecretKey: "secret123"}}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -122,6 +148,81 @@ func TestUserUseCase_Login(t *testing.T) {
 	userPGRepository.EXPECT().FindByEmail(gomock.Any(), mockUser.Email).Return(mockUser, nil)
 	_, err := userUC.Login(ctx, mockUser.Email, mockUser.Password)
 	require.NotNil(t, err)
+	require.ErrorIs(t, err, domain_errors.ErrInvalidCredentials)
+
+	userPGRepository.EXPECT().FindByEmail(gomock.Any(), "unknown@gmail.com").Return(nil, sql.ErrNoRows)
+	_, err = userUC.Login(ctx, "unknown@gmail.com", mockUser.Password)
+	require.ErrorIs(t, err, domain_errors.ErrInvalidCredentials)
+
+	t.Run("Status", func(t *testing.T) {
+		hashedUser := *mockUser
+		require.NoError(t, hashedUser.HashPassword())
+
+		for status, expected := range map[string]error{
+			models.UserStatusActive:      nil,
+			models.UserStatusSuspended:   domain_errors.ErrUserInactive,
+			models.UserStatusDeactivated: domain_errors.ErrUserInactive,
+		} {
+			foundUser := hashedUser
+			foundUser.Status = status
+			userPGRepository.EXPECT().FindByEmail(gomock.Any(), mockUser.Email).Return(&foundUser, nil)
+
+			_, err := userUC.Login(ctx, mockUser.Email, mockUser.Password)
+			if expected == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, expected)
+			}
+		}
+	})
+}
+
+func TestUserUseCase_ChangeStatus(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	tokenRepository := mockAccessTokenRepo.NewMockAccessTokenPGRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, tokenRepository)
+
+	userID := uuid.New()
+	actorID := uuid.New()
+	ctx := context.Background()
+	change := &models.UserStatusChange{UserID: userID, Status: models.UserStatusSuspended, Reason: "spam", ActorID: &actorID}
+
+	userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(&models.User{UserID: userID, Status: models.UserStatusActive}, nil)
+	userPGRepository.EXPECT().UpdateStatus(gomock.Any(), change, models.UserStatusActive).
+		Return(&models.User{UserID: userID, Status: models.UserStatusSuspended, Password: "123456"}, nil)
+	userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userID.String()).Return(nil)
+	sessRepository.EXPECT().DeleteByUserId(gomock.Any(), userID).Return(nil)
+	tokenRepository.EXPECT().DeleteAllByUserId(gomock.Any(), userID).Return(2, nil)
+
+	updatedUser, err := userUC.ChangeStatus(ctx, change)
+	require.NoError(t, err)
+	require.Equal(t, models.UserStatusSuspended, updatedUser.Status)
+	require.Empty(t, updatedUser.Password)
+
+	t.Run("Invalid transition", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(&models.User{UserID: userID, Status: models.UserStatusDeactivated}, nil)
+
+		_, err := userUC.ChangeStatus(ctx, change)
+		require.ErrorIs(t, err, domain_errors.ErrInvalidStatusTransition)
+	})
+
+	t.Run("Concurrent change", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(&models.User{UserID: userID, Status: models.UserStatusActive}, nil)
+		userPGRepository.EXPECT().UpdateStatus(gomock.Any(), change, models.UserStatusActive).Return(nil, sql.ErrNoRows)
+
+		_, err := userUC.ChangeStatus(ctx, change)
+		require.ErrorIs(t, err, domain_errors.ErrInvalidStatusTransition)
+	})
 }
 
 func TestUserUseCase_FindByAll(t *testing.T) {
@@ -132,10 +233,11 @@ func TestUserUseCase_FindByAll(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -150,12 +252,169 @@ func TestUserUseCase_FindByAll(t *testing.T) {
 
 	ctx := context.Background()
 
-	userPGRepository.EXPECT().FindAll(gomock.Any(), nil).AnyTimes().Return(append([]models.User{}, *mockUser), nil)
+	filter := &models.UserFilter{Role: models.UserRoleAdmin, Search: "first"}
+	pagination := utils.NewPaginationQuery(10, 1)
+	userPGRepository.EXPECT().CountAll(gomock.Any(), filter).Return(11, nil)
+	userPGRepository.EXPECT().FindAll(gomock.Any(), filter, pagination).Return(append([]models.User{}, *mockUser), nil)
+
+	usersList, err := userUC.FindAll(ctx, filter, pagination)
+	require.NoError(t, err)
+	require.NotNil(t, usersList)
+	require.Equal(t, len(usersList.Users), 1)
+	require.Empty(t, usersList.Users[0].Password)
+	require.Equal(t, 11, usersList.TotalCount)
+	require.Equal(t, 2, usersList.TotalPages)
+	require.True(t, usersList.HasMore)
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		pagination := utils.NewPaginationQuery(10, 1)
+		pagination.SetOrderBy("password")
+
+		_, err := userUC.FindAll(ctx, nil, pagination)
+		require.Equal(t, domain_errors.KindValidation, domain_errors.KindOf(err))
+	})
+
+	t.Run("Page past the end", func(t *testing.T) {
+		userPGRepository.EXPECT().CountAll(gomock.Any(), &models.UserFilter{}).Return(3, nil)
+
+		usersList, err := userUC.FindAll(ctx, nil, utils.NewPaginationQuery(10, 2))
+		require.NoError(t, err)
+		require.Empty(t, usersList.Users)
+		require.False(t, usersList.HasMore)
+	})
+}
+
+func TestUserUseCase_FindAllByCursor(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{Server: config.ServerConfig{CursorSecretKey: "secret"}}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	users := make([]models.User, 0, 3)
+	for i := 0; i < 3; i++ {
+		users = append(users, models.User{UserID: uuid.New(), Password: "123456", CreatedAt: now.Add(time.Duration(i) * time.Minute)})
+	}
+
+	// first page by offset hands out a cursor to the second page
+	filter := &models.UserFilter{}
+	userPGRepository.EXPECT().CountAll(gomock.Any(), filter).Return(3, nil)
+	userPGRepository.EXPECT().FindAll(gomock.Any(), filter, gomock.Any()).Return(append([]models.User{}, users[:2]...), nil)
+
+	firstPage, err := userUC.FindAll(ctx, filter, utils.NewPaginationQuery(2, 1))
+	require.NoError(t, err)
+	require.NotEmpty(t, firstPage.NextCursor)
+	require.Empty(t, firstPage.PrevCursor)
+
+	pagination := utils.NewPaginationQuery(2, 1)
+	pagination.SetCursor(firstPage.NextCursor)
+	userPGRepository.EXPECT().FindAllByCursor(gomock.Any(), filter, &models.UserCursor{CreatedAt: users[1].CreatedAt, UserID: users[1].UserID}, false, 3).
+		Return(append([]models.User{}, users[2]), nil)
+
+	secondPage, err := userUC.FindAll(ctx, filter, pagination)
+	require.NoError(t, err)
+	require.Len(t, secondPage.Users, 1)
+	require.Empty(t, secondPage.Users[0].Password)
+	require.False(t, secondPage.HasMore)
+	require.Empty(t, secondPage.NextCursor)
+	require.NotEmpty(t, secondPage.PrevCursor)
+
+	// walking back scans in the opposite direction and restores the page order
+	pagination = utils.NewPaginationQuery(2, 1)
+	pagination.SetCursor(secondPage.PrevCursor)
+	userPGRepository.EXPECT().FindAllByCursor(gomock.Any(), filter, &models.UserCursor{CreatedAt: users[2].CreatedAt, UserID: users[2].UserID}, true, 3).
+		Return([]models.User{users[1], users[0]}, nil)
+
+	prevPage, err := userUC.FindAll(ctx, filter, pagination)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{users[0].UserID, users[1].UserID}, []uuid.UUID{prevPage.Users[0].UserID, prevPage.Users[1].UserID})
+	require.NotEmpty(t, prevPage.NextCursor)
+	require.Empty(t, prevPage.PrevCursor)
+
+	t.Run("Tampered cursor", func(t *testing.T) {
+		pagination := utils.NewPaginationQuery(2, 1)
+		pagination.SetCursor(firstPage.NextCursor + "x")
+
+		_, err := userUC.FindAll(ctx, nil, pagination)
+		require.Equal(t, domain_errors.KindValidation, domain_errors.KindOf(err))
+	})
+
+	t.Run("Sort mismatch", func(t *testing.T) {
+		pagination := utils.NewPaginationQuery(2, 1)
+		pagination.SetCursor(firstPage.NextCursor)
+		pagination.SetOrderBy("-created_at")
+
+		_, err := userUC.FindAll(ctx, nil, pagination)
+		require.Equal(t, domain_errors.KindValidation, domain_errors.KindOf(err))
+
+		pagination.SetOrderBy("email")
+		_, err = userUC.FindAll(ctx, nil, pagination)
+		require.Equal(t, domain_errors.KindValidation, domain_errors.KindOf(err))
+	})
+}
+
+func TestUserUseCase_StreamAll(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
+
+	firstBatch := []models.User{
+		{UserID: uuid.New(), CreatedAt: time.Now().Add(-time.Hour), Password: "123456"},
+		{UserID: uuid.New(), CreatedAt: time.Now().Add(-time.Minute), Password: "123456"},
+	}
+	secondBatch := []models.User{
+		{UserID: uuid.New(), CreatedAt: time.Now(), Password: "123456"},
+	}
+	filter := &models.UserFilter{Role: models.UserRoleUser}
+
+	ctx := context.Background()
 
-	users, err := userUC.FindAll(ctx, nil)
+	gomock.InOrder(
+		userPGRepository.EXPECT().FindAllByCursor(gomock.Any(), filter, nil, false, 2).Return(firstBatch, nil),
+		userPGRepository.EXPECT().FindAllByCursor(gomock.Any(), filter, &models.UserCursor{
+			CreatedAt: firstBatch[1].CreatedAt,
+			UserID:    firstBatch[1].UserID,
+		}, false, 2).Return(secondBatch, nil),
+	)
+
+	var batches [][]models.User
+	err := userUC.StreamAll(ctx, filter, 2, func(users []models.User) error {
+		batches = append(batches, users)
+		return nil
+	})
 	require.NoError(t, err)
-	require.NotNil(t, users)
-	require.Equal(t, len(users), 1)
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0], 2)
+	require.Len(t, batches[1], 1)
+	require.Empty(t, batches[0][0].Password)
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := userUC.StreamAll(ctx, filter, 2, func(users []models.User) error {
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
 }
 
 func TestUserUseCase_FindById(t *testing.T) {
@@ -166,10 +425,11 @@ func TestUserUseCase_FindById(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -203,10 +463,11 @@ func TestUserUseCase_CachedFindById(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -239,10 +500,11 @@ func TestUserUseCase_UpdateById(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -257,13 +519,41 @@ func TestUserUseCase_UpdateById(t *testing.T) {
 
 	ctx := context.Background()
 
-	userPGRepository.EXPECT().UpdateById(gomock.Any(), mockUser).Return(mockUser, nil)
-	userRedisRepository.EXPECT().SetUserCtx(gomock.Any(), mockUser.UserID.String(), 3600, mockUser).AnyTimes().Return(nil)
-
-	user, err := userUC.UpdateById(ctx, mockUser)
-	require.NoError(t, err)
-	require.NotNil(t, user)
-	require.Equal(t, user.UserID, mockUser.UserID)
+	t.Run("Update", func(t *testing.T) {
+		userPGRepository.EXPECT().UpdateById(gomock.Any(), mockUser).Return(mockUser, nil)
+		userRedisRepository.EXPECT().SetUserCtx(gomock.Any(), mockUser.UserID.String(), 3600, mockUser).AnyTimes().Return(nil)
+
+		user, err := userUC.UpdateById(ctx, mockUser)
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		require.Equal(t, user.UserID, mockUser.UserID)
+	})
+
+	t.Run("Stale version", func(t *testing.T) {
+		userPGRepository.EXPECT().UpdateById(gomock.Any(), mockUser).Return(nil, sql.ErrNoRows)
+		userPGRepository.EXPECT().FindById(gomock.Any(), mockUser.UserID).Return(&models.User{UserID: userID, Version: 2}, nil)
+
+		user, err := userUC.UpdateById(ctx, mockUser)
+		require.ErrorIs(t, err, domain_errors.ErrVersionMismatch)
+		require.Nil(t, user)
+	})
+
+	t.Run("Email exists", func(t *testing.T) {
+		userPGRepository.EXPECT().UpdateById(gomock.Any(), mockUser).Return(nil, errors.Wrap(&pq.Error{Code: uniqueViolation}, "UserRepository.Update.QueryRowxContext"))
+
+		user, err := userUC.UpdateById(ctx, mockUser)
+		require.ErrorIs(t, err, domain_errors.ErrEmailExists)
+		require.Nil(t, user)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		userPGRepository.EXPECT().UpdateById(gomock.Any(), mockUser).Return(nil, sql.ErrNoRows)
+		userPGRepository.EXPECT().FindById(gomock.Any(), mockUser.UserID).Return(nil, sql.ErrNoRows)
+
+		user, err := userUC.UpdateById(ctx, mockUser)
+		require.ErrorIs(t, err, domain_errors.ErrUserNotFound)
+		require.Nil(t, user)
+	})
 }
 
 func TestUserUseCase_DeleteById(t *testing.T) {
@@ -274,10 +564,12 @@ func TestUserUseCase_DeleteById(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	tokenRepository := mockAccessTokenRepo.NewMockAccessTokenPGRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, tokenRepository)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -293,6 +585,8 @@ func TestUserUseCase_DeleteById(t *testing.T) {
 
 	userPGRepository.EXPECT().DeleteById(gomock.Any(), mockUser.UserID).Return(nil)
 	userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), mockUser.UserID.String()).AnyTimes().Return(nil)
+	sessRepository.EXPECT().DeleteByUserId(gomock.Any(), mockUser.UserID).Return(nil)
+	tokenRepository.EXPECT().DeleteAllByUserId(gomock.Any(), mockUser.UserID).Return(0, nil)
 
 	err := userUC.DeleteById(ctx, mockUser.UserID)
 	require.NoError(t, err)
@@ -301,6 +595,58 @@ func TestUserUseCase_DeleteById(t *testing.T) {
 	userRedisRepository.EXPECT().GetByIdCtx(gomock.Any(), mockUser.UserID.String()).AnyTimes().Return(nil, redis.Nil)
 }
 
+func TestUserUseCase_RestoreById(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	ctx := context.Background()
+
+	userPGRepository.EXPECT().RestoreById(gomock.Any(), userID).Return(&models.User{UserID: userID, Password: "123456"}, nil)
+	restoredUser, err := userUC.RestoreById(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, userID, restoredUser.UserID)
+	require.Empty(t, restoredUser.Password)
+
+	userPGRepository.EXPECT().RestoreById(gomock.Any(), userID).Return(nil, sql.ErrNoRows)
+	_, err = userUC.RestoreById(ctx, userID)
+	require.ErrorIs(t, err, domain_errors.ErrUserNotFound)
+}
+
+func TestUserUseCase_PurgeDeleted(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{Purge: config.Purge{RetentionDays: 30, BatchSize: 2, Anonymize: true}}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
+
+	gomock.InOrder(
+		userPGRepository.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any(), 2, true).Return(2, nil),
+		userPGRepository.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any(), 2, true).Return(1, nil),
+	)
+
+	purged, err := userUC.PurgeDeleted(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, purged)
+}
+
 func TestUserUseCase_GenerateTokenPair(t *testing.T) {
 	t.Parallel()
 
@@ -309,10 +655,11 @@ func TestUserUseCase_GenerateTokenPair(t *testing.T) {
 
 	userPGRepository := mock.NewMockUserPGRepository(ctrl)
 	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
 	apiLogger := logger.NewAppLogger(nil)
 
 	cfg := &config.Config{}
-	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository)
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
 
 	userID := uuid.New()
 	mockUser := &models.User{
@@ -330,3 +677,790 @@ func TestUserUseCase_GenerateTokenPair(t *testing.T) {
 	require.NotEqual(t, at, "")
 	require.NotEqual(t, rt, "")
 }
+
+func TestUserUseCase_GenerateClientTokenPair(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Server: config.ServerConfig{JwtSecretKey: "secret"}}
+	userUC := NewUserUseCase(cfg, logger.NewAppLogger(nil), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	mockUser := &models.User{UserID: uuid.New(), Email: "email@gmail.com", Role: models.UserRoleUser}
+	session := &models.Session{
+		SessionID: "s",
+		UserID:    mockUser.UserID,
+		ClientID:  uuid.New().String(),
+		Scopes:    []string{models.APIKeyScopeUsersRead, models.APIKeyScopeUsersWrite},
+	}
+
+	at, rt, err := userUC.GenerateClientTokenPair(mockUser, session)
+	require.NoError(t, err)
+	require.NotEqual(t, rt, "")
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(at, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, session.ClientID, claims["client_id"])
+	require.Equal(t, models.APIKeyScopeUsersRead, claims["scope"])
+	require.Equal(t, "s", claims["session_id"])
+}
+
+func TestUserUseCase_Signup(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	userMailer := mockMailer.NewMockMailer(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{
+		Mailer: config.Mailer{LinkBaseURL: "http://localhost:3000/"},
+		Signup: config.Signup{
+			Mode:                     config.SignupModeOpen,
+			DeniedDomains:            []string{"mailinator.com"},
+			AllowedDomains:           []string{"example.com"},
+			InviteCodes:              []string{"welcome"},
+			RequireEmailVerification: true,
+			VerificationExpire:       60,
+		},
+	}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, userMailer, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	newUser := func(email string) *models.User {
+		return &models.User{Email: email, FirstName: "FirstName", LastName: "LastName", Role: models.UserRoleAdmin, Password: "hash"}
+	}
+
+	t.Run("Open", func(t *testing.T) {
+		user := newUser("email@gmail.com")
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(nil, sql.ErrNoRows)
+		userPGRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			require.Equal(t, models.UserRoleUser, user.Role)
+			require.Equal(t, models.UserStatusPending, user.Status)
+			created := *user
+			created.UserID = uuid.New()
+			return &created, nil
+		})
+		userRedisRepository.EXPECT().SetTokenCtx(gomock.Any(), tokenPurposeVerifyEmail, gomock.Any(), gomock.Any(), time.Minute).Return(nil)
+		userMailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *mailer.Message) error {
+			require.Equal(t, user.Email, msg.To)
+			require.Contains(t, msg.Body, "http://localhost:3000/verify-email?token=")
+			return nil
+		})
+
+		createdUser, err := userUC.Signup(ctx, user, "")
+		require.NoError(t, err)
+		require.Equal(t, models.UserRoleUser, createdUser.Role)
+		require.Empty(t, createdUser.Password)
+	})
+
+	t.Run("Denied domain", func(t *testing.T) {
+		_, err := userUC.Signup(ctx, newUser("email@Mailinator.com"), "")
+		require.ErrorIs(t, err, domain_errors.ErrEmailDomainNotAllowed)
+	})
+
+	t.Run("Allowlist", func(t *testing.T) {
+		cfg := *cfg
+		cfg.Signup.Mode = config.SignupModeAllowlist
+		userUC := NewUserUseCase(&cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, userMailer, nil, nil, nil, nil, nil)
+
+		_, err := userUC.Signup(ctx, newUser("email@gmail.com"), "")
+		require.ErrorIs(t, err, domain_errors.ErrEmailDomainNotAllowed)
+	})
+
+	t.Run("Invite only", func(t *testing.T) {
+		cfg := *cfg
+		cfg.Signup.Mode = config.SignupModeInvite
+		userUC := NewUserUseCase(&cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, userMailer, nil, nil, nil, nil, nil)
+
+		_, err := userUC.Signup(ctx, newUser("email@gmail.com"), "wrong")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidInviteCode)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		cfg := *cfg
+		cfg.Signup.Mode = ""
+		userUC := NewUserUseCase(&cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, userMailer, nil, nil, nil, nil, nil)
+
+		_, err := userUC.Signup(ctx, newUser("email@gmail.com"), "")
+		require.ErrorIs(t, err, domain_errors.ErrSignupClosed)
+	})
+}
+
+func TestUserUseCase_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	ctx := context.Background()
+
+	t.Run("Pending user is activated", func(t *testing.T) {
+		userRedisRepository.EXPECT().GetDelTokenCtx(gomock.Any(), tokenPurposeVerifyEmail, utils.HashToken("token")).Return(userID.String(), nil)
+		userPGRepository.EXPECT().VerifyEmail(gomock.Any(), userID).Return(&models.User{UserID: userID, Status: models.UserStatusPending}, nil)
+		userPGRepository.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), models.UserStatusPending).Return(&models.User{UserID: userID, Status: models.UserStatusActive}, nil)
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userID.String()).Return(nil)
+
+		user, err := userUC.VerifyEmail(ctx, "token")
+		require.NoError(t, err)
+		require.Equal(t, models.UserStatusActive, user.Status)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		userRedisRepository.EXPECT().GetDelTokenCtx(gomock.Any(), tokenPurposeVerifyEmail, utils.HashToken("token")).Return("", redis.Nil)
+
+		user, err := userUC.VerifyEmail(ctx, "token")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidVerification)
+		require.Nil(t, user)
+	})
+}
+
+func TestUserUseCase_ResendEmailVerification(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	userMailer := mockMailer.NewMockMailer(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, userMailer, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	verifiedAt := time.Now()
+
+	t.Run("Unverified", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "email@gmail.com").Return(&models.User{UserID: uuid.New(), Email: "email@gmail.com"}, nil)
+		userRedisRepository.EXPECT().SetTokenCtx(gomock.Any(), tokenPurposeVerifyEmail, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		userMailer.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+		require.NoError(t, userUC.ResendEmailVerification(ctx, " Email@gmail.com"))
+	})
+
+	t.Run("Verified", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "email@gmail.com").Return(&models.User{Email: "email@gmail.com", EmailVerifiedAt: &verifiedAt}, nil)
+
+		require.NoError(t, userUC.ResendEmailVerification(ctx, "email@gmail.com"))
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "unknown@gmail.com").Return(nil, sql.ErrNoRows)
+
+		require.NoError(t, userUC.ResendEmailVerification(ctx, "unknown@gmail.com"))
+	})
+}
+
+func TestUserUseCase_Invite(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	userMailer := mockMailer.NewMockMailer(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{Mailer: config.Mailer{LinkBaseURL: "http://localhost:3000"}, Invitation: config.Invitation{Expire: 60}}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, userMailer, nil, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	ctx := context.Background()
+
+	t.Run("Invite", func(t *testing.T) {
+		user := &models.User{Email: "email@gmail.com", FirstName: "FirstName", LastName: "LastName", Role: models.UserRoleUser}
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(nil, sql.ErrNoRows)
+		userPGRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			require.False(t, user.HasPassword())
+			require.Equal(t, models.UserStatusPending, user.Status)
+			created := *user
+			created.UserID = userID
+			return &created, nil
+		})
+		userRedisRepository.EXPECT().SetTokenCtx(gomock.Any(), tokenPurposeInvitation, gomock.Any(), userID.String(), time.Minute).Return(nil)
+		userMailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *mailer.Message) error {
+			require.Contains(t, msg.Body, "http://localhost:3000/accept-invitation?token=")
+			return nil
+		})
+
+		invitedUser, err := userUC.Invite(ctx, user)
+		require.NoError(t, err)
+		require.Empty(t, invitedUser.Password)
+	})
+
+	t.Run("Resend accepted invitation", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(&models.User{UserID: userID, Status: models.UserStatusActive, Password: "hash"}, nil)
+
+		err := userUC.ResendInvitation(ctx, userID)
+		require.ErrorIs(t, err, domain_errors.ErrInvitationNotFound)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		userPGRepository.EXPECT().DeleteInvited(gomock.Any(), userID).Return(nil)
+		userRedisRepository.EXPECT().DeleteTokenCtx(gomock.Any(), tokenPurposeInvitation, userID.String()).Return(nil)
+
+		require.NoError(t, userUC.RevokeInvitation(ctx, userID))
+	})
+
+	t.Run("Revoke unknown invitation", func(t *testing.T) {
+		userPGRepository.EXPECT().DeleteInvited(gomock.Any(), userID).Return(sql.ErrNoRows)
+
+		require.ErrorIs(t, userUC.RevokeInvitation(ctx, userID), domain_errors.ErrInvitationNotFound)
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		userRedisRepository.EXPECT().GetDelTokenCtx(gomock.Any(), tokenPurposeInvitation, utils.HashToken("token")).Return(userID.String(), nil)
+		userPGRepository.EXPECT().AcceptInvitation(gomock.Any(), userID, gomock.Any()).DoAndReturn(func(ctx context.Context, userID uuid.UUID, password string) (*models.User, error) {
+			require.NoError(t, (&models.User{Password: password}).ComparePasswords("secret"))
+			return &models.User{UserID: userID, Status: models.UserStatusActive, Password: password}, nil
+		})
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userID.String()).Return(nil)
+
+		acceptedUser, err := userUC.AcceptInvitation(ctx, "token", "secret")
+		require.NoError(t, err)
+		require.Equal(t, models.UserStatusActive, acceptedUser.Status)
+		require.Empty(t, acceptedUser.Password)
+	})
+
+	t.Run("Accept expired invitation", func(t *testing.T) {
+		userRedisRepository.EXPECT().GetDelTokenCtx(gomock.Any(), tokenPurposeInvitation, utils.HashToken("token")).Return("", redis.Nil)
+
+		_, err := userUC.AcceptInvitation(ctx, "token", "secret")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidInvitation)
+	})
+}
+
+func TestUserUseCase_MagicLink(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	userMailer := mockMailer.NewMockMailer(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{Mailer: config.Mailer{LinkBaseURL: "http://localhost:3000"}, MagicLink: config.MagicLink{Expire: 900}}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, userMailer, nil, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	activeUser := &models.User{UserID: userID, Email: "email@gmail.com", Status: models.UserStatusActive, Password: "hash"}
+	ctx := context.Background()
+
+	t.Run("Request", func(t *testing.T) {
+		var token string
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "email@gmail.com").Return(activeUser, nil)
+		userRedisRepository.EXPECT().SetTokenCtx(gomock.Any(), tokenPurposeMagicLink, gomock.Any(), userID.String(), 15*time.Minute).
+			DoAndReturn(func(ctx context.Context, purpose string, hash string, userID string, expire time.Duration) error {
+				token = hash
+				return nil
+			})
+		userMailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *mailer.Message) error {
+			require.Contains(t, msg.Body, "http://localhost:3000/magic-link?token=")
+			return nil
+		})
+
+		require.NoError(t, userUC.RequestMagicLink(ctx, "Email@gmail.com", "nonce"))
+		require.NotEmpty(t, token)
+	})
+
+	t.Run("Request unknown email", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "unknown@gmail.com").Return(nil, sql.ErrNoRows)
+
+		require.NoError(t, userUC.RequestMagicLink(ctx, "unknown@gmail.com", "nonce"))
+	})
+
+	t.Run("Request inactive user", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "email@gmail.com").Return(&models.User{UserID: userID, Status: models.UserStatusSuspended}, nil)
+
+		require.NoError(t, userUC.RequestMagicLink(ctx, "email@gmail.com", "nonce"))
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		userRedisRepository.EXPECT().GetDelTokenCtx(gomock.Any(), tokenPurposeMagicLink, utils.HashToken("nonce.token")).Return(userID.String(), nil)
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(activeUser, nil)
+
+		user, err := userUC.LoginWithMagicLink(ctx, "token", "nonce")
+		require.NoError(t, err)
+		require.Equal(t, userID, user.UserID)
+	})
+
+	t.Run("Login from another browser", func(t *testing.T) {
+		userRedisRepository.EXPECT().GetDelTokenCtx(gomock.Any(), tokenPurposeMagicLink, utils.HashToken("other.token")).Return("", redis.Nil)
+
+		_, err := userUC.LoginWithMagicLink(ctx, "token", "other")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidMagicLink)
+	})
+
+	t.Run("Login without nonce", func(t *testing.T) {
+		_, err := userUC.LoginWithMagicLink(ctx, "token", "")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidMagicLink)
+	})
+}
+
+func TestUserUseCase_LoginCode(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	otpRepository := mockOTPRepo.NewMockOTPRepository(ctrl)
+	userMailer := mockMailer.NewMockMailer(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{Server: config.ServerConfig{JwtSecretKey: "secret"}, OTP: config.OTP{Expire: 300, MaxAttempts: 5}}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, userMailer, otpRepository, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	activeUser := &models.User{UserID: userID, Email: "email@gmail.com", Status: models.UserStatusActive, Password: "hash"}
+	key := otpKey(models.OTPPurposeLogin, userID)
+	ctx := context.Background()
+
+	t.Run("Request", func(t *testing.T) {
+		var codeHash string
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "email@gmail.com").Return(activeUser, nil)
+		otpRepository.EXPECT().Create(gomock.Any(), key, gomock.Any(), 5*time.Minute).
+			DoAndReturn(func(ctx context.Context, key string, hash string, expire time.Duration) error {
+				codeHash = hash
+				return nil
+			})
+		userMailer.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *mailer.Message) error {
+			require.Equal(t, "email@gmail.com", msg.To)
+			return nil
+		})
+
+		require.NoError(t, userUC.RequestLoginCode(ctx, " Email@gmail.com"))
+		require.NotEmpty(t, codeHash)
+	})
+
+	t.Run("Request unknown email", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "unknown@gmail.com").Return(nil, sql.ErrNoRows)
+
+		require.NoError(t, userUC.RequestLoginCode(ctx, "unknown@gmail.com"))
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "email@gmail.com").Return(activeUser, nil)
+		otpRepository.EXPECT().Verify(gomock.Any(), key, utils.HashCode("secret", key+":123456"), 5).Return(true, nil)
+
+		user, err := userUC.LoginWithCode(ctx, "email@gmail.com", "123456")
+		require.NoError(t, err)
+		require.Equal(t, userID, user.UserID)
+		require.Empty(t, user.Password)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "email@gmail.com").Return(activeUser, nil)
+		otpRepository.EXPECT().Verify(gomock.Any(), key, gomock.Any(), 5).Return(false, nil)
+
+		_, err := userUC.LoginWithCode(ctx, "email@gmail.com", "654321")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidCode)
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByEmail(gomock.Any(), "unknown@gmail.com").Return(nil, sql.ErrNoRows)
+
+		_, err := userUC.LoginWithCode(ctx, "unknown@gmail.com", "123456")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidCode)
+	})
+}
+
+func TestUserUseCase_Phone(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	otpRepository := mockOTPRepo.NewMockOTPRepository(ctrl)
+	smsSender := mockSMS.NewMockSMSSender(ctrl)
+	rateLimitRepository := mockRateLimitRepo.NewMockRateLimitRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{JwtSecretKey: "secret"},
+		OTP:    config.OTP{Expire: 300, MaxAttempts: 5},
+		SMS:    config.SMS{RateLimit: 3, RateLimitWindow: 3600},
+	}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, otpRepository, smsSender, rateLimitRepository, nil, nil)
+
+	userID := uuid.New()
+	phone := "+6281234567890"
+	verifiedAt := time.Now()
+	unverifiedUser := &models.User{UserID: userID, Status: models.UserStatusActive, PhoneNumber: &phone}
+	verifiedUser := &models.User{UserID: userID, Status: models.UserStatusActive, PhoneNumber: &phone, PhoneVerifiedAt: &verifiedAt}
+	verifyKey := smsOTPKey(models.OTPPurposePhoneVerify, userID, phone)
+	loginKey := smsOTPKey(models.OTPPurposeSMSLogin, userID, phone)
+	ctx := context.Background()
+
+	t.Run("Request verification", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(&models.User{UserID: userID}, nil)
+		rateLimitRepository.EXPECT().Allow(gomock.Any(), "sms:"+phone, 3, time.Hour).Return(true, time.Duration(0), nil)
+		userPGRepository.EXPECT().SetPhoneNumber(gomock.Any(), userID, phone).Return(unverifiedUser, nil)
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userID.String()).Return(nil)
+		otpRepository.EXPECT().Create(gomock.Any(), verifyKey, gomock.Any(), 5*time.Minute).Return(nil)
+		smsSender.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *sms.Message) error {
+			require.Equal(t, phone, msg.To)
+			return nil
+		})
+
+		user, err := userUC.RequestPhoneVerification(ctx, userID, phone)
+		require.NoError(t, err)
+		require.Equal(t, phone, *user.PhoneNumber)
+		require.False(t, user.HasVerifiedPhone())
+	})
+
+	t.Run("Request verification of verified number", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(verifiedUser, nil)
+
+		user, err := userUC.RequestPhoneVerification(ctx, userID, phone)
+		require.NoError(t, err)
+		require.True(t, user.HasVerifiedPhone())
+	})
+
+	t.Run("Request verification rate limited", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(&models.User{UserID: userID}, nil)
+		rateLimitRepository.EXPECT().Allow(gomock.Any(), "sms:"+phone, 3, time.Hour).Return(false, time.Minute, nil)
+
+		_, err := userUC.RequestPhoneVerification(ctx, userID, phone)
+		var domainErr *domain_errors.Error
+		require.True(t, errors.As(err, &domainErr))
+		require.Equal(t, domain_errors.ReasonRateLimited, domainErr.Reason)
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(unverifiedUser, nil)
+		otpRepository.EXPECT().Verify(gomock.Any(), verifyKey, utils.HashCode("secret", verifyKey+":123456"), 5).Return(true, nil)
+		userPGRepository.EXPECT().VerifyPhoneNumber(gomock.Any(), userID, phone).Return(verifiedUser, nil)
+		userRedisRepository.EXPECT().DeleteUserCtx(gomock.Any(), userID.String()).Return(nil)
+
+		user, err := userUC.VerifyPhoneNumber(ctx, userID, "123456")
+		require.NoError(t, err)
+		require.True(t, user.HasVerifiedPhone())
+	})
+
+	t.Run("Verify number of another account", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(unverifiedUser, nil)
+		otpRepository.EXPECT().Verify(gomock.Any(), verifyKey, gomock.Any(), 5).Return(true, nil)
+		userPGRepository.EXPECT().VerifyPhoneNumber(gomock.Any(), userID, phone).Return(nil, &pq.Error{Code: uniqueViolation})
+
+		_, err := userUC.VerifyPhoneNumber(ctx, userID, "123456")
+		require.ErrorIs(t, err, domain_errors.ErrPhoneNumberExists)
+	})
+
+	t.Run("Verify without number", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(&models.User{UserID: userID}, nil)
+
+		_, err := userUC.VerifyPhoneNumber(ctx, userID, "123456")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidCode)
+	})
+
+	t.Run("Request login code", func(t *testing.T) {
+		rateLimitRepository.EXPECT().Allow(gomock.Any(), "sms:"+phone, 3, time.Hour).Return(true, time.Duration(0), nil)
+		userPGRepository.EXPECT().FindByVerifiedPhone(gomock.Any(), phone).Return(verifiedUser, nil)
+		otpRepository.EXPECT().Create(gomock.Any(), loginKey, gomock.Any(), 5*time.Minute).Return(nil)
+		smsSender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+		require.NoError(t, userUC.RequestSMSLoginCode(ctx, phone))
+	})
+
+	t.Run("Request login code unknown number", func(t *testing.T) {
+		rateLimitRepository.EXPECT().Allow(gomock.Any(), "sms:+6289999999999", 3, time.Hour).Return(true, time.Duration(0), nil)
+		userPGRepository.EXPECT().FindByVerifiedPhone(gomock.Any(), "+6289999999999").Return(nil, sql.ErrNoRows)
+
+		require.NoError(t, userUC.RequestSMSLoginCode(ctx, "+6289999999999"))
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByVerifiedPhone(gomock.Any(), phone).Return(verifiedUser, nil)
+		otpRepository.EXPECT().Verify(gomock.Any(), loginKey, utils.HashCode("secret", loginKey+":123456"), 5).Return(true, nil)
+
+		user, err := userUC.LoginWithSMSCode(ctx, phone, "123456")
+		require.NoError(t, err)
+		require.Equal(t, userID, user.UserID)
+	})
+
+	t.Run("Login unknown number", func(t *testing.T) {
+		userPGRepository.EXPECT().FindByVerifiedPhone(gomock.Any(), phone).Return(nil, sql.ErrNoRows)
+
+		_, err := userUC.LoginWithSMSCode(ctx, phone, "123456")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidCode)
+	})
+}
+
+func TestUserUseCase_StepUp(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	otpRepository := mockOTPRepo.NewMockOTPRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{Server: config.ServerConfig{JwtSecretKey: "secret"}, OTP: config.OTP{Expire: 300, MaxAttempts: 5}}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, otpRepository, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	key := otpKey(models.OTPPurposeStepUp, userID)
+	ctx := context.Background()
+
+	t.Run("Step up", func(t *testing.T) {
+		session := &models.Session{SessionID: "s", UserID: userID, ACR: models.SessionACRBasic}
+		otpRepository.EXPECT().Verify(gomock.Any(), key, utils.HashCode("secret", key+":123456"), 5).Return(true, nil)
+		sessRepository.EXPECT().UpdateSession(gomock.Any(), session).Return(nil)
+
+		steppedUp, err := userUC.StepUp(ctx, session, "123456")
+		require.NoError(t, err)
+		require.True(t, steppedUp.SteppedUp(time.Minute))
+		require.True(t, steppedUp.HasMethod(models.AuthMethodOTP))
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		session := &models.Session{SessionID: "s", UserID: userID, ACR: models.SessionACRBasic}
+		otpRepository.EXPECT().Verify(gomock.Any(), key, gomock.Any(), 5).Return(false, nil)
+
+		_, err := userUC.StepUp(ctx, session, "654321")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidCode)
+		require.False(t, session.SteppedUp(time.Minute))
+	})
+
+	t.Run("Expired session", func(t *testing.T) {
+		session := &models.Session{SessionID: "s", UserID: userID}
+		otpRepository.EXPECT().Verify(gomock.Any(), key, gomock.Any(), 5).Return(true, nil)
+		sessRepository.EXPECT().UpdateSession(gomock.Any(), session).Return(errors.Wrap(redis.Nil, "sessionRepo.UpdateSession"))
+
+		_, err := userUC.StepUp(ctx, session, "123456")
+		require.ErrorIs(t, err, domain_errors.ErrSessionNotFound)
+	})
+}
+
+func TestUserUseCase_TrustedDevices(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	deviceRepository := mockDeviceRepo.NewMockDevicePGRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{Server: config.ServerConfig{JwtSecretKey: "secret"}, TrustedDevice: config.TrustedDevice{Expire: 3600}}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, deviceRepository, nil)
+
+	userID := uuid.New()
+	deviceID := uuid.New()
+	ctx := context.Background()
+
+	var token, tokenHash string
+	t.Run("Trust", func(t *testing.T) {
+		deviceRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, device *models.TrustedDevice) (*models.TrustedDevice, error) {
+			require.Equal(t, userID, device.UserID)
+			require.Equal(t, "Firefox", device.Name)
+			require.WithinDuration(t, time.Now().Add(time.Hour), device.ExpiresAt, time.Minute)
+			tokenHash = device.TokenHash
+			device.DeviceID = deviceID
+			return device, nil
+		})
+
+		signed, device, err := userUC.TrustDevice(ctx, userID, " Firefox ", "127.0.0.1")
+		require.NoError(t, err)
+		require.Equal(t, deviceID, device.DeviceID)
+		token = signed
+	})
+
+	t.Run("Touch", func(t *testing.T) {
+		deviceRepository.EXPECT().Touch(gomock.Any(), userID, tokenHash, "10.0.0.1").Return(&models.TrustedDevice{DeviceID: deviceID}, nil)
+
+		device, err := userUC.TouchTrustedDevice(ctx, userID, token, "10.0.0.1")
+		require.NoError(t, err)
+		require.Equal(t, deviceID, device.DeviceID)
+	})
+
+	t.Run("Touch forged token", func(t *testing.T) {
+		_, err := userUC.TouchTrustedDevice(ctx, userID, token+"x", "10.0.0.1")
+		require.ErrorIs(t, err, domain_errors.ErrTrustedDeviceNotFound)
+	})
+
+	t.Run("Touch revoked device", func(t *testing.T) {
+		deviceRepository.EXPECT().Touch(gomock.Any(), userID, tokenHash, "10.0.0.1").Return(nil, sql.ErrNoRows)
+
+		_, err := userUC.TouchTrustedDevice(ctx, userID, token, "10.0.0.1")
+		require.ErrorIs(t, err, domain_errors.ErrTrustedDeviceNotFound)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		deviceRepository.EXPECT().DeleteById(gomock.Any(), userID, deviceID).Return(nil)
+		require.NoError(t, userUC.RevokeTrustedDevice(ctx, userID, deviceID))
+
+		deviceRepository.EXPECT().DeleteById(gomock.Any(), userID, deviceID).Return(sql.ErrNoRows)
+		require.ErrorIs(t, userUC.RevokeTrustedDevice(ctx, userID, deviceID), domain_errors.ErrTrustedDeviceNotFound)
+	})
+}
+
+func TestUserUseCase_Reauthenticate(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	sessRepository := mockSessRepo.NewMockSessRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	userUC := NewUserUseCase(&config.Config{}, apiLogger, userPGRepository, userRedisRepository, sessRepository, nil, nil, nil, nil, nil, nil)
+
+	userID := uuid.New()
+	activeUser := &models.User{UserID: userID, Email: "email@gmail.com", Status: models.UserStatusActive, Password: "123456"}
+	require.NoError(t, activeUser.HashPassword())
+	ctx := context.Background()
+
+	t.Run("Reauthenticate", func(t *testing.T) {
+		session := &models.Session{
+			SessionID: "s",
+			UserID:    userID,
+			AuthTime:  time.Now().Add(-time.Hour),
+			ACR:       models.SessionACRStepUp,
+			AMR:       []string{models.AuthMethodMagicLink, models.AuthMethodOTP},
+		}
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(activeUser, nil)
+		sessRepository.EXPECT().UpdateSession(gomock.Any(), session).Return(nil)
+
+		reauthenticated, err := userUC.Reauthenticate(ctx, session, "123456")
+		require.NoError(t, err)
+		require.True(t, reauthenticated.AuthenticatedWithin(time.Minute, models.AuthMethodPassword))
+		require.Equal(t, []string{models.AuthMethodPassword}, reauthenticated.AMR)
+		require.False(t, reauthenticated.SteppedUp(time.Hour))
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		session := &models.Session{SessionID: "s", UserID: userID}
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(activeUser, nil)
+
+		_, err := userUC.Reauthenticate(ctx, session, "wrong")
+		require.ErrorIs(t, err, domain_errors.ErrInvalidCredentials)
+		require.True(t, session.AuthTime.IsZero())
+	})
+}
+
+func TestUserUseCase_AccessTokens(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userPGRepository := mock.NewMockUserPGRepository(ctrl)
+	userRedisRepository := mock.NewMockUserRedisRepository(ctrl)
+	tokenRepository := mockAccessTokenRepo.NewMockAccessTokenPGRepository(ctrl)
+	apiLogger := logger.NewAppLogger(nil)
+
+	cfg := &config.Config{PersonalAccessToken: config.PersonalAccessToken{Expire: 3600, MaxExpire: 7200}}
+	userUC := NewUserUseCase(cfg, apiLogger, userPGRepository, userRedisRepository, nil, nil, nil, nil, nil, nil, tokenRepository)
+
+	userID := uuid.New()
+	tokenID := uuid.New()
+	ctx := context.Background()
+	member := &models.User{UserID: userID, Role: models.UserRoleUser, Status: models.UserStatusActive, Version: 1}
+
+	var token, tokenHash string
+	t.Run("Create", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(member, nil)
+		tokenRepository.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, token *models.PersonalAccessToken) (*models.PersonalAccessToken, error) {
+			require.Equal(t, userID, token.UserID)
+			require.Equal(t, "deploy script", token.Name)
+			require.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Minute)
+			tokenHash = token.TokenHash
+			token.TokenID = tokenID
+			return token, nil
+		})
+
+		created, createdToken, err := userUC.CreateAccessToken(ctx, userID, " deploy script ", []string{models.APIKeyScopeUsersRead}, 0)
+		require.NoError(t, err)
+		require.Equal(t, tokenID, createdToken.TokenID)
+		require.Equal(t, utils.HashToken(created), tokenHash)
+		require.Equal(t, models.PersonalAccessTokenPrefix, created[:len(models.PersonalAccessTokenPrefix)])
+		token = created
+	})
+
+	t.Run("Scope beyond the user", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(member, nil)
+
+		_, _, err := userUC.CreateAccessToken(ctx, userID, "admin script", []string{models.APIKeyScopeUsersWrite}, 0)
+		require.ErrorIs(t, err, domain_errors.ErrScopeNotAllowed)
+	})
+
+	t.Run("Expiry beyond the maximum", func(t *testing.T) {
+		userPGRepository.EXPECT().FindById(gomock.Any(), userID).Return(member, nil)
+
+		_, _, err := userUC.CreateAccessToken(ctx, userID, "forever", []string{models.APIKeyScopeUsersRead}, 24*time.Hour)
+		require.Error(t, err)
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		tokenRepository.EXPECT().Authenticate(gomock.Any(), tokenHash).Return(&models.PersonalAccessToken{
+			TokenID: tokenID,
+			UserID:  userID,
+			Scopes:  []string{models.APIKeyScopeUsersRead, models.APIKeyScopeUsersWrite},
+		}, nil)
+		userRedisRepository.EXPECT().GetByIdCtx(gomock.Any(), userID.String()).Return(member, nil)
+
+		authenticated, tokenUser, err := userUC.AuthenticateAccessToken(ctx, token)
+		require.NoError(t, err)
+		require.Equal(t, userID, tokenUser.UserID)
+		require.True(t, authenticated.HasScope(models.APIKeyScopeUsersRead))
+		require.False(t, authenticated.HasScope(models.APIKeyScopeUsersWrite))
+	})
+
+	t.Run("Authenticate suspended user", func(t *testing.T) {
+		tokenRepository.EXPECT().Authenticate(gomock.Any(), tokenHash).Return(&models.PersonalAccessToken{TokenID: tokenID, UserID: userID}, nil)
+		userRedisRepository.EXPECT().GetByIdCtx(gomock.Any(), userID.String()).Return(&models.User{UserID: userID, Status: models.UserStatusSuspended, Version: 1}, nil)
+
+		_, _, err := userUC.AuthenticateAccessToken(ctx, token)
+		require.ErrorIs(t, err, domain_errors.ErrUserInactive)
+	})
+
+	t.Run("Authenticate revoked", func(t *testing.T) {
+		tokenRepository.EXPECT().Authenticate(gomock.Any(), tokenHash).Return(nil, sql.ErrNoRows)
+
+		_, _, err := userUC.AuthenticateAccessToken(ctx, token)
+		require.ErrorIs(t, err, domain_errors.ErrInvalidAccessToken)
+	})
+
+	t.Run("Revoke unknown", func(t *testing.T) {
+		tokenRepository.EXPECT().DeleteById(gomock.Any(), userID, tokenID).Return(sql.ErrNoRows)
+
+		err := userUC.RevokeAccessToken(ctx, userID, tokenID)
+		require.ErrorIs(t, err, domain_errors.ErrAccessTokenNotFound)
+	})
+}
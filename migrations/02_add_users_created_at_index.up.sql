@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS users_created_at_user_id_idx ON users (created_at, user_id);
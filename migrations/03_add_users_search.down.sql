@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_role_idx;
+DROP INDEX IF EXISTS users_search_trgm_idx;
+
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS users_search_trgm_idx ON users
+    USING GIN ((first_name || ' ' || last_name || ' ' || email) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_role_idx ON users (role);
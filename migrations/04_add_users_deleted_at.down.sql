@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_deleted_at_idx;
+
+ALTER TABLE users DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
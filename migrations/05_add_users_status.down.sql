@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_status_events;
+
+ALTER TABLE users DROP COLUMN IF EXISTS status;
+
+DROP TYPE IF EXISTS user_status;
@@ -0,0 +1,16 @@
+CREATE TYPE user_status AS ENUM ('pending', 'active', 'suspended', 'locked', 'deactivated');
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status user_status NOT NULL DEFAULT 'active';
+
+CREATE TABLE IF NOT EXISTS user_status_events
+(
+    event_id    UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id     UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    from_status user_status              NOT NULL,
+    to_status   user_status              NOT NULL,
+    reason      VARCHAR(250)             NOT NULL DEFAULT '',
+    actor_id    UUID,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS user_status_events_user_id_idx ON user_status_events (user_id, created_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_verified_phone_number_idx;
+
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_number;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS trusted_devices
+(
+    device_id     UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id       UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    token_hash    VARCHAR(64) UNIQUE       NOT NULL,
+    name          VARCHAR(128)             NOT NULL DEFAULT '',
+    ip            VARCHAR(45)              NOT NULL DEFAULT '',
+    first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_seen_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS trusted_devices_user_id_idx ON trusted_devices (user_id, last_seen_at);
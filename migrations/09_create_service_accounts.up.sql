@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS service_accounts
+(
+    service_account_id UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    name               VARCHAR(64) UNIQUE       NOT NULL CHECK ( name <> '' ),
+    description        VARCHAR(250)             NOT NULL DEFAULT '',
+    created_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    key_id             UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    service_account_id UUID                     NOT NULL REFERENCES service_accounts (service_account_id) ON DELETE CASCADE,
+    prefix             VARCHAR(16)              NOT NULL,
+    key_hash           VARCHAR(64) UNIQUE       NOT NULL,
+    scopes             TEXT[]                   NOT NULL DEFAULT '{}',
+    expires_at         TIMESTAMP WITH TIME ZONE,
+    last_used_at       TIMESTAMP WITH TIME ZONE,
+    revoked_at         TIMESTAMP WITH TIME ZONE,
+    created_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_service_account_id_idx ON api_keys (service_account_id, created_at);
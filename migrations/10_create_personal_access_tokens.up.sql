@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS personal_access_tokens
+(
+    token_id     UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id      UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name         VARCHAR(64)              NOT NULL CHECK ( name <> '' ),
+    prefix       VARCHAR(16)              NOT NULL,
+    token_hash   VARCHAR(64) UNIQUE       NOT NULL,
+    scopes       TEXT[]                   NOT NULL DEFAULT '{}',
+    expires_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS personal_access_tokens_user_id_idx ON personal_access_tokens (user_id, created_at);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS oauth_clients
+(
+    client_id     UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    name          VARCHAR(64)              NOT NULL CHECK ( name <> '' ),
+    client_type   VARCHAR(16)              NOT NULL CHECK ( client_type IN ('public', 'confidential') ),
+    secret_hash   VARCHAR(64)              NOT NULL DEFAULT '',
+    redirect_uris TEXT[]                   NOT NULL CHECK ( cardinality(redirect_uris) > 0 ),
+    scopes        TEXT[]                   NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
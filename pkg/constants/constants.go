@@ -20,8 +20,25 @@ const (
 	REPLY    = "REPLY"
 	TIME     = "TIME"
 
-	Page   = "page"
-	Size   = "size"
-	Search = "search"
-	ID     = "id"
+	Page        = "page"
+	Size        = "size"
+	Search      = "search"
+	ID          = "id"
+	Fields      = "fields"
+	OrderBy     = "orderBy"
+	Role        = "role"
+	CreatedFrom = "created_from"
+	CreatedTo   = "created_to"
+	Verified    = "verified"
+	Cursor      = "cursor"
+
+	IncludeDeleted = "include_deleted"
+
+	HeaderETag               = "ETag"
+	HeaderIfMatch            = "If-Match"
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+	MIMEApplicationJSONPatchJSON  = "application/json-patch+json"
 )
@@ -0,0 +1,296 @@
+package domain_errors
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	validatorV9 "github.com/go-playground/validator"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+)
+
+// Kind category of a domain error, every kind maps to exactly one grpc code and one http status
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindInvalidCredentials
+	KindValidation
+	KindUnauthenticated
+	KindForbidden
+	KindRateLimited
+	KindCanceled
+	KindTimeout
+	KindPreconditionFailed
+)
+
+var kindNames = map[Kind]string{
+	KindInternal:           "Internal",
+	KindNotFound:           "NotFound",
+	KindConflict:           "Conflict",
+	KindInvalidCredentials: "InvalidCredentials",
+	KindValidation:         "Validation",
+	KindUnauthenticated:    "Unauthenticated",
+	KindForbidden:          "Forbidden",
+	KindRateLimited:        "RateLimited",
+	KindCanceled:           "Canceled",
+	KindTimeout:            "Timeout",
+	KindPreconditionFailed: "PreconditionFailed",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Stable reasons clients can switch on
+const (
+	ReasonNotFound           = "NOT_FOUND"
+	ReasonUserNotFound       = "USER_NOT_FOUND"
+	ReasonEmailExists        = "EMAIL_ALREADY_EXISTS"
+	ReasonInvalidCredentials = "INVALID_CREDENTIALS"
+	ReasonMissingMetadata    = "MISSING_METADATA"
+	ReasonInvalidSession     = "INVALID_SESSION"
+	ReasonInvalidToken       = "INVALID_TOKEN"
+	ReasonValidationFailed   = "VALIDATION_FAILED"
+	ReasonUnauthenticated    = "UNAUTHENTICATED"
+	ReasonPermissionDenied   = "PERMISSION_DENIED"
+	ReasonRateLimited        = "RATE_LIMITED"
+	ReasonCanceled           = "CANCELED"
+	ReasonDeadlineExceeded   = "DEADLINE_EXCEEDED"
+	ReasonInternal           = "INTERNAL"
+
+	ReasonAccountInactive         = "ACCOUNT_INACTIVE"
+	ReasonInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+	ReasonVersionMismatch         = "VERSION_MISMATCH"
+	ReasonIdempotencyKeyInUse     = "IDEMPOTENCY_KEY_IN_USE"
+	ReasonIdempotencyKeyReused    = "IDEMPOTENCY_KEY_REUSED"
+	ReasonSignupClosed            = "SIGNUP_CLOSED"
+	ReasonEmailDomainNotAllowed   = "EMAIL_DOMAIN_NOT_ALLOWED"
+	ReasonInvalidInviteCode       = "INVALID_INVITE_CODE"
+	ReasonInvalidVerification     = "INVALID_VERIFICATION_TOKEN"
+	ReasonInvitationNotFound      = "INVITATION_NOT_FOUND"
+	ReasonInvalidInvitation       = "INVALID_INVITATION_TOKEN"
+	ReasonInvalidMagicLink        = "INVALID_MAGIC_LINK"
+	ReasonInvalidCode             = "INVALID_CODE"
+	ReasonStepUpRequired          = "STEP_UP_REQUIRED"
+	ReasonReauthRequired          = "REAUTHENTICATION_REQUIRED"
+	ReasonPhoneNumberExists       = "PHONE_NUMBER_ALREADY_EXISTS"
+	ReasonTrustedDeviceNotFound   = "TRUSTED_DEVICE_NOT_FOUND"
+	ReasonServiceAccountNotFound  = "SERVICE_ACCOUNT_NOT_FOUND"
+	ReasonServiceAccountExists    = "SERVICE_ACCOUNT_ALREADY_EXISTS"
+	ReasonAPIKeyNotFound          = "API_KEY_NOT_FOUND"
+	ReasonInvalidAPIKey           = "INVALID_API_KEY"
+	ReasonInsufficientScope       = "INSUFFICIENT_SCOPE"
+	ReasonAccessTokenNotFound     = "ACCESS_TOKEN_NOT_FOUND"
+	ReasonInvalidAccessToken      = "INVALID_ACCESS_TOKEN"
+	ReasonScopeNotAllowed         = "SCOPE_NOT_ALLOWED"
+	ReasonOAuthClientNotFound     = "OAUTH_CLIENT_NOT_FOUND"
+	ReasonInvalidOAuthClient      = "INVALID_OAUTH_CLIENT"
+	ReasonInvalidRedirectURI      = "INVALID_REDIRECT_URI"
+	ReasonInvalidOAuthScope       = "INVALID_OAUTH_SCOPE"
+	ReasonInvalidGrant            = "INVALID_GRANT"
+	ReasonUnsupportedGrantType    = "UNSUPPORTED_GRANT_TYPE"
+)
+
+var (
+	ErrNotFound           = New(KindNotFound, ReasonNotFound, "Not found")
+	ErrUserNotFound       = New(KindNotFound, ReasonUserNotFound, "User not found")
+	ErrEmailExists        = New(KindConflict, ReasonEmailExists, "Email already exists")
+	ErrInvalidCredentials = New(KindInvalidCredentials, ReasonInvalidCredentials, "Invalid email or password")
+	ErrSessionNotFound    = New(KindUnauthenticated, ReasonInvalidSession, "Session not found")
+	ErrInvalidToken       = New(KindUnauthenticated, ReasonInvalidToken, "Invalid token")
+	ErrUnauthenticated    = New(KindUnauthenticated, ReasonUnauthenticated, "Unauthenticated")
+	ErrForbidden          = New(KindForbidden, ReasonPermissionDenied, "Permission denied")
+	ErrCanceled           = New(KindCanceled, ReasonCanceled, "Request canceled")
+	ErrTimeout            = New(KindTimeout, ReasonDeadlineExceeded, "Request timeout")
+	ErrInternal           = New(KindInternal, ReasonInternal, "Internal error")
+
+	ErrUserInactive            = New(KindForbidden, ReasonAccountInactive, "Account is not active")
+	ErrInvalidStatusTransition = New(KindConflict, ReasonInvalidStatusTransition, "Invalid account status transition")
+	ErrVersionMismatch         = New(KindPreconditionFailed, ReasonVersionMismatch, "User was modified by another request")
+	ErrIdempotencyKeyInUse     = New(KindConflict, ReasonIdempotencyKeyInUse, "A request with this idempotency key is in progress")
+	ErrIdempotencyKeyReused    = New(KindValidation, ReasonIdempotencyKeyReused, "Idempotency key was used with a different request")
+	ErrSignupClosed            = New(KindForbidden, ReasonSignupClosed, "Signup is closed")
+	ErrEmailDomainNotAllowed   = New(KindForbidden, ReasonEmailDomainNotAllowed, "Email domain is not allowed to sign up")
+	ErrInvalidInviteCode       = New(KindForbidden, ReasonInvalidInviteCode, "Invalid invite code")
+	ErrInvalidVerification     = New(KindValidation, ReasonInvalidVerification, "Verification token is invalid or expired")
+	ErrInvitationNotFound      = New(KindNotFound, ReasonInvitationNotFound, "Invitation not found")
+	ErrInvalidInvitation       = New(KindValidation, ReasonInvalidInvitation, "Invitation is invalid or expired")
+	ErrInvalidMagicLink        = New(KindUnauthenticated, ReasonInvalidMagicLink, "Login link is invalid or expired")
+	ErrInvalidCode             = New(KindInvalidCredentials, ReasonInvalidCode, "Invalid or expired code")
+	ErrStepUpRequired          = New(KindForbidden, ReasonStepUpRequired, "Step-up verification required")
+	ErrReauthRequired          = New(KindUnauthenticated, ReasonReauthRequired, "Reauthentication required")
+	ErrPhoneNumberExists       = New(KindConflict, ReasonPhoneNumberExists, "Phone number is already verified by another account")
+	ErrTrustedDeviceNotFound   = New(KindNotFound, ReasonTrustedDeviceNotFound, "Trusted device not found")
+	ErrServiceAccountNotFound  = New(KindNotFound, ReasonServiceAccountNotFound, "Service account not found")
+	ErrServiceAccountExists    = New(KindConflict, ReasonServiceAccountExists, "Service account already exists")
+	ErrAPIKeyNotFound          = New(KindNotFound, ReasonAPIKeyNotFound, "API key not found")
+	ErrInvalidAPIKey           = New(KindUnauthenticated, ReasonInvalidAPIKey, "Invalid, expired or revoked API key")
+	ErrAccessTokenNotFound     = New(KindNotFound, ReasonAccessTokenNotFound, "Personal access token not found")
+	ErrInvalidAccessToken      = New(KindUnauthenticated, ReasonInvalidAccessToken, "Invalid, expired or revoked personal access token")
+	ErrScopeNotAllowed         = New(KindForbidden, ReasonScopeNotAllowed, "Scope exceeds the permissions of the user")
+	ErrOAuthClientNotFound     = New(KindNotFound, ReasonOAuthClientNotFound, "OAuth client not found")
+	ErrInvalidOAuthClient      = New(KindUnauthenticated, ReasonInvalidOAuthClient, "Unknown OAuth client or invalid client credentials")
+	ErrInvalidRedirectURI      = New(KindValidation, ReasonInvalidRedirectURI, "Redirect uri is not registered for the client")
+	ErrInvalidOAuthScope       = New(KindValidation, ReasonInvalidOAuthScope, "Scope is not allowed for the client")
+	ErrInvalidGrant            = New(KindValidation, ReasonInvalidGrant, "Authorization code or refresh token is invalid, expired or issued to another client")
+	ErrUnsupportedGrantType    = New(KindValidation, ReasonUnsupportedGrantType, "Unsupported grant type")
+)
+
+// FieldCodeInvalid code of a field rejected outside of struct validation
+const FieldCodeInvalid = "invalid"
+
+// FieldViolation invalid request field, Code is the failed validation rule
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Code        string `json:"code"`
+	Param       string `json:"param,omitempty"`
+	Description string `json:"description"`
+}
+
+// Error typed domain error returned by use cases
+type Error struct {
+	Kind       Kind
+	Reason     string
+	Message    string
+	Fields     []FieldViolation
+	RetryAfter time.Duration
+	Metadata   map[string]string
+	Err        error
+}
+
+// New domain error constructor
+func New(kind Kind, reason string, message string) *Error {
+	return &Error{Kind: kind, Reason: reason, Message: message}
+}
+
+// Validation error for the invalid fields
+func Validation(fields ...FieldViolation) *Error {
+	return &Error{Kind: KindValidation, Reason: ReasonValidationFailed, Message: "Validation failed", Fields: fields}
+}
+
+// InvalidField validation error of a single field caused by err
+func InvalidField(field string, err error) *Error {
+	return Validation(FieldViolation{Field: field, Code: FieldCodeInvalid, Description: err.Error()}).Wrap(err)
+}
+
+// RateLimited request rejected by a rate limit, retry is allowed after retryAfter
+func RateLimited(retryAfter time.Duration) *Error {
+	return &Error{
+		Kind:       KindRateLimited,
+		Reason:     ReasonRateLimited,
+		Message:    fmt.Sprintf("Rate limit exceeded, retry after %v", retryAfter),
+		RetryAfter: retryAfter,
+	}
+}
+
+// Metadata keys of a reauthentication required error
+const (
+	MetadataMaxAge  = "max_age"
+	MetadataMethods = "methods"
+)
+
+// ReauthRequired request rejected until the user authenticated again within maxAge,
+// with one of methods when any are given
+func ReauthRequired(maxAge time.Duration, methods ...string) *Error {
+	metadata := map[string]string{MetadataMaxAge: strconv.Itoa(int(maxAge.Seconds()))}
+	if len(methods) > 0 {
+		metadata[MetadataMethods] = strings.Join(methods, " ")
+	}
+	return &Error{
+		Kind:     KindUnauthenticated,
+		Reason:   ReasonReauthRequired,
+		Message:  "Reauthentication required",
+		Metadata: metadata,
+	}
+}
+
+// MetadataScope key of the scope an API key is missing
+const MetadataScope = "scope"
+
+// InsufficientScope request rejected because the API key was not granted scope
+func InsufficientScope(scope string) *Error {
+	return &Error{
+		Kind:     KindForbidden,
+		Reason:   ReasonInsufficientScope,
+		Message:  "API key lacks the required scope",
+		Metadata: map[string]string{MetadataScope: scope},
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a domain error of the same kind and reason
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Reason == e.Reason
+}
+
+// Wrap copy of the error caused by err
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// From find the domain error of err, validation and context errors are converted, anything else is internal
+func From(err error) *Error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+
+	var validationErrors validator.ValidationErrors
+	var validationErrorsV9 validatorV9.ValidationErrors
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled.Wrap(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout.Wrap(err)
+	case errors.As(err, &validationErrors):
+		fields := make([]FieldViolation, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			fields = append(fields, fieldViolation(fieldErr.Field(), fieldErr.Tag(), fieldErr.Param()))
+		}
+		return Validation(fields...).Wrap(err)
+	case errors.As(err, &validationErrorsV9):
+		fields := make([]FieldViolation, 0, len(validationErrorsV9))
+		for _, fieldErr := range validationErrorsV9 {
+			fields = append(fields, fieldViolation(fieldErr.Field(), fieldErr.Tag(), fieldErr.Param()))
+		}
+		return Validation(fields...).Wrap(err)
+	}
+
+	return ErrInternal.Wrap(err)
+}
+
+func fieldViolation(field string, tag string, param string) FieldViolation {
+	return FieldViolation{
+		Field:       field,
+		Code:        tag,
+		Param:       param,
+		Description: fmt.Sprintf("Field validation for '%s' failed on the '%s' tag", field, tag),
+	}
+}
+
+// KindOf kind of the domain error of err
+func KindOf(err error) Kind {
+	return From(err).Kind
+}
@@ -0,0 +1,97 @@
+package domain_errors_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/dinorain/useraja/pkg/domain_errors"
+	"github.com/dinorain/useraja/pkg/grpc_errors"
+	httpErrors "github.com/dinorain/useraja/pkg/http_errors"
+)
+
+func TestErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	validationErr := validator.New().Struct(struct {
+		Email string `validate:"required,email"`
+	}{Email: "invalid"})
+	require.Error(t, validationErr)
+
+	golden := []struct {
+		name       string
+		err        error
+		kind       domain_errors.Kind
+		reason     string
+		grpcCode   codes.Code
+		httpStatus int
+	}{
+		{"user not found", errors.Wrap(domain_errors.ErrUserNotFound.Wrap(sql.ErrNoRows), "userPgRepo.FindById"), domain_errors.KindNotFound, domain_errors.ReasonUserNotFound, codes.NotFound, http.StatusNotFound},
+		{"email exists", domain_errors.ErrEmailExists, domain_errors.KindConflict, domain_errors.ReasonEmailExists, codes.AlreadyExists, http.StatusConflict},
+		{"invalid credentials", domain_errors.ErrInvalidCredentials.Wrap(errors.New("bcrypt: hashedPassword is not the hash of the given password")), domain_errors.KindInvalidCredentials, domain_errors.ReasonInvalidCredentials, codes.Unauthenticated, http.StatusUnauthorized},
+		{"session not found", domain_errors.ErrSessionNotFound.Wrap(redis.Nil), domain_errors.KindUnauthenticated, domain_errors.ReasonInvalidSession, codes.Unauthenticated, http.StatusUnauthorized},
+		{"invalid token", domain_errors.ErrInvalidToken, domain_errors.KindUnauthenticated, domain_errors.ReasonInvalidToken, codes.Unauthenticated, http.StatusUnauthorized},
+		{"missing metadata", grpc_errors.ErrNoCtxMetaData, domain_errors.KindUnauthenticated, domain_errors.ReasonMissingMetadata, codes.Unauthenticated, http.StatusUnauthorized},
+		{"forbidden", domain_errors.ErrForbidden, domain_errors.KindForbidden, domain_errors.ReasonPermissionDenied, codes.PermissionDenied, http.StatusForbidden},
+		{"account inactive", domain_errors.ErrUserInactive, domain_errors.KindForbidden, domain_errors.ReasonAccountInactive, codes.PermissionDenied, http.StatusForbidden},
+		{"invalid status transition", domain_errors.ErrInvalidStatusTransition, domain_errors.KindConflict, domain_errors.ReasonInvalidStatusTransition, codes.AlreadyExists, http.StatusConflict},
+		{"version mismatch", domain_errors.ErrVersionMismatch.Wrap(sql.ErrNoRows), domain_errors.KindPreconditionFailed, domain_errors.ReasonVersionMismatch, codes.FailedPrecondition, http.StatusPreconditionFailed},
+		{"idempotency key in use", domain_errors.ErrIdempotencyKeyInUse, domain_errors.KindConflict, domain_errors.ReasonIdempotencyKeyInUse, codes.AlreadyExists, http.StatusConflict},
+		{"idempotency key reused", domain_errors.ErrIdempotencyKeyReused, domain_errors.KindValidation, domain_errors.ReasonIdempotencyKeyReused, codes.InvalidArgument, http.StatusBadRequest},
+		{"signup closed", domain_errors.ErrSignupClosed, domain_errors.KindForbidden, domain_errors.ReasonSignupClosed, codes.PermissionDenied, http.StatusForbidden},
+		{"email domain not allowed", domain_errors.ErrEmailDomainNotAllowed, domain_errors.KindForbidden, domain_errors.ReasonEmailDomainNotAllowed, codes.PermissionDenied, http.StatusForbidden},
+		{"invalid invite code", domain_errors.ErrInvalidInviteCode, domain_errors.KindForbidden, domain_errors.ReasonInvalidInviteCode, codes.PermissionDenied, http.StatusForbidden},
+		{"invitation not found", domain_errors.ErrInvitationNotFound, domain_errors.KindNotFound, domain_errors.ReasonInvitationNotFound, codes.NotFound, http.StatusNotFound},
+		{"invalid invitation token", domain_errors.ErrInvalidInvitation, domain_errors.KindValidation, domain_errors.ReasonInvalidInvitation, codes.InvalidArgument, http.StatusBadRequest},
+		{"invalid magic link", domain_errors.ErrInvalidMagicLink, domain_errors.KindUnauthenticated, domain_errors.ReasonInvalidMagicLink, codes.Unauthenticated, http.StatusUnauthorized},
+		{"invalid code", domain_errors.ErrInvalidCode, domain_errors.KindInvalidCredentials, domain_errors.ReasonInvalidCode, codes.Unauthenticated, http.StatusUnauthorized},
+		{"phone number exists", domain_errors.ErrPhoneNumberExists, domain_errors.KindConflict, domain_errors.ReasonPhoneNumberExists, codes.AlreadyExists, http.StatusConflict},
+		{"trusted device not found", domain_errors.ErrTrustedDeviceNotFound, domain_errors.KindNotFound, domain_errors.ReasonTrustedDeviceNotFound, codes.NotFound, http.StatusNotFound},
+		{"service account not found", domain_errors.ErrServiceAccountNotFound, domain_errors.KindNotFound, domain_errors.ReasonServiceAccountNotFound, codes.NotFound, http.StatusNotFound},
+		{"service account exists", domain_errors.ErrServiceAccountExists, domain_errors.KindConflict, domain_errors.ReasonServiceAccountExists, codes.AlreadyExists, http.StatusConflict},
+		{"api key not found", domain_errors.ErrAPIKeyNotFound, domain_errors.KindNotFound, domain_errors.ReasonAPIKeyNotFound, codes.NotFound, http.StatusNotFound},
+		{"invalid api key", domain_errors.ErrInvalidAPIKey, domain_errors.KindUnauthenticated, domain_errors.ReasonInvalidAPIKey, codes.Unauthenticated, http.StatusUnauthorized},
+		{"insufficient scope", domain_errors.InsufficientScope("users:read"), domain_errors.KindForbidden, domain_errors.ReasonInsufficientScope, codes.PermissionDenied, http.StatusForbidden},
+		{"access token not found", domain_errors.ErrAccessTokenNotFound, domain_errors.KindNotFound, domain_errors.ReasonAccessTokenNotFound, codes.NotFound, http.StatusNotFound},
+		{"invalid access token", domain_errors.ErrInvalidAccessToken, domain_errors.KindUnauthenticated, domain_errors.ReasonInvalidAccessToken, codes.Unauthenticated, http.StatusUnauthorized},
+		{"scope not allowed", domain_errors.ErrScopeNotAllowed, domain_errors.KindForbidden, domain_errors.ReasonScopeNotAllowed, codes.PermissionDenied, http.StatusForbidden},
+		{"oauth client not found", domain_errors.ErrOAuthClientNotFound, domain_errors.KindNotFound, domain_errors.ReasonOAuthClientNotFound, codes.NotFound, http.StatusNotFound},
+		{"invalid oauth client", domain_errors.ErrInvalidOAuthClient, domain_errors.KindUnauthenticated, domain_errors.ReasonInvalidOAuthClient, codes.Unauthenticated, http.StatusUnauthorized},
+		{"invalid redirect uri", domain_errors.ErrInvalidRedirectURI, domain_errors.KindValidation, domain_errors.ReasonInvalidRedirectURI, codes.InvalidArgument, http.StatusBadRequest},
+		{"invalid oauth scope", domain_errors.ErrInvalidOAuthScope, domain_errors.KindValidation, domain_errors.ReasonInvalidOAuthScope, codes.InvalidArgument, http.StatusBadRequest},
+		{"invalid grant", domain_errors.ErrInvalidGrant, domain_errors.KindValidation, domain_errors.ReasonInvalidGrant, codes.InvalidArgument, http.StatusBadRequest},
+		{"unsupported grant type", domain_errors.ErrUnsupportedGrantType, domain_errors.KindValidation, domain_errors.ReasonUnsupportedGrantType, codes.InvalidArgument, http.StatusBadRequest},
+		{"step up required", domain_errors.ErrStepUpRequired, domain_errors.KindForbidden, domain_errors.ReasonStepUpRequired, codes.PermissionDenied, http.StatusForbidden},
+		{"invalid verification token", domain_errors.ErrInvalidVerification, domain_errors.KindValidation, domain_errors.ReasonInvalidVerification, codes.InvalidArgument, http.StatusBadRequest},
+		{"invalid field", domain_errors.InvalidField("uuid", errors.New("invalid UUID length: 3")), domain_errors.KindValidation, domain_errors.ReasonValidationFailed, codes.InvalidArgument, http.StatusBadRequest},
+		{"validator errors", errors.Wrap(validationErr, "ValidateStruct"), domain_errors.KindValidation, domain_errors.ReasonValidationFailed, codes.InvalidArgument, http.StatusBadRequest},
+		{"reauthentication required", domain_errors.ReauthRequired(5*time.Minute, "pwd"), domain_errors.KindUnauthenticated, domain_errors.ReasonReauthRequired, codes.Unauthenticated, http.StatusUnauthorized},
+		{"rate limited", domain_errors.RateLimited(time.Minute), domain_errors.KindRateLimited, domain_errors.ReasonRateLimited, codes.ResourceExhausted, http.StatusTooManyRequests},
+		{"canceled", errors.Wrap(context.Canceled, "userPgRepo.FindAll"), domain_errors.KindCanceled, domain_errors.ReasonCanceled, codes.Canceled, http.StatusRequestTimeout},
+		{"deadline exceeded", context.DeadlineExceeded, domain_errors.KindTimeout, domain_errors.ReasonDeadlineExceeded, codes.DeadlineExceeded, http.StatusGatewayTimeout},
+		{"untyped sql error", sql.ErrNoRows, domain_errors.KindInternal, domain_errors.ReasonInternal, codes.Internal, http.StatusInternalServerError},
+		{"untyped redis error", errors.Wrap(redis.Nil, "redisClient.Get"), domain_errors.KindInternal, domain_errors.ReasonInternal, codes.Internal, http.StatusInternalServerError},
+		{"untyped token error", errors.New("token is expired"), domain_errors.KindInternal, domain_errors.ReasonInternal, codes.Internal, http.StatusInternalServerError},
+	}
+
+	for _, tc := range golden {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.kind, domain_errors.KindOf(tc.err))
+			require.Equal(t, tc.reason, grpc_errors.ParseGRPCErrReason(tc.err))
+			require.Equal(t, tc.grpcCode, grpc_errors.ParseGRPCErrStatusCode(tc.err))
+			require.Equal(t, tc.httpStatus, httpErrors.ParseErrors(tc.err, false).Status())
+			require.Equal(t, tc.httpStatus, grpc_errors.MapGRPCErrCodeToHttpStatus(tc.grpcCode))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package grpc_errors
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/dinorain/useraja/pkg/domain_errors"
+)
+
+// ErrorDomain google.rpc.ErrorInfo domain of every error returned by the service
+const ErrorDomain = "useraja"
+
+// ErrorResponse build a grpc status error for err with google.rpc error details.
+// Without debug the internal cause is hidden from the message of internal errors.
+func ErrorResponse(err error, msg string, debug bool) error {
+	cause := err.Error()
+	if st, ok := grpcStatus(err); ok {
+		if len(st.Details()) > 0 {
+			return st.Err()
+		}
+		cause = st.Message()
+	}
+
+	code := ParseGRPCErrStatusCode(err)
+	message := fmt.Sprintf("%s: %s", msg, cause)
+	if !debug && isInternalCode(code) {
+		message = fmt.Sprintf("%s: %s", msg, code.String())
+	}
+
+	st := status.New(code, message)
+	withDetails, detailsErr := st.WithDetails(errorDetails(err)...)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// ParseGRPCErrReason get stable google.rpc.ErrorInfo reason of err
+func ParseGRPCErrReason(err error) string {
+	if st, ok := grpcStatus(err); ok {
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok {
+				return info.GetReason()
+			}
+		}
+		return domain_errors.ReasonInternal
+	}
+	return domain_errors.From(err).Reason
+}
+
+func errorDetails(err error) []protoiface.MessageV1 {
+	domainErr := domain_errors.From(err)
+	details := []protoiface.MessageV1{&errdetails.ErrorInfo{
+		Reason:   domainErr.Reason,
+		Domain:   ErrorDomain,
+		Metadata: domainErr.Metadata,
+	}}
+
+	if len(domainErr.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range domainErr.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Description,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	if domainErr.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(domainErr.RetryAfter)})
+	}
+
+	return details
+}
+
+func grpcStatus(err error) (*status.Status, bool) {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		return grpcErr.GRPCStatus(), true
+	}
+	return nil, false
+}
+
+func isInternalCode(code codes.Code) bool {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		return true
+	}
+	return false
+}
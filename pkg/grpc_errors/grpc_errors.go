@@ -1,44 +1,47 @@
 package grpc_errors
 
 import (
-	"context"
-	"database/sql"
 	"net/http"
-	"strings"
 
-	"github.com/go-redis/redis/v8"
-	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
+
+	"github.com/dinorain/useraja/pkg/domain_errors"
 )
 
 var (
-	ErrNotFound         = errors.New("Not found")
-	ErrNoCtxMetaData    = errors.New("No ctx metadata")
-	ErrInvalidSessionId = errors.New("Invalid session id")
-	ErrEmailExists      = errors.New("Email already exists")
+	ErrNoCtxMetaData    = domain_errors.New(domain_errors.KindUnauthenticated, domain_errors.ReasonMissingMetadata, "No ctx metadata")
+	ErrInvalidSessionId = domain_errors.New(domain_errors.KindUnauthenticated, domain_errors.ReasonInvalidSession, "Invalid session id")
 )
 
 // Parse error and get code
 func ParseGRPCErrStatusCode(err error) codes.Code {
-	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		return codes.NotFound
-	case errors.Is(err, redis.Nil):
+	if st, ok := grpcStatus(err); ok {
+		return st.Code()
+	}
+	return MapKindToGRPCCode(domain_errors.KindOf(err))
+}
+
+// MapKindToGRPCCode grpc code of a domain error kind
+func MapKindToGRPCCode(kind domain_errors.Kind) codes.Code {
+	switch kind {
+	case domain_errors.KindNotFound:
 		return codes.NotFound
-	case errors.Is(err, context.Canceled):
-		return codes.Canceled
-	case errors.Is(err, context.DeadlineExceeded):
-		return codes.DeadlineExceeded
-	case errors.Is(err, ErrEmailExists):
+	case domain_errors.KindConflict:
 		return codes.AlreadyExists
-	case errors.Is(err, ErrNoCtxMetaData):
+	case domain_errors.KindInvalidCredentials, domain_errors.KindUnauthenticated:
 		return codes.Unauthenticated
-	case errors.Is(err, ErrInvalidSessionId):
-		return codes.PermissionDenied
-	case strings.Contains(err.Error(), "Validate"):
+	case domain_errors.KindValidation:
 		return codes.InvalidArgument
-	case strings.Contains(err.Error(), "redis"):
-		return codes.NotFound
+	case domain_errors.KindForbidden:
+		return codes.PermissionDenied
+	case domain_errors.KindRateLimited:
+		return codes.ResourceExhausted
+	case domain_errors.KindCanceled:
+		return codes.Canceled
+	case domain_errors.KindTimeout:
+		return codes.DeadlineExceeded
+	case domain_errors.KindPreconditionFailed:
+		return codes.FailedPrecondition
 	}
 	return codes.Internal
 }
@@ -49,7 +52,7 @@ func MapGRPCErrCodeToHttpStatus(code codes.Code) int {
 	case codes.Unauthenticated:
 		return http.StatusUnauthorized
 	case codes.AlreadyExists:
-		return http.StatusBadRequest
+		return http.StatusConflict
 	case codes.NotFound:
 		return http.StatusNotFound
 	case codes.Internal:
@@ -62,6 +65,14 @@ func MapGRPCErrCodeToHttpStatus(code codes.Code) int {
 		return http.StatusGatewayTimeout
 	case codes.InvalidArgument:
 		return http.StatusBadRequest
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
 	}
 	return http.StatusInternalServerError
 }
@@ -1,19 +1,16 @@
 package httpErrors
 
 import (
-	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/pkg/errors"
 
-	"github.com/go-playground/validator"
-	"github.com/labstack/echo/v4"
+	"github.com/dinorain/useraja/pkg/domain_errors"
 )
 
 const (
@@ -174,64 +171,71 @@ func NewInternalServerError(ctx echo.Context, causes interface{}, debug bool) er
 	return ctx.JSON(http.StatusInternalServerError, restError)
 }
 
-// ParseErrors Parser of error string messages returns RestError
+// ParseErrors map err to RestError by the kind of its domain error
 func ParseErrors(err error, debug bool) RestErr {
+	if restErr, ok := err.(RestErr); ok {
+		return restErr
+	}
+
+	if errors.Is(err, middleware.ErrJWTMissing) {
+		err = domain_errors.ErrUnauthenticated.Wrap(err)
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return NewRestError(httpErr.Code, statusTitle(httpErr.Code), httpErr.Message, debug)
+	}
+
+	domainErr := domain_errors.From(err)
+	status := MapKindToHttpStatus(domainErr.Kind)
 	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		return NewRestError(http.StatusNotFound, ErrNotFound, err.Error(), debug)
-	case errors.Is(err, context.DeadlineExceeded):
-		return NewRestError(http.StatusRequestTimeout, ErrRequestTimeout, err.Error(), debug)
-	case errors.Is(err, Unauthorized):
-		return NewRestError(http.StatusUnauthorized, ErrUnauthorized, err.Error(), debug)
-	case errors.Is(err, WrongCredentials):
-		return NewRestError(http.StatusUnauthorized, ErrUnauthorized, err.Error(), debug)
-	case errors.Is(err, middleware.ErrJWTMissing):
-		return NewRestError(http.StatusUnauthorized, ErrUnauthorized, err.Error(), debug)
-	case strings.Contains(strings.ToLower(err.Error()), "sqlstate"):
-		return parseSqlErrors(err, debug)
-	case strings.Contains(strings.ToLower(err.Error()), "field validation"):
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			return NewRestError(http.StatusBadRequest, ErrBadRequest, validationErrors.Error(), debug)
-		}
-		return parseValidatorError(err, debug)
-	case strings.Contains(strings.ToLower(err.Error()), "required header"):
-		return NewRestError(http.StatusBadRequest, ErrBadRequest, err.Error(), debug)
-	case strings.Contains(strings.ToLower(err.Error()), "base64"):
-		return NewRestError(http.StatusBadRequest, ErrBadRequest, err.Error(), debug)
-	case strings.Contains(strings.ToLower(err.Error()), "unmarshal"):
-		return NewRestError(http.StatusBadRequest, ErrBadRequest, err.Error(), debug)
-	case strings.Contains(strings.ToLower(err.Error()), "uuid"):
-		return NewRestError(http.StatusBadRequest, ErrBadRequest, err.Error(), debug)
-	case strings.Contains(strings.ToLower(err.Error()), "cookie"):
-		return NewRestError(http.StatusUnauthorized, ErrUnauthorized, err.Error(), debug)
-	case strings.Contains(strings.ToLower(err.Error()), "token"):
-		return NewRestError(http.StatusUnauthorized, ErrUnauthorized, err.Error(), debug)
-	case strings.Contains(strings.ToLower(err.Error()), "bcrypt"):
-		return NewRestError(http.StatusBadRequest, ErrBadRequest, err.Error(), debug)
-	case strings.Contains(strings.ToLower(err.Error()), "no documents in result"):
-		return NewRestError(http.StatusNotFound, ErrNotFound, err.Error(), debug)
-	default:
-		if restErr, ok := err.(*RestError); ok {
-			return restErr
-		}
-		return NewRestError(http.StatusInternalServerError, ErrInternalServerError, errors.Cause(err).Error(), debug)
-	}
-}
-
-func parseSqlErrors(err error, debug bool) RestErr {
-	return NewRestError(http.StatusBadRequest, ErrBadRequest, err, debug)
-}
-
-func parseValidatorError(err error, debug bool) RestErr {
-	if strings.Contains(err.Error(), "Password") {
-		return NewRestError(http.StatusBadRequest, ErrInvalidPassword, err, debug)
-	}
-
-	if strings.Contains(err.Error(), "Email") {
-		return NewRestError(http.StatusBadRequest, ErrInvalidEmail, err, debug)
-	}
-
-	return NewRestError(http.StatusBadRequest, ErrInvalidField, err, debug)
+	case len(domainErr.Fields) > 0:
+		return NewRestError(status, statusTitle(status), domainErr.Fields, debug)
+	case domainErr.Kind == domain_errors.KindInternal:
+		return NewRestError(status, statusTitle(status), errors.Cause(err).Error(), debug)
+	}
+	return NewRestError(status, statusTitle(status), err.Error(), debug)
+}
+
+func statusTitle(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusRequestTimeout:
+		return ErrRequestTimeout
+	case http.StatusInternalServerError:
+		return ErrInternalServerError
+	}
+	return http.StatusText(status)
+}
+
+// MapKindToHttpStatus http status of a domain error kind
+func MapKindToHttpStatus(kind domain_errors.Kind) int {
+	switch kind {
+	case domain_errors.KindNotFound:
+		return http.StatusNotFound
+	case domain_errors.KindConflict:
+		return http.StatusConflict
+	case domain_errors.KindInvalidCredentials, domain_errors.KindUnauthenticated:
+		return http.StatusUnauthorized
+	case domain_errors.KindValidation:
+		return http.StatusBadRequest
+	case domain_errors.KindForbidden:
+		return http.StatusForbidden
+	case domain_errors.KindRateLimited:
+		return http.StatusTooManyRequests
+	case domain_errors.KindCanceled:
+		return http.StatusRequestTimeout
+	case domain_errors.KindTimeout:
+		return http.StatusGatewayTimeout
+	case domain_errors.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
 }
 
 // ErrorResponse Error response
@@ -239,8 +243,24 @@ func ErrorResponse(err error, debug bool) (int, interface{}) {
 	return ParseErrors(err, debug).Status(), ParseErrors(err, debug)
 }
 
-// ErrorCtxResponse Error response object and status code
+// ErrorCtxResponse Error response object and status code, problem+json when enabled for the request
 func ErrorCtxResponse(ctx echo.Context, err error, debug bool) error {
+	setAuthenticateHeader(ctx, err)
+	if format, ok := ctx.Get(ErrorFormatKey).(string); ok && format == ErrorFormatProblem {
+		return ProblemCtxResponse(ctx, err, debug)
+	}
 	restErr := ParseErrors(err, debug)
 	return ctx.JSON(restErr.Status(), restErr)
 }
+
+// setAuthenticateHeader challenge the client to authenticate again when err requires a recent authentication, see RFC 9470
+func setAuthenticateHeader(ctx echo.Context, err error) {
+	domainErr := domain_errors.From(err)
+	if domainErr.Reason != domain_errors.ReasonReauthRequired {
+		return
+	}
+	ctx.Response().Header().Set(
+		echo.HeaderWWWAuthenticate,
+		fmt.Sprintf(`Bearer error="insufficient_user_authentication", max_age=%s`, domainErr.Metadata[domain_errors.MetadataMaxAge]),
+	)
+}
@@ -0,0 +1,89 @@
+package httpErrors
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// catalog localized problem titles and details by code, and field messages by validation rule
+type catalog struct {
+	Problems map[string]struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"problems"`
+	Fields map[string]string `json:"fields"`
+}
+
+var (
+	catalogs      = map[language.Tag]*catalog{}
+	supportedTags []language.Tag
+	matcher       language.Matcher
+)
+
+// English goes first, it is the fallback of the matcher
+func init() {
+	supportedTags = []language.Tag{language.English}
+	catalogs[language.English] = mustLoadCatalog("en.json")
+
+	entries, err := localesFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "en.json" {
+			continue
+		}
+		tag := language.MustParse(strings.TrimSuffix(name, path.Ext(name)))
+		catalogs[tag] = mustLoadCatalog(name)
+		supportedTags = append(supportedTags, tag)
+	}
+
+	matcher = language.NewMatcher(supportedTags)
+}
+
+func mustLoadCatalog(name string) *catalog {
+	data, err := localesFS.ReadFile(path.Join("locales", name))
+	if err != nil {
+		panic(err)
+	}
+	c := &catalog{}
+	if err := json.Unmarshal(data, c); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// matchLanguage best supported language for an Accept-Language header
+func matchLanguage(acceptLanguage string) language.Tag {
+	desired, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := matcher.Match(desired...)
+	return supportedTags[index]
+}
+
+// problemText localized title and detail of a problem code, empty when the catalogs miss it
+func problemText(tag language.Tag, code string) (title string, detail string) {
+	for _, c := range []*catalog{catalogs[tag], catalogs[language.English]} {
+		if text, ok := c.Problems[code]; ok {
+			return text.Title, text.Detail
+		}
+	}
+	return "", ""
+}
+
+// fieldText localized message of a failed field validation rule, empty when the catalogs miss it
+func fieldText(tag language.Tag, code string, param string) string {
+	for _, c := range []*catalog{catalogs[tag], catalogs[language.English]} {
+		if text, ok := c.Fields[code]; ok {
+			return strings.ReplaceAll(text, "{param}", param)
+		}
+	}
+	return ""
+}
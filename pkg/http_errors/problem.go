@@ -0,0 +1,140 @@
+package httpErrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/pkg/domain_errors"
+)
+
+const (
+	// ErrorFormatKey echo context key of the error response format
+	ErrorFormatKey = "errorFormat"
+	// ErrorFormatProblem RFC 7807 application/problem+json error responses
+	ErrorFormatProblem = "problem"
+
+	MIMEApplicationProblemJSON = "application/problem+json"
+
+	problemTypePrefix = "urn:useraja:problem:"
+)
+
+// Problem RFC 7807 problem details
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Errors   []ProblemField    `json:"errors,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Debug    string            `json:"debug,omitempty"`
+}
+
+// ProblemField invalid field of a validation problem
+type ProblemField struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// NewProblem build problem details of err localized for acceptLanguage, instance is the request id
+func NewProblem(err error, acceptLanguage string, instance string, debug bool) Problem {
+	tag := matchLanguage(acceptLanguage)
+
+	var status int
+	var code, detail string
+	var fields []domain_errors.FieldViolation
+	var metadata map[string]string
+
+	var httpErr *echo.HTTPError
+	if errors.Is(err, middleware.ErrJWTMissing) {
+		err = domain_errors.ErrUnauthenticated.Wrap(err)
+	}
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+		code = statusCode(status)
+		if message, ok := httpErr.Message.(string); ok {
+			detail = message
+		}
+	} else {
+		domainErr := domain_errors.From(err)
+		status = MapKindToHttpStatus(domainErr.Kind)
+		code = domainErr.Reason
+		fields = domainErr.Fields
+		metadata = domainErr.Metadata
+	}
+
+	title, localizedDetail := problemText(tag, code)
+	if title == "" {
+		title = http.StatusText(status)
+	}
+	if localizedDetail != "" {
+		detail = localizedDetail
+	}
+
+	problem := Problem{
+		Type:     problemTypePrefix + strings.ToLower(strings.ReplaceAll(code, "_", "-")),
+		Title:    title,
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+		Code:     code,
+		Metadata: metadata,
+	}
+	for _, field := range fields {
+		fieldDetail := fieldText(tag, field.Code, field.Param)
+		if fieldDetail == "" {
+			fieldDetail = field.Description
+		}
+		problem.Errors = append(problem.Errors, ProblemField{Field: field.Field, Code: field.Code, Detail: fieldDetail})
+	}
+	if debug {
+		problem.Debug = err.Error()
+	}
+
+	return problem
+}
+
+// ProblemCtxResponse write err as application/problem+json
+func ProblemCtxResponse(ctx echo.Context, err error, debug bool) error {
+	req := ctx.Request()
+	res := ctx.Response()
+
+	requestID := res.Header().Get(echo.HeaderXRequestID)
+	if requestID == "" {
+		requestID = req.Header.Get(echo.HeaderXRequestID)
+	}
+
+	problem := NewProblem(err, req.Header.Get("Accept-Language"), requestID, debug)
+	body, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	res.Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+	res.Header().Set("Content-Language", matchLanguage(req.Header.Get("Accept-Language")).String())
+	if problem.Status == http.StatusTooManyRequests {
+		if retryAfter := domain_errors.From(err).RetryAfter; retryAfter > 0 {
+			res.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		}
+	}
+	res.WriteHeader(problem.Status)
+	_, writeErr := res.Write(body)
+	return writeErr
+}
+
+// statusCode stable code of a plain http status, e.g. METHOD_NOT_ALLOWED
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return domain_errors.ReasonInternal
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
@@ -0,0 +1,106 @@
+package httpErrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/pkg/domain_errors"
+)
+
+func TestNewProblem(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Localized", func(t *testing.T) {
+		t.Parallel()
+
+		problem := NewProblem(domain_errors.ErrUserNotFound, "id", "request-id", false)
+		require.Equal(t, http.StatusNotFound, problem.Status)
+		require.Equal(t, domain_errors.ReasonUserNotFound, problem.Code)
+		require.Equal(t, "urn:useraja:problem:user-not-found", problem.Type)
+		require.Equal(t, "Pengguna tidak ditemukan", problem.Title)
+		require.Equal(t, "request-id", problem.Instance)
+	})
+
+	t.Run("English fallback", func(t *testing.T) {
+		t.Parallel()
+
+		problem := NewProblem(domain_errors.ErrEmailExists, "fr-FR", "", false)
+		require.Equal(t, http.StatusConflict, problem.Status)
+		require.Equal(t, "Email already exists", problem.Title)
+	})
+
+	t.Run("Internal cause hidden", func(t *testing.T) {
+		t.Parallel()
+
+		err := errors.New("pq: connection refused")
+		problem := NewProblem(err, "", "", false)
+		require.Equal(t, http.StatusInternalServerError, problem.Status)
+		require.Equal(t, domain_errors.ReasonInternal, problem.Code)
+		require.NotContains(t, problem.Detail, "pq")
+		require.Empty(t, problem.Debug)
+
+		problem = NewProblem(err, "", "", true)
+		require.Equal(t, err.Error(), problem.Debug)
+	})
+
+	t.Run("Field errors", func(t *testing.T) {
+		t.Parallel()
+
+		problem := NewProblem(domain_errors.Validation(domain_errors.FieldViolation{Field: "first_name", Code: "lte", Param: "30"}), "en", "", false)
+		require.Equal(t, http.StatusBadRequest, problem.Status)
+		require.Equal(t, []ProblemField{{Field: "first_name", Code: "lte", Detail: "The value must be at most 30 characters long."}}, problem.Errors)
+	})
+
+	t.Run("Echo http error", func(t *testing.T) {
+		t.Parallel()
+
+		problem := NewProblem(echo.ErrMethodNotAllowed, "", "", false)
+		require.Equal(t, http.StatusMethodNotAllowed, problem.Status)
+		require.Equal(t, "METHOD_NOT_ALLOWED", problem.Code)
+	})
+}
+
+func TestErrorCtxResponse_Problem(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	res := httptest.NewRecorder()
+	ctx := e.NewContext(req, res)
+	ctx.Set(ErrorFormatKey, ErrorFormatProblem)
+
+	require.NoError(t, ErrorCtxResponse(ctx, domain_errors.RateLimited(time.Minute), false))
+	require.Equal(t, http.StatusTooManyRequests, res.Code)
+	require.Equal(t, MIMEApplicationProblemJSON, res.Header().Get(echo.HeaderContentType))
+	require.Equal(t, "60", res.Header().Get("Retry-After"))
+
+	problem := Problem{}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &problem))
+	require.Equal(t, domain_errors.ReasonRateLimited, problem.Code)
+}
+
+func TestErrorCtxResponse_ReauthRequired(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	res := httptest.NewRecorder()
+	ctx := e.NewContext(req, res)
+	ctx.Set(ErrorFormatKey, ErrorFormatProblem)
+
+	require.NoError(t, ErrorCtxResponse(ctx, domain_errors.ReauthRequired(5*time.Minute, "pwd", "otp"), false))
+	require.Equal(t, http.StatusUnauthorized, res.Code)
+	require.Equal(t, `Bearer error="insufficient_user_authentication", max_age=300`, res.Header().Get(echo.HeaderWWWAuthenticate))
+
+	problem := Problem{}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &problem))
+	require.Equal(t, domain_errors.ReasonReauthRequired, problem.Code)
+	require.Equal(t, map[string]string{"max_age": "300", "methods": "pwd otp"}, problem.Metadata)
+}
@@ -38,9 +38,9 @@ type appLogger struct {
 
 var _ Logger = (*appLogger)(nil)
 
-// App Logger constructor
+// App Logger constructor, logs are discarded until InitLogger is called
 func NewAppLogger(cfg *config.Config) *appLogger {
-	return &appLogger{cfg: cfg}
+	return &appLogger{cfg: cfg, sugarLogger: zap.NewNop().Sugar()}
 }
 
 // For mapping config logger to app logger levels
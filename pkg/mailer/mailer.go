@@ -0,0 +1,73 @@
+//go:generate mockgen -source mailer.go -destination mock/mailer.go -package mock
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+const DriverSMTP = "smtp"
+
+// Message plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Returns the mailer of the configured driver, messages are only logged unless the driver is smtp
+func NewMailer(cfg *config.Config, logger logger.Logger) Mailer {
+	if cfg.Mailer.Driver == DriverSMTP {
+		return &smtpMailer{cfg: cfg}
+	}
+	return &logMailer{logger: logger}
+}
+
+type smtpMailer struct {
+	cfg *config.Config
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	addr := net.JoinHostPort(m.cfg.Mailer.Host, strconv.Itoa(m.cfg.Mailer.Port))
+
+	var auth smtp.Auth
+	if m.cfg.Mailer.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Mailer.Username, m.cfg.Mailer.Password, m.cfg.Mailer.Host)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", m.cfg.Mailer.From)
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	body.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(msg.Body)
+
+	if err := smtp.SendMail(addr, auth, m.cfg.Mailer.From, []string{msg.To}, []byte(body.String())); err != nil {
+		return errors.Wrap(err, "smtp.SendMail")
+	}
+	return nil
+}
+
+// logMailer development mailer writing messages to the log
+type logMailer struct {
+	logger logger.Logger
+}
+
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	m.logger.Infof("mailer: to: %s, subject: %s, body: %s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,107 @@
+//go:generate mockgen -source sms.go -destination mock/sms.go -package mock
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+const DriverHTTP = "http"
+
+// Message text message to an E.164 phone number
+type Message struct {
+	To   string
+	Body string
+}
+
+// SMSSender sends text messages
+type SMSSender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Returns the sender of the configured driver, messages are appended to FilePath or logged unless the driver is http
+func NewSMSSender(cfg *config.Config, logger logger.Logger) SMSSender {
+	if cfg.SMS.Driver == DriverHTTP {
+		return &httpSender{cfg: cfg, client: &http.Client{Timeout: time.Duration(cfg.SMS.Timeout) * time.Second}}
+	}
+	return &localSender{cfg: cfg, logger: logger}
+}
+
+// providerRequest body posted to the http provider
+type providerRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+// httpSender posts messages as json to the ProviderURL of an sms gateway
+type httpSender struct {
+	cfg    *config.Config
+	client *http.Client
+}
+
+func (s *httpSender) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(&providerRequest{From: s.cfg.SMS.From, To: msg.To, Body: msg.Body})
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.SMS.ProviderURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "http.NewRequestWithContext")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.SMS.ProviderToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.SMS.ProviderToken)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "client.Do")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.Errorf("sms provider responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// localSender development sender appending messages to FilePath, or writing them to the log without it
+type localSender struct {
+	cfg    *config.Config
+	logger logger.Logger
+	mu     sync.Mutex
+}
+
+func (s *localSender) Send(ctx context.Context, msg *Message) error {
+	if s.cfg.SMS.FilePath == "" {
+		s.logger.Infof("sms: to: %s, body: %s", msg.To, msg.Body)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.cfg.SMS.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "os.OpenFile")
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%s\t%s\t%q\n", time.Now().UTC().Format(time.RFC3339), msg.To, msg.Body); err != nil {
+		return errors.Wrap(err, "fmt.Fprintf")
+	}
+	return nil
+}
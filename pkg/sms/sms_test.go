@@ -0,0 +1,57 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dinorain/useraja/config"
+	"github.com/dinorain/useraja/pkg/logger"
+)
+
+func TestHTTPSender_Send(t *testing.T) {
+	t.Parallel()
+
+	var received providerRequest
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{SMS: config.SMS{Driver: DriverHTTP, From: "Useraja", ProviderURL: server.URL, ProviderToken: "token", Timeout: 5}}
+	sender := NewSMSSender(cfg, logger.NewAppLogger(nil))
+
+	require.NoError(t, sender.Send(context.Background(), &Message{To: "+6281234567890", Body: "Your code is 123456"}))
+	require.Equal(t, providerRequest{From: "Useraja", To: "+6281234567890", Body: "Your code is 123456"}, received)
+
+	status = http.StatusBadGateway
+	require.Error(t, sender.Send(context.Background(), &Message{To: "+6281234567890", Body: "Your code is 123456"}))
+}
+
+func TestLocalSender_Send(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sms.log")
+	cfg := &config.Config{SMS: config.SMS{FilePath: path}}
+	sender := NewSMSSender(cfg, logger.NewAppLogger(nil))
+
+	require.NoError(t, sender.Send(context.Background(), &Message{To: "+6281234567890", Body: "first"}))
+	require.NoError(t, sender.Send(context.Background(), &Message{To: "+6281234567891", Body: "second"}))
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "+6281234567890\t\"first\"")
+	require.Contains(t, lines[1], "+6281234567891\t\"second\"")
+}
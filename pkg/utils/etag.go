@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidETag entity tag is not a quoted resource version
+var ErrInvalidETag = errors.New("invalid entity tag")
+
+// FormatETag entity tag of a resource version
+func FormatETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ParseETag resource version of an If-Match entity tag, weak tags are accepted
+func ParseETag(etag string) (int64, error) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	if len(etag) < 2 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		return 0, ErrInvalidETag
+	}
+
+	version, err := strconv.ParseInt(etag[1:len(etag)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, ErrInvalidETag
+	}
+
+	return version, nil
+}
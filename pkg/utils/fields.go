@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseFields Parse comma separated fields query param
+func ParseFields(fieldsQuery string) []string {
+	var fields []string
+	for _, field := range strings.Split(fieldsQuery, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// SelectFields Keep only the given json fields of an object or of every object in a list
+func SelectFields(v interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return v, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, err
+	}
+
+	switch value := decoded.(type) {
+	case map[string]interface{}:
+		return selectObjectFields(value, fields)
+	case []interface{}:
+		selected := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("fields: unsupported list item %T", item)
+			}
+			selectedObject, err := selectObjectFields(object, fields)
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, selectedObject)
+		}
+		return selected, nil
+	case nil:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("fields: unsupported value %T", decoded)
+}
+
+func selectObjectFields(object map[string]interface{}, fields []string) (map[string]interface{}, error) {
+	selected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		value, ok := object[field]
+		if !ok {
+			return nil, fmt.Errorf("fields: invalid field %q", field)
+		}
+		selected[field] = value
+	}
+	return selected, nil
+}
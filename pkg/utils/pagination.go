@@ -8,6 +8,7 @@ import (
 
 const (
 	defaultSize = 10
+	maxSize     = 100
 )
 
 // Pagination query params
@@ -15,6 +16,7 @@ type Pagination struct {
 	Size    int    `json:"size,omitempty"`
 	Page    int    `json:"page,omitempty"`
 	OrderBy string `json:"orderBy,omitempty"`
+	Cursor  string `json:"cursor,omitempty"`
 }
 
 // NewPaginationQuery Pagination query constructor
@@ -30,6 +32,15 @@ func NewPaginationFromQueryParams(size string, page string) *Pagination {
 	if page != "" {
 		p.SetPage(page)
 	}
+	if p.Size <= 0 {
+		p.Size = defaultSize
+	}
+	if p.Size > maxSize {
+		p.Size = maxSize
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
 
 	return p
 }
@@ -69,6 +80,16 @@ func (q *Pagination) SetOrderBy(orderByQuery string) {
 	q.OrderBy = orderByQuery
 }
 
+// SetCursor Set cursor token, a cursor replaces page based offsets
+func (q *Pagination) SetCursor(cursor string) {
+	q.Cursor = cursor
+}
+
+// GetCursor Get cursor token
+func (q *Pagination) GetCursor() string {
+	return q.Cursor
+}
+
 // GetOffset Get offset
 func (q *Pagination) GetOffset() int {
 	if q.Page == 0 {
@@ -110,5 +131,5 @@ func (q *Pagination) GetTotalPages(totalCount int) int {
 
 // GetHasMore Get has more
 func (q *Pagination) GetHasMore(totalCount int) bool {
-	return q.GetPage() < totalCount/q.GetSize()
+	return q.GetPage() < q.GetTotalPages(totalCount)
 }
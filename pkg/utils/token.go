@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const randomTokenBytes = 32
+
+// GenerateToken random url safe token for one time links
+func GenerateToken() (string, error) {
+	b := make([]byte, randomTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "rand.Read")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken sha256 hex digest a token is stored under, so leaked storage does not leak usable tokens
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// GenerateCode random numeric code of the given number of digits
+func GenerateCode(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", errors.Wrap(err, "rand.Int")
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// HashCode keyed hmac-sha256 hex digest of a short code, unlike HashToken it can not be brute forced without the key
+func HashCode(key string, code string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignToken token followed by its keyed signature, so forged tokens are rejected before they are looked up
+func SignToken(key string, token string) string {
+	return token + "." + HashCode(key, token)
+}
+
+// VerifySignedToken token of a SignToken value, false when the signature does not match
+func VerifySignedToken(key string, signed string) (string, bool) {
+	i := strings.LastIndex(signed, ".")
+	if i <= 0 {
+		return "", false
+	}
+	token, signature := signed[:i], signed[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(HashCode(key, token))) {
+		return "", false
+	}
+	return token, true
+}
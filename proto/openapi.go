@@ -0,0 +1,8 @@
+package userService
+
+import _ "embed"
+
+// OpenAPIv2 document generated from user.proto, see make proto
+//
+//go:embed user.swagger.json
+var OpenAPIv2 []byte